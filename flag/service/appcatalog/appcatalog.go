@@ -1,5 +1,10 @@
 package appcatalog
 
+import (
+	"github.com/giantswarm/app-operator/v5/flag/service/appcatalog/metadata"
+)
+
 type AppCatalog struct {
 	MaxEntriesPerApp string
+	Metadata         metadata.Metadata
 }
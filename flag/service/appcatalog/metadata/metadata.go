@@ -0,0 +1,6 @@
+package metadata
+
+type Metadata struct {
+	Concurrency string
+	RateLimit   string
+}
@@ -0,0 +1,130 @@
+// Package annotation contains Kubernetes object annotations used by
+// app-operator that are not defined in the k8smetadata library.
+package annotation
+
+const (
+	// AppAdopt annotation is set to true on app CRs to adopt an existing
+	// Helm release with the same name and namespace. Without it the chart CR
+	// is not created when the release already exists.
+	AppAdopt = "app-operator.giantswarm.io/adopt"
+
+	// AppDependsOn annotation is set on app CRs with a comma separated list
+	// of app CRs in the same namespace the app depends on. The chart CR is
+	// only created or updated once they are deployed.
+	AppDependsOn = "app-operator.giantswarm.io/depends-on"
+
+	// AppDeletionPolicy annotation is set on app CRs to configure what
+	// happens to the Helm release when the app CR is deleted. With orphan
+	// the release is kept and only the chart CR and the generated values
+	// are deleted. The default is delete.
+	AppDeletionPolicy = "app-operator.giantswarm.io/deletion-policy"
+
+	// AppDryRun annotation is set to true on app CRs so changes of the chart
+	// CR and its values are computed but not applied. The diff is set in the
+	// AppDryRunDiff annotation.
+	AppDryRun = "app-operator.giantswarm.io/dry-run"
+
+	// AppDryRunDiff annotation is set on app CRs in dry run mode by
+	// app-operator with the diff between the current and desired chart CR,
+	// values config map and secret. Secret values are redacted.
+	AppDryRunDiff = "application.giantswarm.io/dry-run-diff"
+
+	// AppResolvedVersion annotation is set on app CRs by app-operator with
+	// the chart version resolved from the version constraint of the app.
+	AppResolvedVersion = "application.giantswarm.io/resolved-version"
+
+	// AppExtraConfigs annotation is set on app CRs to merge config maps and
+	// secrets in addition to the catalog, cluster and user values. The value
+	// is a JSON list of the kind, name, namespace and priority of each
+	// config, e.g. [{"kind": "configMap", "name": "ingress-values",
+	// "priority": 75}]. Priorities are between 1 and 150. The catalog,
+	// organization default, namespace default, cluster and user values have
+	// the priorities 0, 10, 20, 50 and 100.
+	AppExtraConfigs = "app-operator.giantswarm.io/extra-configs"
+	// AppMaintenanceWindow annotation is set on app CRs or their namespace
+	// to only apply chart changes in a recurring maintenance window. The
+	// value is a JSON object with a cron schedule, a duration and a time
+	// zone, e.g. {"schedule": "0 22 * * 1-5", "duration": "4h", "timeZone":
+	// "Europe/Berlin"}. With "exemptConfigChanges": true changes that keep
+	// the chart version are applied outside the window.
+	AppMaintenanceWindow = "app-operator.giantswarm.io/maintenance-window"
+	// AppRollback annotation is set to true on app CRs to roll back the
+	// chart CR to the last deployed version and values when an upgrade
+	// fails or is not deployed within the rollback timeout.
+	AppRollback = "app-operator.giantswarm.io/rollback"
+
+	// AppRollbackState annotation is set on app CRs by app-operator with the
+	// last deployed release and the release the app was rolled back from.
+	// The value is JSON.
+	AppRollbackState = "application.giantswarm.io/rollback-state"
+
+	// AppRollbackTimeout annotation is set on app CRs to configure how long
+	// an upgrade may take to become deployed before it is rolled back, e.g.
+	// 15m. It defaults to 10m.
+	AppRollbackTimeout = "app-operator.giantswarm.io/rollback-timeout"
+
+	// AppValueRefs annotation is set on app CRs to inject single keys of
+	// config maps and secrets into the values. The value is a JSON list of
+	// the kind, name, namespace, key and values path of each ref, e.g.
+	// [{"name": "db-credentials", "key": "password", "path":
+	// "database.password"}]. The kind defaults to secret. The values are
+	// added to the chart secret and override all other values.
+	AppValueRefs = "app-operator.giantswarm.io/value-refs"
+	// AppValuesProvenance annotation is set on app CRs with the value "true"
+	// so the provenance of the merged config map values is stored on the
+	// generated chart config map.
+	AppValuesProvenance = "app-operator.giantswarm.io/values-provenance"
+	// AppValuesTemplating annotation is set on app CRs with the value "true"
+	// so the string values of the merged values are rendered as Go templates
+	// with the cluster ID, organization ID, provider and labels of the app.
+	AppValuesTemplating = "app-operator.giantswarm.io/values-templating"
+	// AppVersionConstraint annotation is set on app CRs by app-operator with
+	// the version constraint of the app that was resolved.
+	AppVersionConstraint = "application.giantswarm.io/version-constraint"
+
+	// CatalogAuthSecret annotation is set on catalog CRs to reference a secret
+	// in the catalog namespace with the credentials for the Helm repository.
+	// The secret may contain username and password, a bearer token or client
+	// TLS certificates.
+	CatalogAuthSecret = "application.giantswarm.io/catalog-auth-secret"
+
+	// CatalogOCICharts annotation is set on catalog CRs with oci storage to
+	// list the names of their charts, separated by commas. It is needed for
+	// registries that do not support listing repositories, e.g. GHCR, ECR
+	// and Docker Hub.
+	CatalogOCICharts = "application.giantswarm.io/oci-charts"
+
+	// CatalogProvenanceKeyringSecret annotation is set on catalog CRs to
+	// require Helm provenance verification of its charts. It references a
+	// secret in the catalog namespace with the keyring of trusted keys.
+	CatalogProvenanceKeyringSecret = "application.giantswarm.io/provenance-keyring-secret"
+
+	// CatalogRetentionPolicy annotation is set on catalog CRs to configure
+	// which appcatalogentry CRs are kept per app. The value is a JSON object
+	// with the rules of the policy, e.g. {"patchesPerMinor": 3}.
+	CatalogRetentionPolicy = "application.giantswarm.io/retention-policy"
+
+	// CatalogStatus annotation is set on catalog CRs by app-operator with
+	// the result of the last sync of its appcatalogentry CRs. The value is
+	// JSON because the catalog CRD has no status.
+	CatalogStatus = "application.giantswarm.io/catalog-status"
+
+	// CatalogMetadataErrors annotation is set on catalog CRs by app-operator
+	// with the appMetadata files that could not be pulled in the last sync.
+	// The value is a JSON list of the entry, URL and error of each failure.
+	CatalogMetadataErrors = "application.giantswarm.io/metadata-errors"
+
+	// ChartDigest annotation is set on appcatalogentry CRs with the SHA256
+	// digest of the chart tarball from the catalog index.
+	ChartDigest = "application.giantswarm.io/chart-digest"
+
+	// ChartOperatorChartDigest annotation is set on chart CRs so
+	// chart-operator refuses tarballs that do not match the digest.
+	ChartOperatorChartDigest = "chart-operator.giantswarm.io/chart-digest"
+
+	// ChartConfigMapValuesProvenance annotation is set on chart config maps
+	// by app-operator when enabled in the app CR. The value is a JSON object
+	// with the layer that set each key of the values, e.g.
+	// {"ingress.host": "cluster configmap org-acme/acme-cluster-values"}.
+	ChartConfigMapValuesProvenance = "application.giantswarm.io/values-provenance"
+)
@@ -0,0 +1,62 @@
+// Package condition contains the types of the conditions app-operator sets in
+// the status of app CRs, e.g. for kubectl wait --for=condition=Deployed.
+package condition
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// CatalogResolved condition is true when the catalog CR of the app was
+	// found. It is set by the catalog resource.
+	CatalogResolved = "CatalogResolved"
+
+	// ChartSynced condition is true when the chart CR in the workload cluster
+	// matches the app CR. It is false when changes are held back, e.g. until
+	// dependencies are deployed. It is set by the chart resource.
+	ChartSynced = "ChartSynced"
+
+	// ClusterReachable condition is true when app-operator has clients for
+	// the cluster the app is deployed to. It is set by the clients resource.
+	ClusterReachable = "ClusterReachable"
+
+	// Deployed condition is true when the Helm release of the app is
+	// deployed. It is set by the status resource from the chart CR status.
+	Deployed = "Deployed"
+
+	// Validated condition is true when the app CR passed validation. It is
+	// set by the validation resource.
+	Validated = "Validated"
+
+	// ValuesMerged condition is true when the config maps and secrets of the
+	// app were merged into the values of the chart CR. It is set by the
+	// configmap and secret resources.
+	ValuesMerged = "ValuesMerged"
+)
+
+// Reason converts a status like waiting-for-dependencies into the CamelCase
+// form required for condition reasons, e.g. WaitingForDependencies.
+func Reason(status string) string {
+	words := strings.FieldsFunc(status, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, w := range words {
+		runes := []rune(w)
+		if b.Len() == 0 && !unicode.IsLetter(runes[0]) {
+			// Reasons must start with a letter.
+			continue
+		}
+
+		b.WriteRune(unicode.ToUpper(runes[0]))
+		b.WriteString(string(runes[1:]))
+	}
+
+	if b.Len() == 0 {
+		return "Unknown"
+	}
+
+	return b.String()
+}
@@ -14,9 +14,16 @@ import (
 )
 
 const (
+	// DefaultValues label is set to "true" on config maps and secrets with
+	// default values for all apps in their namespace. Default values in an
+	// organization namespace apply to all apps of the organization.
+	DefaultValues = "app-operator.giantswarm.io/default-values"
 	// Latest label is added to appcatalogentry CRs to filter for the most
-	// recent release.
+	// recent stable release.
 	Latest = "latest"
+	// LatestPrerelease label is added to appcatalogentry CRs to filter for the
+	// most recent release including prereleases.
+	LatestPrerelease = "latest-prerelease"
 )
 
 func AppVersionSelector(unique bool) labels.Selector {
@@ -5,10 +5,46 @@ const (
 	// merge configmaps.
 	ConfigmapMergeFailedStatus = "configmap-merge-failed"
 
+	// DependencyCycleStatus is set in the CR status when the app is part of a
+	// cycle of app CRs depending on each other.
+	DependencyCycleStatus = "dependency-cycle"
+
+	// ReleaseAlreadyExistsStatus is set in the CR status when the chart CR
+	// is not created because a Helm release with the same name and namespace
+	// already exists and the app does not adopt it.
+	ReleaseAlreadyExistsStatus = "release-already-exists"
+
 	// ResourceNotFoundStatus is set in the CR status when there is an failure during
 	// finding dependents kubernete resources.
 	ResourceNotFoundStatus = "resource-not-found"
 
+	// SignatureVerificationFailedStatus is set in the CR status when the
+	// provenance of the chart could not be verified against the keyring of
+	// the catalog.
+	SignatureVerificationFailedStatus = "signature-verification-failed"
+
+	// ValuesSchemaInvalidStatus is set in the CR status when the merged
+	// values do not match the values.schema.json file of the chart.
+	ValuesSchemaInvalidStatus = "values-schema-invalid"
+
+	// ValuesTemplateFailedStatus is set in the CR status when the merged
+	// values of an app with templating enabled can not be rendered.
+	ValuesTemplateFailedStatus = "values-template-failed"
+
+	// VersionConstraintUnsatisfiedStatus is set in the CR status when no
+	// version in the catalog matches the version constraint of the app.
+	VersionConstraintUnsatisfiedStatus = "version-constraint-unsatisfied"
+
+	// WaitingForDependenciesStatus is set in the CR status when the chart CR
+	// is not created or updated because app CRs the app depends on are not
+	// deployed yet.
+	WaitingForDependenciesStatus = "waiting-for-dependencies"
+
+	// WaitingForMaintenanceWindowStatus is set in the CR status when changes
+	// of the chart CR are held back until the maintenance window of the app
+	// opens.
+	WaitingForMaintenanceWindowStatus = "waiting-for-maintenance-window"
+
 	// SecretMergeFailedStatus is set in the CR status when there is an failure during
 	// merge secrets.
 	SecretMergeFailedStatus = "secret-merge-failed"
@@ -17,6 +53,11 @@ const (
 var (
 	FailedStatus = map[string]bool{
 		ConfigmapMergeFailedStatus: true,
+		ResourceNotFoundStatus:     true,
 		SecretMergeFailedStatus:    true,
+
+		SignatureVerificationFailedStatus: true,
+		ValuesSchemaInvalidStatus:         true,
+		ValuesTemplateFailedStatus:        true,
 	}
 )
@@ -6,6 +6,7 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 
+	"github.com/giantswarm/app-operator/v5/server/endpoint/valuesprovenance"
 	"github.com/giantswarm/app-operator/v5/service"
 )
 
@@ -18,8 +19,9 @@ type Config struct {
 
 // Endpoint is the endpoint collection.
 type Endpoint struct {
-	Healthz *healthz.Endpoint
-	Version *version.Endpoint
+	Healthz          *healthz.Endpoint
+	ValuesProvenance *valuesprovenance.Endpoint
+	Version          *version.Endpoint
 }
 
 // New creates a new endpoint with given configuration.
@@ -58,9 +60,23 @@ func New(config Config) (*Endpoint, error) {
 		}
 	}
 
+	var valuesProvenanceEndpoint *valuesprovenance.Endpoint
+	{
+		c := valuesprovenance.Config{
+			Logger:  config.Logger,
+			Service: config.Service.ValuesProvenance,
+		}
+
+		valuesProvenanceEndpoint, err = valuesprovenance.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	endpoint := &Endpoint{
-		Healthz: healthzEndpoint,
-		Version: versionEndpoint,
+		Healthz:          healthzEndpoint,
+		ValuesProvenance: valuesProvenanceEndpoint,
+		Version:          versionEndpoint,
 	}
 
 	return endpoint, nil
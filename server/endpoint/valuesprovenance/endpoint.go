@@ -0,0 +1,127 @@
+package valuesprovenance
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+
+	"github.com/giantswarm/app-operator/v5/service/valuesprovenance"
+)
+
+const (
+	// Method is the HTTP method this endpoint is registered for.
+	Method = "GET"
+	// Name identifies the endpoint. It is aligned to the package path.
+	Name = "values-provenance"
+	// Path is the HTTP request path this endpoint is registered for.
+	Path = "/values-provenance/"
+)
+
+// Config represents the configuration used to create a values provenance
+// endpoint.
+type Config struct {
+	// Dependencies.
+	Logger  micrologger.Logger
+	Service *valuesprovenance.Service
+}
+
+// Request is the app whose values provenance is requested.
+type Request struct {
+	Name      string
+	Namespace string
+}
+
+// Response is the values provenance of the requested app.
+type Response struct {
+	Name       string                      `json:"name"`
+	Namespace  string                      `json:"namespace"`
+	Provenance valuesprovenance.Provenance `json:"provenance"`
+}
+
+// New creates a new configured values provenance endpoint.
+func New(config Config) (*Endpoint, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Service == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Service must not be empty", config)
+	}
+
+	e := &Endpoint{
+		logger:  config.Logger,
+		service: config.Service,
+	}
+
+	return e, nil
+}
+
+// Endpoint serves the values provenance of an app. The app is selected with
+// the namespace and name query parameters. Only apps reconciled by this
+// app-operator instance since it started are known, requests for other apps
+// get a not found error.
+type Endpoint struct {
+	logger  micrologger.Logger
+	service *valuesprovenance.Service
+}
+
+func (e *Endpoint) Decoder() kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		request := Request{
+			Name:      r.URL.Query().Get("name"),
+			Namespace: r.URL.Query().Get("namespace"),
+		}
+		if request.Name == "" || request.Namespace == "" {
+			return nil, microerror.Maskf(invalidRequestError, "query parameters %#q and %#q must not be empty", "namespace", "name")
+		}
+
+		return request, nil
+	}
+}
+
+func (e *Endpoint) Encoder() kithttp.EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		return json.NewEncoder(w).Encode(response)
+	}
+}
+
+func (e *Endpoint) Endpoint() kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		r := request.(Request)
+
+		provenance, ok := e.service.Get(r.Namespace, r.Name)
+		if !ok {
+			return nil, microerror.Maskf(notFoundError, "values provenance of app %#q in namespace %#q not found, the app may not be reconciled by this instance yet", r.Name, r.Namespace)
+		}
+
+		response := &Response{
+			Name:       r.Name,
+			Namespace:  r.Namespace,
+			Provenance: provenance,
+		}
+
+		return response, nil
+	}
+}
+
+func (e *Endpoint) Method() string {
+	return Method
+}
+
+func (e *Endpoint) Middlewares() []kitendpoint.Middleware {
+	return []kitendpoint.Middleware{}
+}
+
+func (e *Endpoint) Name() string {
+	return Name
+}
+
+func (e *Endpoint) Path() string {
+	return Path
+}
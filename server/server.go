@@ -12,6 +12,7 @@ import (
 
 	"github.com/giantswarm/app-operator/v5/pkg/project"
 	"github.com/giantswarm/app-operator/v5/server/endpoint"
+	"github.com/giantswarm/app-operator/v5/server/endpoint/valuesprovenance"
 	"github.com/giantswarm/app-operator/v5/service"
 )
 
@@ -64,6 +65,7 @@ func New(config Config) (microserver.Server, error) {
 			Viper:       config.Viper,
 			Endpoints: []microserver.Endpoint{
 				endpointCollection.Healthz,
+				endpointCollection.ValuesProvenance,
 				endpointCollection.Version,
 			},
 			ErrorEncoder: errorEncoder,
@@ -104,7 +106,18 @@ func errorEncoder(ctx context.Context, err error, w http.ResponseWriter) {
 	rErr := err.(microserver.ResponseError)
 	uErr := rErr.Underlying()
 
-	rErr.SetCode(microserver.CodeInternalError)
-	rErr.SetMessage(uErr.Error())
-	w.WriteHeader(http.StatusInternalServerError)
+	switch {
+	case valuesprovenance.IsInvalidRequest(uErr):
+		rErr.SetCode(microserver.CodeInvalidInput)
+		rErr.SetMessage(uErr.Error())
+		w.WriteHeader(http.StatusBadRequest)
+	case valuesprovenance.IsNotFound(uErr):
+		rErr.SetCode(microserver.CodeResourceNotFound)
+		rErr.SetMessage(uErr.Error())
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		rErr.SetCode(microserver.CodeInternalError)
+		rErr.SetMessage(uErr.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+	}
 }
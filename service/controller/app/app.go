@@ -19,16 +19,22 @@ import (
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
 	"github.com/giantswarm/app-operator/v5/service/internal/clientcache"
 	"github.com/giantswarm/app-operator/v5/service/internal/crdcache"
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
+	"github.com/giantswarm/app-operator/v5/service/internal/recorder"
+	"github.com/giantswarm/app-operator/v5/service/valuesprovenance"
 )
 
 const appControllerSuffix = "-app"
 
 type Config struct {
-	Fs          afero.Fs
-	K8sClient   k8sclient.Interface
-	ClientCache *clientcache.Resource
-	CRDCache    *crdcache.Resource
-	Logger      micrologger.Logger
+	Event            recorder.Interface
+	Fs               afero.Fs
+	K8sClient        k8sclient.Interface
+	ClientCache      *clientcache.Resource
+	CRDCache         *crdcache.Resource
+	IndexCache       *indexcache.Resource
+	Logger           micrologger.Logger
+	ValuesProvenance *valuesprovenance.Service
 
 	ChartNamespace    string
 	HTTPClientTimeout time.Duration
@@ -52,6 +58,12 @@ func NewApp(config Config) (*App, error) {
 	if config.CRDCache == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.CRDCache must not be empty", config)
 	}
+	if config.IndexCache == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.IndexCache must not be empty", config)
+	}
+	if config.Event == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Event must not be empty", config)
+	}
 	if config.Fs == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Fs must not be empty", config)
 	}
@@ -61,6 +73,9 @@ func NewApp(config Config) (*App, error) {
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
+	if config.ValuesProvenance == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ValuesProvenance must not be empty", config)
+	}
 
 	if config.HTTPClientTimeout == 0 {
 		return nil, microerror.Maskf(invalidConfigError, "%T.HTTPClientTimeout must not be empty", config)
@@ -92,11 +107,14 @@ func NewApp(config Config) (*App, error) {
 	var resources []resource.Interface
 	{
 		c := appResourcesConfig{
-			ClientCache: config.ClientCache,
-			CRDCache:    config.CRDCache,
-			FileSystem:  config.Fs,
-			K8sClient:   config.K8sClient,
-			Logger:      config.Logger,
+			ClientCache:      config.ClientCache,
+			CRDCache:         config.CRDCache,
+			Event:            config.Event,
+			FileSystem:       config.Fs,
+			IndexCache:       config.IndexCache,
+			K8sClient:        config.K8sClient,
+			Logger:           config.Logger,
+			ValuesProvenance: config.ValuesProvenance,
 
 			ChartNamespace:    config.ChartNamespace,
 			HTTPClientTimeout: config.HTTPClientTimeout,
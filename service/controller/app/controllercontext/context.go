@@ -7,6 +7,10 @@ import (
 	"github.com/giantswarm/helmclient/v4/pkg/helmclient"
 	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
 	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/app-operator/v5/service/internal/dryrun"
 )
 
 type contextKey string
@@ -16,7 +20,17 @@ const controllerKey contextKey = "controller"
 type Context struct {
 	Catalog v1alpha1.Catalog
 	Clients Clients
-	Status  Status
+	// Conditions are set by the resources in this reconciliation loop and
+	// written to the app CR status together with the status.
+	Conditions []metav1.Condition
+	// DryRunChanges are the changes that were not applied because the app is
+	// in dry run mode.
+	DryRunChanges []dryrun.Change
+	// Maintenance is the state of the maintenance window of the app. It is
+	// set before the values and the chart CR are reconciled.
+	Maintenance Maintenance
+	Status      Status
+	Version     Version
 }
 
 type Clients struct {
@@ -24,9 +38,30 @@ type Clients struct {
 	Helm helmclient.Interface
 }
 
+// Maintenance is the state of the maintenance window of the app. Changes of
+// the generated values and the chart CR are held back while it is closed.
+type Maintenance struct {
+	// Closed is true if the app has a maintenance window that is closed.
+	Closed bool
+	// ConfigChangesExempt is true if changes that keep the chart version are
+	// applied while the window is closed.
+	ConfigChangesExempt bool
+	// Reason describes why changes are held back.
+	Reason string
+}
+
+// HoldsConfigChanges returns true if changes of the generated values of the
+// app are held back.
+func (m Maintenance) HoldsConfigChanges() bool {
+	return m.Closed && !m.ConfigChangesExempt
+}
+
 type Status struct {
 	ChartStatus   ChartStatus
 	ClusterStatus ClusterStatus
+	// Rollback describes the rollback of the chart CR to the last deployed
+	// release if the app was rolled back.
+	Rollback string
 }
 
 type ChartStatus struct {
@@ -39,6 +74,13 @@ type ClusterStatus struct {
 	IsUnavailable bool
 }
 
+// Version is the chart version of the app. If the app CR has a version
+// constraint it is resolved against the catalog.
+type Version struct {
+	Constraint string
+	Resolved   string
+}
+
 func NewContext(ctx context.Context, c Context) context.Context {
 	return context.WithValue(ctx, controllerKey, &c)
 }
@@ -51,3 +93,25 @@ func FromContext(ctx context.Context) (*Context, error) {
 
 	return c, nil
 }
+
+// ChartVersion returns the resolved chart version of the app or the version
+// of the app CR if it was not resolved.
+func (c *Context) ChartVersion(cr v1alpha1.App) string {
+	if c.Version.Resolved != "" {
+		return c.Version.Resolved
+	}
+
+	return cr.Spec.Version
+}
+
+// SetCondition sets the condition of the given type observed for the current
+// generation of the app CR.
+func (c *Context) SetCondition(cr v1alpha1.App, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&c.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: cr.GetGeneration(),
+		Reason:             reason,
+		Message:            message,
+	})
+}
@@ -0,0 +1,130 @@
+package appversion
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/app/v5/pkg/key"
+	"github.com/giantswarm/app/v5/pkg/validation"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/v5/pkg/controller/context/reconciliationcanceledcontext"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/app-operator/v5/pkg/condition"
+	"github.com/giantswarm/app-operator/v5/pkg/status"
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/conditions"
+	"github.com/giantswarm/app-operator/v5/service/internal/versionconstraint"
+)
+
+// EnsureCreated resolves the version constraint of the app CR and stores the
+// resolved version in the controller context. New versions matching the
+// constraint are picked up on the next reconciliation.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	cr, err := key.ToApp(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	version := key.Version(cr)
+
+	if !versionconstraint.IsConstraint(version) {
+		cc.Version = controllercontext.Version{
+			Resolved: version,
+		}
+
+		err = r.setVersion(ctx, cr, "", "")
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	}
+
+	r.logger.Debugf(ctx, "resolving version constraint %#q of app %#q", version, cr.Name)
+
+	versions, err := r.getVersions(ctx, cr, cc.Catalog)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	resolved, err := versionconstraint.Resolve(version, versions)
+	if versionconstraint.IsNotFound(err) {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("no version of app %#q in catalog %#q matches constraint %#q", key.AppName(cr), cc.Catalog.Name, version))
+
+		err = r.updateAppStatus(ctx, cc, cr, status.VersionConstraintUnsatisfiedStatus, err.Error())
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.Debugf(ctx, "canceling reconciliation")
+		reconciliationcanceledcontext.SetCanceled(ctx)
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.Debugf(ctx, "resolved version constraint %#q of app %#q to %#q", version, cr.Name, resolved)
+
+	cc.Version = controllercontext.Version{
+		Constraint: version,
+		Resolved:   resolved,
+	}
+
+	err = r.setVersion(ctx, cr, version, resolved)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// The validation resource validated the app CR with the version
+	// constraint, which has no appcatalogentry CR, so the metadata
+	// restrictions of the resolved version are validated here.
+	resolvedCR := cr.DeepCopy()
+	resolvedCR.Spec.Version = resolved
+
+	_, err = r.appValidator.ValidateApp(ctx, *resolvedCR)
+	if validation.IsValidationError(err) {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("validation error %s", err.Error()))
+
+		cc.SetCondition(cr, condition.Validated, metav1.ConditionFalse, "ValidationFailed", err.Error())
+
+		err = r.updateAppStatus(ctx, cc, cr, status.ResourceNotFoundStatus, err.Error())
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.Debugf(ctx, "canceling reconciliation")
+		reconciliationcanceledcontext.SetCanceled(ctx)
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (r *Resource) updateAppStatus(ctx context.Context, cc *controllercontext.Context, cr v1alpha1.App, releaseStatus, reason string) error {
+	r.logger.Debugf(ctx, "setting status for app %#q in namespace %#q", cr.Name, cr.Namespace)
+
+	desiredStatus := key.AppStatus(cr)
+	desiredStatus.Release.Reason = reason
+	desiredStatus.Release.Status = releaseStatus
+
+	updated, err := conditions.Update(ctx, r.dynClient, cr, &desiredStatus, cc.Conditions)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if updated {
+		r.logger.Debugf(ctx, "status set for app %#q in namespace %#q", cr.Name, cr.Namespace)
+	} else {
+		r.logger.Debugf(ctx, "status already set for app %#q in namespace %#q", cr.Name, cr.Namespace)
+	}
+
+	return nil
+}
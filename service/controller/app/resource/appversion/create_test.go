@@ -0,0 +1,197 @@
+package appversion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clientgofake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
+)
+
+func Test_Resource_EnsureCreated(t *testing.T) {
+	storageURL := "https://giantswarm.github.io/app-catalog/"
+
+	tests := []struct {
+		name               string
+		version            string
+		expectedVersion    controllercontext.Version
+		expectedAnnotation string
+		expectedStatus     string
+	}{
+		{
+			name:    "case 0: fixed version",
+			version: "1.4.0",
+			expectedVersion: controllercontext.Version{
+				Resolved: "1.4.0",
+			},
+		},
+		{
+			name:    "case 1: version constraint",
+			version: "~1.4",
+			expectedVersion: controllercontext.Version{
+				Constraint: "~1.4",
+				Resolved:   "1.4.3",
+			},
+			expectedAnnotation: "1.4.3",
+		},
+		{
+			name:           "case 2: unsatisfied version constraint",
+			version:        ">=3.0",
+			expectedStatus: "version-constraint-unsatisfied",
+		},
+		{
+			name:    "case 3: resolved version restricted to another namespace",
+			version: "~2.0",
+			expectedVersion: controllercontext.Version{
+				Constraint: "~2.0",
+				Resolved:   "2.0.0",
+			},
+			expectedAnnotation: "2.0.0",
+			expectedStatus:     "resource-not-found",
+		},
+	}
+
+	catalog := v1alpha1.Catalog{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "giantswarm",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.CatalogSpec{
+			Storage: v1alpha1.CatalogSpecStorage{
+				Type: "helm",
+				URL:  storageURL,
+			},
+		},
+	}
+	entry := &v1alpha1.AppCatalogEntry{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "giantswarm-prometheus-2.0.0",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AppCatalogEntrySpec{
+			Restrictions: &v1alpha1.AppCatalogEntrySpecRestrictions{
+				FixedNamespace: "kube-system",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := &v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-prometheus",
+					Namespace: "default",
+					Labels: map[string]string{
+						"app-operator.giantswarm.io/version": "0.0.0",
+					},
+				},
+				Spec: v1alpha1.AppSpec{
+					Catalog: "giantswarm",
+					KubeConfig: v1alpha1.AppSpecKubeConfig{
+						InCluster: true,
+					},
+					Name:      "prometheus",
+					Namespace: "monitoring",
+					Version:   tc.version,
+				},
+			}
+
+			indexCache, err := indexcache.New(indexcache.Config{Logger: microloggertest.New()})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			indexCache.Set(storageURL, indexcache.Index{
+				Entries: map[string][]indexcache.Entry{
+					"prometheus": {
+						{Name: "prometheus", Version: "1.3.0"},
+						{Name: "prometheus", Version: "1.4.3"},
+						{Name: "prometheus", Version: "1.4.1"},
+						{Name: "prometheus", Version: "2.0.0"},
+					},
+				},
+			})
+
+			scheme := runtime.NewScheme()
+			err = v1alpha1.AddToScheme(scheme)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			dynClient := dynamicfake.NewSimpleDynamicClient(scheme, app.DeepCopy())
+			g8sClient := fake.NewSimpleClientset(app, catalog.DeepCopy(), entry)
+
+			c := Config{
+				DynClient:  dynClient,
+				G8sClient:  g8sClient,
+				IndexCache: indexCache,
+				K8sClient:  clientgofake.NewSimpleClientset(),
+				Logger:     microloggertest.New(),
+
+				HTTPClientTimeout: 5 * time.Second,
+				Provider:          "aws",
+			}
+			r, err := New(c)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+				Catalog: catalog,
+			})
+
+			err = r.EnsureCreated(ctx, app)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			cc, err := controllercontext.FromContext(ctx)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			if cc.Version != tc.expectedVersion {
+				t.Fatalf("version == %#v, want %#v", cc.Version, tc.expectedVersion)
+			}
+
+			updated, err := g8sClient.ApplicationV1alpha1().Apps(app.Namespace).Get(ctx, app.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			resolved := updated.Annotations["application.giantswarm.io/resolved-version"]
+			if resolved != tc.expectedAnnotation {
+				t.Fatalf("resolved version == %#q, want %#q", resolved, tc.expectedAnnotation)
+			}
+
+			obj, err := dynClient.Resource(v1alpha1.SchemeGroupVersion.WithResource("apps")).Namespace(app.Namespace).Get(ctx, app.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			status, _, err := unstructured.NestedString(obj.Object, "status", "release", "status")
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			if status != tc.expectedStatus {
+				t.Fatalf("status == %#q, want %#q", status, tc.expectedStatus)
+			}
+
+			resolvedStatus, _, err := unstructured.NestedString(obj.Object, "status", "resolvedVersion")
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			if resolvedStatus != tc.expectedAnnotation {
+				t.Fatalf("resolved version status == %#q, want %#q", resolvedStatus, tc.expectedAnnotation)
+			}
+		})
+	}
+}
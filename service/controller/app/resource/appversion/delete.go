@@ -0,0 +1,50 @@
+package appversion
+
+import (
+	"context"
+
+	"github.com/giantswarm/app/v5/pkg/key"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/versionconstraint"
+)
+
+// EnsureDeleted stores the resolved version of the app CR in the controller
+// context so the chart version that was deployed is deleted. The catalog is
+// not resolved on deletion so the version recorded on the app CR is used
+// instead of resolving the version constraint again.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	cr, err := key.ToApp(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	version := key.Version(cr)
+	if !versionconstraint.IsConstraint(version) {
+		cc.Version = controllercontext.Version{
+			Resolved: version,
+		}
+
+		return nil
+	}
+
+	resolved := cr.GetAnnotations()[annotation.AppResolvedVersion]
+	if resolved == "" {
+		r.logger.Debugf(ctx, "version constraint %#q of app %#q was never resolved", version, cr.Name)
+		return nil
+	}
+
+	cc.Version = controllercontext.Version{
+		Constraint: version,
+		Resolved:   resolved,
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package appversion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clientgofake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
+)
+
+func Test_Resource_EnsureDeleted(t *testing.T) {
+	tests := []struct {
+		name            string
+		version         string
+		annotations     map[string]string
+		expectedVersion controllercontext.Version
+	}{
+		{
+			name:    "case 0: fixed version",
+			version: "1.4.0",
+			expectedVersion: controllercontext.Version{
+				Resolved: "1.4.0",
+			},
+		},
+		{
+			name:    "case 1: resolved version constraint",
+			version: "~1.4",
+			annotations: map[string]string{
+				"application.giantswarm.io/resolved-version": "1.4.3",
+			},
+			expectedVersion: controllercontext.Version{
+				Constraint: "~1.4",
+				Resolved:   "1.4.3",
+			},
+		},
+		{
+			name:    "case 2: version constraint never resolved",
+			version: "~1.4",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tc.annotations,
+					Name:        "my-prometheus",
+					Namespace:   "default",
+				},
+				Spec: v1alpha1.AppSpec{
+					Catalog: "giantswarm",
+					Name:    "prometheus",
+					Version: tc.version,
+				},
+			}
+
+			indexCache, err := indexcache.New(indexcache.Config{Logger: microloggertest.New()})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			r, err := New(Config{
+				DynClient:  dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()),
+				G8sClient:  fake.NewSimpleClientset(),
+				IndexCache: indexCache,
+				K8sClient:  clientgofake.NewSimpleClientset(),
+				Logger:     microloggertest.New(),
+
+				HTTPClientTimeout: 5 * time.Second,
+				Provider:          "aws",
+			})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{})
+
+			err = r.EnsureDeleted(ctx, &app)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			cc, err := controllercontext.FromContext(ctx)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			if cc.Version != tc.expectedVersion {
+				t.Fatalf("version == %#v, want %#v", cc.Version, tc.expectedVersion)
+			}
+		})
+	}
+}
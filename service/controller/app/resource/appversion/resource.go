@@ -0,0 +1,194 @@
+package appversion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned"
+	"github.com/giantswarm/app/v5/pkg/key"
+	"github.com/giantswarm/app/v5/pkg/validation"
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+	"github.com/giantswarm/app-operator/v5/service/internal/conditions"
+	"github.com/giantswarm/app-operator/v5/service/internal/helmrepo"
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
+	"github.com/giantswarm/app-operator/v5/service/internal/oci"
+)
+
+const (
+	Name = "appversion"
+)
+
+type Config struct {
+	DynClient  dynamic.Interface
+	G8sClient  versioned.Interface
+	IndexCache *indexcache.Resource
+	K8sClient  kubernetes.Interface
+	Logger     micrologger.Logger
+
+	HTTPClientTimeout time.Duration
+	Provider          string
+}
+
+// Resource resolves semver constraints in the version of app CRs to the
+// highest matching chart version in the catalog.
+type Resource struct {
+	appValidator *validation.Validator
+	dynClient    dynamic.Interface
+	g8sClient    versioned.Interface
+	indexCache   *indexcache.Resource
+	k8sClient    kubernetes.Interface
+	logger       micrologger.Logger
+
+	httpClientTimeout time.Duration
+}
+
+// New creates a new configured appversion resource.
+func New(config Config) (*Resource, error) {
+	if config.DynClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.DynClient must not be empty", config)
+	}
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.IndexCache == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.IndexCache must not be empty", config)
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.HTTPClientTimeout == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.HTTPClientTimeout must not be empty", config)
+	}
+	if config.Provider == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Provider must not be empty", config)
+	}
+
+	var err error
+
+	// The app CR is validated again with the resolved version because the
+	// metadata restrictions are taken from the appcatalogentry CR of the
+	// chart version.
+	var appValidator *validation.Validator
+	{
+		c := validation.Config{
+			G8sClient: config.G8sClient,
+			K8sClient: config.K8sClient,
+			Logger:    config.Logger,
+
+			Provider: config.Provider,
+		}
+		appValidator, err = validation.NewValidator(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	r := &Resource{
+		appValidator: appValidator,
+		dynClient:    config.DynClient,
+		g8sClient:    config.G8sClient,
+		indexCache:   config.IndexCache,
+		k8sClient:    config.K8sClient,
+		logger:       config.Logger,
+
+		httpClientTimeout: config.HTTPClientTimeout,
+	}
+
+	return r, nil
+}
+
+func (r Resource) Name() string {
+	return Name
+}
+
+// getVersions returns the versions of the app in the catalog. They are taken
+// from the index for Helm repositories and from the appcatalogentry CRs for
+// OCI registries which have no index.
+func (r *Resource) getVersions(ctx context.Context, cr v1alpha1.App, catalog v1alpha1.Catalog) ([]string, error) {
+	if oci.IsOCIStorage(catalog) {
+		lo := metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("%s=%s,%s=%s", label.CatalogName, catalog.Name, label.AppKubernetesName, key.AppName(cr)),
+		}
+		entries, err := r.g8sClient.ApplicationV1alpha1().AppCatalogEntries(catalog.Namespace).List(ctx, lo)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		var versions []string
+		for _, entry := range entries.Items {
+			versions = append(versions, entry.Spec.Version)
+		}
+
+		return versions, nil
+	}
+
+	client, err := helmrepo.NewCatalogHTTPClient(ctx, r.k8sClient, catalog, r.httpClientTimeout)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	versions, err := r.indexCache.Versions(ctx, client, key.CatalogStorageURL(catalog), key.AppName(cr))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return versions, nil
+}
+
+// setVersion records the version constraint and the resolved version in the
+// status and the annotations of the app CR. Empty values remove them. The
+// annotations are patched last so they only match once the status is set.
+func (r *Resource) setVersion(ctx context.Context, cr v1alpha1.App, constraint, resolved string) error {
+	current := cr.GetAnnotations()
+	if current[annotation.AppVersionConstraint] == constraint && current[annotation.AppResolvedVersion] == resolved {
+		return nil
+	}
+
+	_, err := conditions.SetVersion(ctx, r.dynClient, cr, constraint, resolved)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	toValue := func(s string) *string {
+		if s == "" {
+			return nil
+		}
+		return &s
+	}
+
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]*string{
+				annotation.AppResolvedVersion:   toValue(resolved),
+				annotation.AppVersionConstraint: toValue(constraint),
+			},
+		},
+	}
+
+	bytes, err := json.Marshal(patch)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	_, err = r.g8sClient.ApplicationV1alpha1().Apps(cr.Namespace).Patch(ctx, cr.Name, types.MergePatchType, bytes, metav1.PatchOptions{})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package catalog
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
 	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned"
@@ -10,8 +11,11 @@ import (
 	"github.com/giantswarm/micrologger"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 
+	"github.com/giantswarm/app-operator/v5/pkg/condition"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/conditions"
 )
 
 const (
@@ -22,6 +26,7 @@ const (
 // Config represents the configuration used to create a new catalog resource.
 type Config struct {
 	// Dependencies.
+	DynClient dynamic.Interface
 	G8sClient versioned.Interface
 	Logger    micrologger.Logger
 }
@@ -29,12 +34,16 @@ type Config struct {
 // Resource implements the catalog resource.
 type Resource struct {
 	// Dependencies.
+	dynClient dynamic.Interface
 	g8sClient versioned.Interface
 	logger    micrologger.Logger
 }
 
 // New creates a new configured catalog resource.
 func New(config Config) (*Resource, error) {
+	if config.DynClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.DynClient must not be empty", config)
+	}
 	if config.G8sClient == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
 	}
@@ -44,6 +53,7 @@ func New(config Config) (*Resource, error) {
 
 	r := &Resource{
 		// Dependencies.
+		dynClient: config.DynClient,
 		g8sClient: config.G8sClient,
 		logger:    config.Logger,
 	}
@@ -88,11 +98,22 @@ func (r *Resource) getCatalogForApp(ctx context.Context, customResource v1alpha1
 	}
 
 	if catalog == nil || catalog.Name == "" {
-		return microerror.Maskf(notFoundError, "catalog %#q", catalogName)
+		err = microerror.Maskf(notFoundError, "catalog %#q", catalogName)
+
+		// The reconciliation stops with the error so the condition is set
+		// here instead of by the status resource.
+		cc.SetCondition(customResource, condition.CatalogResolved, metav1.ConditionFalse, "CatalogNotFound", err.Error())
+		_, updateErr := conditions.Update(ctx, r.dynClient, customResource, nil, cc.Conditions)
+		if updateErr != nil {
+			r.logger.Errorf(ctx, updateErr, "failed to set conditions of app %#q", customResource.Name)
+		}
+
+		return err
 	}
 
 	r.logger.Debugf(ctx, "found catalog %#q in namespace %#q", catalogName, catalog.GetNamespace())
 	cc.Catalog = *catalog
+	cc.SetCondition(customResource, condition.CatalogResolved, metav1.ConditionTrue, "CatalogFound", fmt.Sprintf("found catalog %#q in namespace %#q", catalogName, catalog.GetNamespace()))
 
 	return nil
 }
@@ -0,0 +1,57 @@
+package chart
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/app/v5/pkg/key"
+	"github.com/giantswarm/helmclient/v4/pkg/helmclient"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+	"github.com/giantswarm/app-operator/v5/pkg/status"
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+)
+
+// releaseAdoptable returns true if the chart CR can be created because there
+// is no Helm release with the same name and namespace yet or the app adopts
+// the existing release. Otherwise the current chart version of the release is
+// set in the controller context so the status resource reports it.
+func (r *Resource) releaseAdoptable(ctx context.Context, cc *controllercontext.Context, cr v1alpha1.App) (bool, error) {
+	if key.AppName(cr) == key.ChartOperatorAppName {
+		// chart-operator is installed by the chartoperator resource.
+		return true, nil
+	}
+
+	if cc.Clients.Helm == nil {
+		return true, nil
+	}
+
+	release, err := cc.Clients.Helm.GetReleaseContent(ctx, key.Namespace(cr), cr.Name)
+	if helmclient.IsReleaseNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	if isAdoptionEnabled(cr) {
+		r.logger.Debugf(ctx, "adopting release %#q in namespace %#q with chart version %#q", cr.Name, key.Namespace(cr), release.Version)
+		return true, nil
+	}
+
+	reason := fmt.Sprintf("release %#q in namespace %#q already exists with chart version %#q and app version %#q in status %#q, set annotation %#q to %#q to adopt it",
+		cr.Name, key.Namespace(cr), release.Version, release.AppVersion, release.Status, annotation.AppAdopt, "true")
+
+	r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("not creating chart %#q because %s", cr.Name, reason))
+	cc.Status.ChartStatus = controllercontext.ChartStatus{
+		Reason: reason,
+		Status: status.ReleaseAlreadyExistsStatus,
+	}
+
+	return false, nil
+}
+
+func isAdoptionEnabled(cr v1alpha1.App) bool {
+	return cr.GetAnnotations()[annotation.AppAdopt] == "true"
+}
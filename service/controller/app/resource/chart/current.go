@@ -10,6 +10,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/app-operator/v5/pkg/condition"
 	"github.com/giantswarm/app-operator/v5/pkg/status"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
 )
@@ -28,7 +29,8 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 	}
 
 	if status.FailedStatus[cc.Status.ChartStatus.Status] {
-		r.logger.Debugf(ctx, "chart %#q failed to merge configMaps/secrets, no need to reconcile resource", cr.Name)
+		r.logger.Debugf(ctx, "chart %#q has failed status %#q, no need to reconcile resource", cr.Name, cc.Status.ChartStatus.Status)
+		setNotSynced(cc, cr)
 		r.logger.Debugf(ctx, "canceling resource")
 		resourcecanceledcontext.SetCanceled(ctx)
 		return nil, nil
@@ -50,6 +52,7 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 
 	if key.IsAppCordoned(cr) {
 		r.logger.Debugf(ctx, "app %#q is cordoned", cr.Name)
+		cc.SetCondition(cr, condition.ChartSynced, metav1.ConditionFalse, "Cordoned", key.CordonReason(cr))
 		r.logger.Debugf(ctx, "canceling resource")
 		resourcecanceledcontext.SetCanceled(ctx)
 		return nil, nil
@@ -0,0 +1,66 @@
+package chart
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/app-operator/v5/pkg/status"
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/dependency"
+)
+
+// dependenciesReady returns true if all app CRs the app depends on are
+// deployed. Otherwise the reason is set in the controller context so the
+// status resource reports why the chart CR is held back.
+func (r *Resource) dependenciesReady(ctx context.Context, cc *controllercontext.Context, cr v1alpha1.App) (bool, error) {
+	if len(dependency.Names(cr)) == 0 {
+		return true, nil
+	}
+
+	apps, err := r.g8sClient.ApplicationV1alpha1().Apps(cr.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	cycle := dependency.FindCycle(cr, apps.Items)
+	if cycle != nil {
+		reason := fmt.Sprintf("dependency cycle %s", joinNames(cycle, " -> "))
+
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("app %#q is part of a %s", cr.Name, reason))
+		cc.Status.ChartStatus = controllercontext.ChartStatus{
+			Reason: reason,
+			Status: status.DependencyCycleStatus,
+		}
+
+		return false, nil
+	}
+
+	waiting := dependency.Waiting(cr, apps.Items)
+	if len(waiting) > 0 {
+		reason := fmt.Sprintf("waiting for apps %s to be deployed", joinNames(waiting, ", "))
+
+		r.logger.Debugf(ctx, "app %#q is %s", cr.Name, reason)
+		cc.Status.ChartStatus = controllercontext.ChartStatus{
+			Reason: reason,
+			Status: status.WaitingForDependenciesStatus,
+		}
+
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func joinNames(names []string, sep string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = fmt.Sprintf("%#q", n)
+	}
+
+	return strings.Join(quoted, sep)
+}
@@ -2,20 +2,31 @@ package chart
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"sort"
 	"strings"
 
 	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
 	"github.com/giantswarm/app/v5/pkg/key"
-	"github.com/giantswarm/appcatalog"
 	"github.com/giantswarm/k8smetadata/pkg/annotation"
 	"github.com/giantswarm/k8smetadata/pkg/label"
 	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/v5/pkg/controller/context/resourcecanceledcontext"
+	"golang.org/x/crypto/openpgp"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
+	repoannotation "github.com/giantswarm/app-operator/v5/pkg/annotation"
 	"github.com/giantswarm/app-operator/v5/pkg/project"
+	"github.com/giantswarm/app-operator/v5/pkg/status"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/digest"
+	"github.com/giantswarm/app-operator/v5/service/internal/helmrepo"
+	"github.com/giantswarm/app-operator/v5/service/internal/oci"
+	"github.com/giantswarm/app-operator/v5/service/internal/provenance"
+	"github.com/giantswarm/app-operator/v5/service/internal/rollback"
 )
 
 func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interface{}, error) {
@@ -45,9 +56,78 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 		return nil, microerror.Mask(err)
 	}
 
-	tarballURL, err := appcatalog.NewTarballURL(key.CatalogStorageURL(cc.Catalog), key.AppName(cr), key.Version(cr))
+	version := cc.ChartVersion(cr)
+
+	// The index and provenance files of Helm repositories are requested with
+	// the credentials of the catalog.
+	var client *http.Client
+	if !oci.IsOCIStorage(cc.Catalog) {
+		client, err = helmrepo.NewCatalogHTTPClient(ctx, r.k8sClient, cc.Catalog, r.httpClientTimeout)
+		if helmrepo.IsNotFound(err) || helmrepo.IsInvalidConfig(err) {
+			r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("failed to get auth secret %#q of catalog %#q", helmrepo.AuthSecretName(cc.Catalog), cc.Catalog.Name), "stack", fmt.Sprintf("%#v", err))
+			cc.Status.ChartStatus = controllercontext.ChartStatus{
+				Reason: err.Error(),
+				Status: status.ResourceNotFoundStatus,
+			}
+			setNotSynced(cc, cr)
+
+			r.logger.Debugf(ctx, "canceling resource")
+			resourcecanceledcontext.SetCanceled(ctx)
+			return nil, nil
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	tarballURL, err := r.generateTarballURL(ctx, client, cc.Catalog, key.AppName(cr), version)
 	if err != nil {
-		r.logger.Errorf(ctx, err, "failed to generated tarball")
+		return nil, microerror.Mask(err)
+	}
+
+	annotations := generateAnnotations(cr.GetAnnotations(), cr.Namespace)
+
+	chartDigest, err := digest.Get(ctx, r.g8sClient, r.indexCache, client, cc.Catalog, key.AppName(cr), version)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if chartDigest != "" {
+		annotations[repoannotation.ChartOperatorChartDigest] = chartDigest
+	} else {
+		r.logger.Debugf(ctx, "no digest found for chart %#q version %#q, not pinning digest", key.AppName(cr), version)
+	}
+
+	signedDigest, err := r.getSignedDigest(ctx, client, cc.Catalog, key.AppName(cr), version, chartDigest, tarballURL)
+	if provenance.IsVerificationFailed(err) || provenance.IsNotFound(err) || IsProvenanceNotSupported(err) {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("provenance verification of chart %#q failed", cr.Name), "stack", fmt.Sprintf("%#v", err))
+		cc.Status.ChartStatus = controllercontext.ChartStatus{
+			Reason: err.Error(),
+			Status: status.SignatureVerificationFailedStatus,
+		}
+		setNotSynced(cc, cr)
+
+		r.logger.Debugf(ctx, "canceling resource")
+		resourcecanceledcontext.SetCanceled(ctx)
+		return nil, nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if signedDigest != "" {
+		if chartDigest != "" && chartDigest != signedDigest {
+			r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("signed digest %#q of chart %#q does not match catalog digest %#q", signedDigest, cr.Name, chartDigest))
+			cc.Status.ChartStatus = controllercontext.ChartStatus{
+				Reason: fmt.Sprintf("signed digest %#q does not match catalog digest %#q", signedDigest, chartDigest),
+				Status: status.SignatureVerificationFailedStatus,
+			}
+			setNotSynced(cc, cr)
+
+			r.logger.Debugf(ctx, "canceling resource")
+			resourcecanceledcontext.SetCanceled(ctx)
+			return nil, nil
+		}
+
+		// chart-operator verifies the pulled tarball against the signed
+		// digest so it can not be replaced after verification.
+		annotations[repoannotation.ChartOperatorChartDigest] = signedDigest
 	}
 
 	chartCR := &v1alpha1.Chart{
@@ -56,7 +136,7 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 			APIVersion: chartAPIVersion,
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Annotations: generateAnnotations(cr.GetAnnotations(), cr.Namespace),
+			Annotations: annotations,
 			Name:        cr.GetName(),
 			Namespace:   r.chartNamespace,
 			Labels:      processLabels(project.Name(), cr.GetLabels()),
@@ -71,10 +151,24 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 				Labels:      cr.Spec.NamespaceConfig.Labels,
 			},
 			TarballURL: tarballURL,
-			Version:    key.Version(cr),
+			Version:    version,
 		},
 	}
 
+	if rollback.IsEnabled(cr) {
+		state, err := rollback.GetState(cr)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		// Keep the last deployed release until the app CR changes so the
+		// failed release is not applied again.
+		if state.RolledBack != nil && state.LastDeployed != nil && rollback.NewRelease(*chartCR).Equal(*state.RolledBack) {
+			r.logger.Debugf(ctx, "app %#q was %s", cr.Name, state.Message())
+			state.LastDeployed.Apply(chartCR)
+		}
+	}
+
 	return chartCR, nil
 }
 
@@ -145,6 +239,76 @@ func generateInstall(cr v1alpha1.App) v1alpha1.ChartSpecInstall {
 	return v1alpha1.ChartSpecInstall{}
 }
 
+// getSignedDigest verifies the provenance file of the chart against the
+// keyring of the catalog and returns the signed digest of the tarball. It
+// returns an empty string if the catalog does not require provenance
+// verification. Successful verifications are cached per catalog, chart
+// version, catalog digest and keyring so the provenance file is not pulled
+// and verified on every reconciliation.
+func (r *Resource) getSignedDigest(ctx context.Context, client *http.Client, catalog v1alpha1.Catalog, appName, version, chartDigest, tarballURL string) (string, error) {
+	keyring, err := provenance.GetKeyring(ctx, r.k8sClient, catalog)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	if keyring == nil {
+		return "", nil
+	}
+
+	if oci.IsOCIStorage(catalog) {
+		return "", microerror.Maskf(provenanceNotSupportedError, "provenance verification is not supported for OCI catalog %#q", catalog.Name)
+	}
+
+	k := provenanceKey(catalog, appName, version, chartDigest, keyring)
+	if v, ok := r.provenanceCache.Get(k); ok {
+		return v.(string), nil
+	}
+
+	signedDigest, err := provenance.VerifyChart(ctx, client, keyring, tarballURL)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	r.logger.Debugf(ctx, "verified provenance of %#q", tarballURL)
+
+	r.provenanceCache.SetDefault(k, signedDigest)
+
+	return signedDigest, nil
+}
+
+// provenanceKey identifies a provenance verification. The fingerprints of the
+// keyring are part of the key so rotated keys are verified again.
+func provenanceKey(catalog v1alpha1.Catalog, appName, version, chartDigest string, keyring openpgp.EntityList) string {
+	fingerprints := make([]string, 0, len(keyring))
+	for _, e := range keyring {
+		fingerprints = append(fingerprints, fmt.Sprintf("%x", e.PrimaryKey.Fingerprint))
+	}
+	sort.Strings(fingerprints)
+
+	return fmt.Sprintf("%s/%s/%s/%s/%s/%s", catalog.Namespace, catalog.Name, appName, version, chartDigest, strings.Join(fingerprints, ","))
+}
+
+// generateTarballURL returns the tarball URL from the index for Helm
+// repositories and the chart reference for catalogs stored in an OCI
+// registry.
+func (r *Resource) generateTarballURL(ctx context.Context, client *http.Client, catalog v1alpha1.Catalog, appName, version string) (string, error) {
+	if oci.IsOCIStorage(catalog) {
+		ref, err := oci.ChartReference(key.CatalogStorageURL(catalog), appName, version)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		return ref, nil
+	}
+
+	tarballURL, err := r.indexCache.TarballURL(ctx, client, key.CatalogStorageURL(catalog), appName, version)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return tarballURL, nil
+}
+
 func hasConfigMap(cr v1alpha1.App, catalog v1alpha1.Catalog) bool {
 	if key.AppConfigMapName(cr) != "" || key.CatalogConfigMapName(catalog) != "" || key.UserConfigMapName(cr) != "" {
 		return true
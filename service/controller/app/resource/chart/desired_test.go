@@ -2,8 +2,12 @@ package chart
 
 import (
 	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
 	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned/fake"
@@ -16,16 +20,18 @@ import (
 	clientgofake "k8s.io/client-go/kubernetes/fake"
 
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
 )
 
 func Test_Resource_GetDesiredState(t *testing.T) {
 	tests := []struct {
-		name          string
-		obj           *v1alpha1.App
-		catalog       v1alpha1.Catalog
-		configMap     *corev1.ConfigMap
-		expectedChart *v1alpha1.Chart
-		error         bool
+		name            string
+		obj             *v1alpha1.App
+		catalog         v1alpha1.Catalog
+		appCatalogEntry *v1alpha1.AppCatalogEntry
+		configMap       *corev1.ConfigMap
+		expectedChart   *v1alpha1.Chart
+		error           bool
 	}{
 		{
 			name: "case 0: flawless flow",
@@ -178,17 +184,57 @@ func Test_Resource_GetDesiredState(t *testing.T) {
 					Namespace: "giantswarm",
 				},
 			},
+			error: true,
+		},
+		{
+			name: "case 2: set helm force upgrade annotation",
+			obj: &v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"chart-operator.giantswarm.io/force-helm-upgrade": "true",
+					},
+					Name:      "my-cool-prometheus",
+					Namespace: "default",
+					Labels: map[string]string{
+						"app":                                "prometheus",
+						"app-operator.giantswarm.io/version": "1.0.0",
+						"giantswarm.io/managed-by":           "cluster-operator",
+					},
+				},
+				Spec: v1alpha1.AppSpec{
+					Catalog:   "giantswarm",
+					Name:      "prometheus",
+					Namespace: "monitoring",
+					Version:   "1.0.0",
+					KubeConfig: v1alpha1.AppSpecKubeConfig{
+						InCluster: true,
+					},
+				},
+			},
+			catalog: v1alpha1.Catalog{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "giantswarm",
+				},
+				Spec: v1alpha1.CatalogSpec{
+					Title: "Giant Swarm",
+					Storage: v1alpha1.CatalogSpecStorage{
+						Type: "helm",
+						URL:  "https://giantswarm.github.io/app-catalog/",
+					},
+				},
+			},
 			expectedChart: &v1alpha1.Chart{
 				TypeMeta: metav1.TypeMeta{
 					Kind:       "Chart",
 					APIVersion: "application.giantswarm.io",
 				},
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "my-cool-prometheus",
-					Namespace: "giantswarm",
 					Annotations: map[string]string{
-						"chart-operator.giantswarm.io/app-namespace": "default",
+						"chart-operator.giantswarm.io/app-namespace":      "default",
+						"chart-operator.giantswarm.io/force-helm-upgrade": "true",
 					},
+					Name:      "my-cool-prometheus",
+					Namespace: "giantswarm",
 					Labels: map[string]string{
 						"app":                                  "prometheus",
 						"chart-operator.giantswarm.io/version": "1.0.0",
@@ -196,34 +242,78 @@ func Test_Resource_GetDesiredState(t *testing.T) {
 					},
 				},
 				Spec: v1alpha1.ChartSpec{
-					Config: v1alpha1.ChartSpecConfig{
-						ConfigMap: v1alpha1.ChartSpecConfigConfigMap{
-							Name:      "my-cool-prometheus-chart-values",
-							Namespace: "giantswarm",
-						},
-					},
 					Name:       "my-cool-prometheus",
 					Namespace:  "monitoring",
-					TarballURL: "",
+					TarballURL: "https://giantswarm.github.io/app-catalog/prometheus-1.0.0.tgz",
 					Version:    "1.0.0",
 				},
 			},
 		},
 		{
-			name: "case 2: set helm force upgrade annotation",
+			name: "case 3: oci catalog",
 			obj: &v1alpha1.App{
 				ObjectMeta: metav1.ObjectMeta{
-					Annotations: map[string]string{
-						"chart-operator.giantswarm.io/force-helm-upgrade": "true",
-					},
 					Name:      "my-cool-prometheus",
 					Namespace: "default",
 					Labels: map[string]string{
 						"app":                                "prometheus",
 						"app-operator.giantswarm.io/version": "1.0.0",
-						"giantswarm.io/managed-by":           "cluster-operator",
 					},
 				},
+				Spec: v1alpha1.AppSpec{
+					Catalog:   "giantswarm-oci",
+					Name:      "prometheus",
+					Namespace: "monitoring",
+					Version:   "1.0.0",
+					KubeConfig: v1alpha1.AppSpecKubeConfig{
+						InCluster: true,
+					},
+				},
+			},
+			catalog: v1alpha1.Catalog{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "giantswarm-oci",
+				},
+				Spec: v1alpha1.CatalogSpec{
+					Title: "Giant Swarm OCI",
+					Storage: v1alpha1.CatalogSpecStorage{
+						Type: "oci",
+						URL:  "oci://giantswarmpublic.azurecr.io/giantswarm-catalog/",
+					},
+				},
+			},
+			expectedChart: &v1alpha1.Chart{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "Chart",
+					APIVersion: "application.giantswarm.io",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"chart-operator.giantswarm.io/app-namespace": "default",
+					},
+					Name:      "my-cool-prometheus",
+					Namespace: "giantswarm",
+					Labels: map[string]string{
+						"app":                                  "prometheus",
+						"chart-operator.giantswarm.io/version": "1.0.0",
+						"giantswarm.io/managed-by":             "app-operator",
+					},
+				},
+				Spec: v1alpha1.ChartSpec{
+					Name:       "my-cool-prometheus",
+					Namespace:  "monitoring",
+					TarballURL: "oci://giantswarmpublic.azurecr.io/giantswarm-catalog/prometheus:1.0.0",
+					Version:    "1.0.0",
+				},
+			},
+		},
+		{
+			name: "case 4: appcatalogentry with digest",
+			obj: &v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-cool-prometheus",
+					Namespace: "default",
+				},
 				Spec: v1alpha1.AppSpec{
 					Catalog:   "giantswarm",
 					Name:      "prometheus",
@@ -236,7 +326,8 @@ func Test_Resource_GetDesiredState(t *testing.T) {
 			},
 			catalog: v1alpha1.Catalog{
 				ObjectMeta: metav1.ObjectMeta{
-					Name: "giantswarm",
+					Name:      "giantswarm",
+					Namespace: "default",
 				},
 				Spec: v1alpha1.CatalogSpec{
 					Title: "Giant Swarm",
@@ -246,6 +337,15 @@ func Test_Resource_GetDesiredState(t *testing.T) {
 					},
 				},
 			},
+			appCatalogEntry: &v1alpha1.AppCatalogEntry{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "giantswarm-prometheus-1.0.0",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"application.giantswarm.io/chart-digest": "cc57fc1903e444cf6a726490b43b27ee9f87facc037f86872201847c565b45fb",
+					},
+				},
+			},
 			expectedChart: &v1alpha1.Chart{
 				TypeMeta: metav1.TypeMeta{
 					Kind:       "Chart",
@@ -253,13 +353,12 @@ func Test_Resource_GetDesiredState(t *testing.T) {
 				},
 				ObjectMeta: metav1.ObjectMeta{
 					Annotations: map[string]string{
-						"chart-operator.giantswarm.io/app-namespace":      "default",
-						"chart-operator.giantswarm.io/force-helm-upgrade": "true",
+						"chart-operator.giantswarm.io/app-namespace": "default",
+						"chart-operator.giantswarm.io/chart-digest":  "cc57fc1903e444cf6a726490b43b27ee9f87facc037f86872201847c565b45fb",
 					},
 					Name:      "my-cool-prometheus",
 					Namespace: "giantswarm",
 					Labels: map[string]string{
-						"app":                                  "prometheus",
 						"chart-operator.giantswarm.io/version": "1.0.0",
 						"giantswarm.io/managed-by":             "app-operator",
 					},
@@ -281,10 +380,35 @@ func Test_Resource_GetDesiredState(t *testing.T) {
 				objs = append(objs, tc.configMap)
 			}
 
+			g8sObjs := make([]runtime.Object, 0)
+			if tc.appCatalogEntry != nil {
+				g8sObjs = append(g8sObjs, tc.appCatalogEntry)
+			}
+
+			indexCache, err := indexcache.New(indexcache.Config{Logger: microloggertest.New()})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			indexCache.Set("https://giantswarm.github.io/app-catalog/", indexcache.Index{
+				Entries: map[string][]indexcache.Entry{
+					"prometheus": {
+						{
+							Name:    "prometheus",
+							URLs:    []string{"prometheus-1.0.0.tgz"},
+							Version: "1.0.0",
+						},
+					},
+				},
+			})
+
 			c := Config{
-				Logger: microloggertest.New(),
+				G8sClient:  fake.NewSimpleClientset(g8sObjs...),
+				IndexCache: indexCache,
+				K8sClient:  clientgofake.NewSimpleClientset(),
+				Logger:     microloggertest.New(),
 
-				ChartNamespace: "giantswarm",
+				ChartNamespace:    "giantswarm",
+				HTTPClientTimeout: 5 * time.Second,
 			}
 			r, err := New(c)
 			if err != nil {
@@ -671,3 +795,282 @@ func Test_processLabels(t *testing.T) {
 		})
 	}
 }
+
+func Test_Resource_GetDesiredState_provenance(t *testing.T) {
+	prov, err := ioutil.ReadFile("../../../../internal/provenance/testdata/hashtest-1.2.3.tgz.prov")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	keyring, err := ioutil.ReadFile("../../../../internal/provenance/testdata/helm-test-key.pub")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	var provRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/hashtest-1.2.3.tgz.prov" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		provRequests++
+		_, _ = w.Write(prov)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		keyringSecret   *corev1.Secret
+		appCatalogEntry *v1alpha1.AppCatalogEntry
+		expectedDigest  string
+		expectedStatus  string
+	}{
+		{
+			name: "case 0: signed digest is set",
+			keyringSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "giantswarm-keyring",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					"keyring": keyring,
+				},
+			},
+			expectedDigest: "c6841b3a895f1444a6738b5d04564a57e860ce42f8519c3be807fb6d9bee7888",
+		},
+		{
+			name:           "case 1: missing keyring secret",
+			expectedStatus: "signature-verification-failed",
+		},
+		{
+			name: "case 2: signed digest does not match catalog digest",
+			keyringSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "giantswarm-keyring",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					"keyring": keyring,
+				},
+			},
+			appCatalogEntry: &v1alpha1.AppCatalogEntry{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "giantswarm-hashtest-1.2.3",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"application.giantswarm.io/chart-digest": "cc57fc1903e444cf6a726490b43b27ee9f87facc037f86872201847c565b45fb",
+					},
+				},
+			},
+			expectedStatus: "signature-verification-failed",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			objs := make([]runtime.Object, 0)
+			if tc.keyringSecret != nil {
+				objs = append(objs, tc.keyringSecret)
+			}
+
+			g8sObjs := make([]runtime.Object, 0)
+			if tc.appCatalogEntry != nil {
+				g8sObjs = append(g8sObjs, tc.appCatalogEntry)
+			}
+
+			indexCache, err := indexcache.New(indexcache.Config{Logger: microloggertest.New()})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			indexCache.Set(server.URL, indexcache.Index{
+				Entries: map[string][]indexcache.Entry{
+					"hashtest": {
+						{Name: "hashtest", Version: "1.2.3", URLs: []string{"hashtest-1.2.3.tgz"}},
+					},
+				},
+			})
+
+			c := Config{
+				G8sClient:  fake.NewSimpleClientset(g8sObjs...),
+				IndexCache: indexCache,
+				K8sClient:  clientgofake.NewSimpleClientset(objs...),
+				Logger:     microloggertest.New(),
+
+				ChartNamespace:    "giantswarm",
+				HTTPClientTimeout: 5 * time.Second,
+			}
+			r, err := New(c)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			var ctx context.Context
+			{
+				config := k8sclienttest.ClientsConfig{
+					G8sClient: fake.NewSimpleClientset(),
+					K8sClient: clientgofake.NewSimpleClientset(),
+				}
+
+				c := controllercontext.Context{
+					Clients: controllercontext.Clients{
+						K8s: k8sclienttest.NewClients(config),
+					},
+					Catalog: v1alpha1.Catalog{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "giantswarm",
+							Namespace: "default",
+							Annotations: map[string]string{
+								"application.giantswarm.io/provenance-keyring-secret": "giantswarm-keyring",
+							},
+						},
+						Spec: v1alpha1.CatalogSpec{
+							Storage: v1alpha1.CatalogSpecStorage{
+								Type: "helm",
+								URL:  server.URL,
+							},
+						},
+					},
+				}
+				ctx = controllercontext.NewContext(context.Background(), c)
+			}
+
+			app := &v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hashtest",
+					Namespace: "default",
+				},
+				Spec: v1alpha1.AppSpec{
+					Catalog:   "giantswarm",
+					Name:      "hashtest",
+					Namespace: "default",
+					Version:   "1.2.3",
+					KubeConfig: v1alpha1.AppSpecKubeConfig{
+						InCluster: true,
+					},
+				},
+			}
+
+			result, err := r.GetDesiredState(ctx, app)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			cc, err := controllercontext.FromContext(ctx)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			if cc.Status.ChartStatus.Status != tc.expectedStatus {
+				t.Fatalf("status == %#q, want %#q", cc.Status.ChartStatus.Status, tc.expectedStatus)
+			}
+
+			if tc.expectedStatus != "" {
+				return
+			}
+
+			chart, err := toChart(result)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			d := chart.Annotations["chart-operator.giantswarm.io/chart-digest"]
+			if d != tc.expectedDigest {
+				t.Fatalf("digest == %#q, want %#q", d, tc.expectedDigest)
+			}
+
+			// The verified provenance is cached so the provenance file is
+			// not pulled again.
+			provRequests = 0
+
+			_, err = r.GetDesiredState(ctx, app)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			if provRequests != 0 {
+				t.Fatalf("provenance requests == %d, want 0", provRequests)
+			}
+		})
+	}
+}
+
+func Test_Resource_GetDesiredState_authSecret(t *testing.T) {
+	indexCache, err := indexcache.New(indexcache.Config{Logger: microloggertest.New()})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	c := Config{
+		G8sClient:  fake.NewSimpleClientset(),
+		IndexCache: indexCache,
+		K8sClient:  clientgofake.NewSimpleClientset(),
+		Logger:     microloggertest.New(),
+
+		ChartNamespace:    "giantswarm",
+		HTTPClientTimeout: 5 * time.Second,
+	}
+	r, err := New(c)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	var ctx context.Context
+	{
+		config := k8sclienttest.ClientsConfig{
+			G8sClient: fake.NewSimpleClientset(),
+			K8sClient: clientgofake.NewSimpleClientset(),
+		}
+
+		c := controllercontext.Context{
+			Clients: controllercontext.Clients{
+				K8s: k8sclienttest.NewClients(config),
+			},
+			Catalog: v1alpha1.Catalog{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "giantswarm",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"application.giantswarm.io/catalog-auth-secret": "giantswarm-auth",
+					},
+				},
+				Spec: v1alpha1.CatalogSpec{
+					Storage: v1alpha1.CatalogSpecStorage{
+						Type: "helm",
+						URL:  "https://giantswarm.github.io/app-catalog/",
+					},
+				},
+			},
+		}
+		ctx = controllercontext.NewContext(context.Background(), c)
+	}
+
+	app := &v1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "prometheus",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AppSpec{
+			Catalog:   "giantswarm",
+			Name:      "prometheus",
+			Namespace: "monitoring",
+			Version:   "1.0.0",
+			KubeConfig: v1alpha1.AppSpecKubeConfig{
+				InCluster: true,
+			},
+		},
+	}
+
+	result, err := r.GetDesiredState(ctx, app)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if result != nil {
+		t.Fatalf("result == %#v, want nil", result)
+	}
+
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if cc.Status.ChartStatus.Status != "resource-not-found" {
+		t.Fatalf("status == %#q, want %#q", cc.Status.ChartStatus.Status, "resource-not-found")
+	}
+}
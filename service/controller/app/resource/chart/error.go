@@ -20,6 +20,15 @@ func IsNotFound(err error) bool {
 	return microerror.Cause(err) == notFoundError
 }
 
+var provenanceNotSupportedError = &microerror.Error{
+	Kind: "provenanceNotSupportedError",
+}
+
+// IsProvenanceNotSupported asserts provenanceNotSupportedError.
+func IsProvenanceNotSupported(err error) bool {
+	return microerror.Cause(err) == provenanceNotSupportedError
+}
+
 var wrongTypeError = &microerror.Error{
 	Kind: "wrongTypeError",
 }
@@ -0,0 +1,32 @@
+package chart
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+
+	"github.com/giantswarm/app-operator/v5/pkg/status"
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+)
+
+// maintenanceWindowOpen returns true if the update of the chart CR can be
+// applied now. Otherwise the reason is set in the controller context so the
+// status resource reports that the update is held back.
+func (r *Resource) maintenanceWindowOpen(ctx context.Context, cc *controllercontext.Context, cr v1alpha1.App, currentChart, updateChart *v1alpha1.Chart) bool {
+	if !cc.Maintenance.Closed {
+		return true
+	}
+
+	if cc.Maintenance.ConfigChangesExempt && currentChart.Spec.Version == updateChart.Spec.Version && currentChart.Spec.TarballURL == updateChart.Spec.TarballURL {
+		r.logger.Debugf(ctx, "applying config change of chart %#q outside maintenance window", cr.Name)
+		return true
+	}
+
+	r.logger.Debugf(ctx, "app %#q is %s", cr.Name, cc.Maintenance.Reason)
+	cc.Status.ChartStatus = controllercontext.ChartStatus{
+		Reason: cc.Maintenance.Reason,
+		Status: status.WaitingForMaintenanceWindowStatus,
+	}
+
+	return false
+}
@@ -4,15 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned"
 	"github.com/giantswarm/k8smetadata/pkg/annotation"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
+	gocache "github.com/patrickmn/go-cache"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
 )
 
 const (
@@ -22,28 +27,50 @@ const (
 	chartAPIVersion            = "application.giantswarm.io"
 	chartKind                  = "Chart"
 	chartCustomResourceVersion = "1.0.0"
+
+	// provenanceExpiration bounds how long a verified provenance file is
+	// trusted without downloading it again, e.g. after it was re-signed.
+	provenanceExpiration = 1 * time.Hour
 )
 
 // Config represents the configuration used to create a new chart resource.
 type Config struct {
 	// Dependencies.
-	Logger micrologger.Logger
+	G8sClient  versioned.Interface
+	IndexCache *indexcache.Resource
+	K8sClient  kubernetes.Interface
+	Logger     micrologger.Logger
 
 	// Settings.
-	ChartNamespace string
+	ChartNamespace    string
+	HTTPClientTimeout time.Duration
 }
 
 // Resource implements the chart resource.
 type Resource struct {
 	// Dependencies.
-	logger micrologger.Logger
+	g8sClient       versioned.Interface
+	indexCache      *indexcache.Resource
+	k8sClient       kubernetes.Interface
+	logger          micrologger.Logger
+	provenanceCache *gocache.Cache
 
 	// Settings.
-	chartNamespace string
+	chartNamespace    string
+	httpClientTimeout time.Duration
 }
 
 // New creates a new configured chart resource.
 func New(config Config) (*Resource, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.IndexCache == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.IndexCache must not be empty", config)
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
@@ -51,11 +78,19 @@ func New(config Config) (*Resource, error) {
 	if config.ChartNamespace == "" {
 		return nil, microerror.Maskf(invalidConfigError, "%T.ChartNamespace must not be empty", config)
 	}
+	if config.HTTPClientTimeout == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.HTTPClientTimeout must not be empty", config)
+	}
 
 	r := &Resource{
-		logger: config.Logger,
-
-		chartNamespace: config.ChartNamespace,
+		g8sClient:       config.G8sClient,
+		indexCache:      config.IndexCache,
+		k8sClient:       config.K8sClient,
+		logger:          config.Logger,
+		provenanceCache: gocache.New(provenanceExpiration, provenanceExpiration/2),
+
+		chartNamespace:    config.ChartNamespace,
+		httpClientTimeout: config.HTTPClientTimeout,
 	}
 
 	return r, nil
@@ -6,12 +6,15 @@ import (
 	"reflect"
 
 	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/app/v5/pkg/key"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/operatorkit/v5/pkg/resource/crud"
 	"github.com/google/go-cmp/cmp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/app-operator/v5/pkg/condition"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/dryrun"
 )
 
 func (r *Resource) ApplyUpdateChange(ctx context.Context, obj, updateChange interface{}) error {
@@ -43,6 +46,15 @@ func (r *Resource) ApplyUpdateChange(ctx context.Context, obj, updateChange inte
 }
 
 func (r *Resource) NewUpdatePatch(ctx context.Context, obj, currentChart, desiredChart interface{}) (*crud.Patch, error) {
+	cr, err := key.ToApp(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
 	create, err := r.newCreateChange(ctx, currentChart, desiredChart)
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -54,12 +66,105 @@ func (r *Resource) NewUpdatePatch(ctx context.Context, obj, currentChart, desire
 	}
 
 	patch := crud.NewPatch()
+
+	if hasChange(create) || hasChange(update) {
+		if dryrun.IsEnabled(cr) {
+			err = r.addDryRunChange(ctx, cc, currentChart, desiredChart)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+			cc.SetCondition(cr, condition.ChartSynced, metav1.ConditionFalse, "DryRun", "changes are not applied in dry run mode")
+
+			return patch, nil
+		}
+
+		ready, err := r.dependenciesReady(ctx, cc, cr)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		if !ready {
+			r.logger.Debugf(ctx, "holding back changes of chart %#q until its dependencies are deployed", cr.Name)
+			setNotSynced(cc, cr)
+			return patch, nil
+		}
+
+		if hasChange(create) {
+			adoptable, err := r.releaseAdoptable(ctx, cc, cr)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+
+			if !adoptable {
+				r.logger.Debugf(ctx, "not creating chart %#q until its existing release is adopted", cr.Name)
+				setNotSynced(cc, cr)
+				return patch, nil
+			}
+		}
+
+		if hasChange(update) {
+			currentChart, err := toChart(currentChart)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+
+			if !r.maintenanceWindowOpen(ctx, cc, cr, currentChart, update.(*v1alpha1.Chart)) {
+				r.logger.Debugf(ctx, "holding back changes of chart %#q until its maintenance window opens", cr.Name)
+				setNotSynced(cc, cr)
+				return patch, nil
+			}
+		}
+	}
+
 	patch.SetCreateChange(create)
 	patch.SetUpdateChange(update)
 
+	cc.SetCondition(cr, condition.ChartSynced, metav1.ConditionTrue, "Synced", "")
+
 	return patch, nil
 }
 
+// addDryRunChange adds the diff of the chart CR to the controller context
+// instead of applying it.
+func (r *Resource) addDryRunChange(ctx context.Context, cc *controllercontext.Context, currentResource, desiredResource interface{}) error {
+	currentChart, err := toChart(currentResource)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	desiredChart, err := toChart(desiredResource)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if !reflect.DeepEqual(currentChart, &v1alpha1.Chart{}) {
+		// Compare the same fields as newUpdateChange.
+		currentChart = copyChart(currentChart)
+		desiredChart = desiredChart.DeepCopy()
+		copyAnnotations(currentChart, desiredChart)
+	}
+
+	r.logger.Debugf(ctx, "dry run enabled, not applying changes to chart %#q", desiredChart.Name)
+
+	cc.DryRunChanges = append(cc.DryRunChanges, dryrun.Change{
+		Kind: "chart",
+		Name: desiredChart.Name,
+		Diff: dryrun.ChartDiff(currentChart, desiredChart),
+	})
+
+	return nil
+}
+
+// setNotSynced sets the ChartSynced condition to false with the status set in
+// the controller context, e.g. when changes are held back.
+func setNotSynced(cc *controllercontext.Context, cr v1alpha1.App) {
+	cc.SetCondition(cr, condition.ChartSynced, metav1.ConditionFalse, condition.Reason(cc.Status.ChartStatus.Status), cc.Status.ChartStatus.Reason)
+}
+
+func hasChange(change interface{}) bool {
+	chart, ok := change.(*v1alpha1.Chart)
+	return ok && chart.Name != ""
+}
+
 func (r *Resource) newUpdateChange(ctx context.Context, currentResource, desiredResource interface{}) (interface{}, error) {
 	currentChart, err := toChart(currentResource)
 	if err != nil {
@@ -3,18 +3,24 @@ package chart
 import (
 	"context"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
 	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/helmclient/v4/pkg/helmclient"
+	"github.com/giantswarm/helmclient/v4/pkg/helmclienttest"
 	"github.com/giantswarm/k8sclient/v5/pkg/k8sclienttest"
 	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/v5/pkg/resource/crud"
 	"github.com/google/go-cmp/cmp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgofake "k8s.io/client-go/kubernetes/fake"
 
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
 )
 
 func Test_Resource_newUpdateChange(t *testing.T) {
@@ -192,10 +198,19 @@ func Test_Resource_newUpdateChange(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			objs := make([]runtime.Object, 0)
 
+			indexCache, err := indexcache.New(indexcache.Config{Logger: microloggertest.New()})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
 			c := Config{
-				Logger: microloggertest.New(),
+				G8sClient:  fake.NewSimpleClientset(),
+				IndexCache: indexCache,
+				K8sClient:  clientgofake.NewSimpleClientset(),
+				Logger:     microloggertest.New(),
 
-				ChartNamespace: "giantswarm",
+				ChartNamespace:    "giantswarm",
+				HTTPClientTimeout: 5 * time.Second,
 			}
 			r, err := New(c)
 			if err != nil {
@@ -247,3 +262,284 @@ func Test_Resource_newUpdateChange(t *testing.T) {
 		})
 	}
 }
+
+func Test_Resource_NewUpdatePatch_dependencies(t *testing.T) {
+	newApp := func(name, dependsOn, status string) *v1alpha1.App {
+		app := &v1alpha1.App{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Status: v1alpha1.AppStatus{
+				Release: v1alpha1.AppStatusRelease{
+					Status: status,
+				},
+			},
+		}
+		if dependsOn != "" {
+			app.Annotations = map[string]string{
+				"app-operator.giantswarm.io/depends-on": dependsOn,
+			}
+		}
+
+		return app
+	}
+
+	tests := []struct {
+		name           string
+		obj            *v1alpha1.App
+		apps           []runtime.Object
+		expectedHeld   bool
+		expectedStatus string
+	}{
+		{
+			name: "case 0: dependency is deployed",
+			obj:  newApp("ingress", "cert-manager", ""),
+			apps: []runtime.Object{
+				newApp("cert-manager", "", "deployed"),
+			},
+		},
+		{
+			name: "case 1: dependency is not deployed",
+			obj:  newApp("ingress", "cert-manager", ""),
+			apps: []runtime.Object{
+				newApp("cert-manager", "", "pending-install"),
+			},
+			expectedHeld:   true,
+			expectedStatus: "waiting-for-dependencies",
+		},
+		{
+			name: "case 2: dependency cycle",
+			obj:  newApp("ingress", "cert-manager", ""),
+			apps: []runtime.Object{
+				newApp("cert-manager", "ingress", "deployed"),
+			},
+			expectedHeld:   true,
+			expectedStatus: "dependency-cycle",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			indexCache, err := indexcache.New(indexcache.Config{Logger: microloggertest.New()})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			c := Config{
+				G8sClient:  fake.NewSimpleClientset(append(tc.apps, tc.obj)...),
+				IndexCache: indexCache,
+				K8sClient:  clientgofake.NewSimpleClientset(),
+				Logger:     microloggertest.New(),
+
+				ChartNamespace:    "giantswarm",
+				HTTPClientTimeout: 5 * time.Second,
+			}
+			r, err := New(c)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{})
+
+			desiredChart := &v1alpha1.Chart{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      tc.obj.Name,
+					Namespace: "giantswarm",
+				},
+			}
+
+			patch, err := r.NewUpdatePatch(ctx, tc.obj, &v1alpha1.Chart{}, desiredChart)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			held := reflect.DeepEqual(patch, crud.NewPatch())
+			if held != tc.expectedHeld {
+				t.Fatalf("held == %t, want %t", held, tc.expectedHeld)
+			}
+
+			cc, err := controllercontext.FromContext(ctx)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			if cc.Status.ChartStatus.Status != tc.expectedStatus {
+				t.Fatalf("status == %#q, want %#q", cc.Status.ChartStatus.Status, tc.expectedStatus)
+			}
+		})
+	}
+}
+
+func Test_Resource_NewUpdatePatch_dryRun(t *testing.T) {
+	indexCache, err := indexcache.New(indexcache.Config{Logger: microloggertest.New()})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	c := Config{
+		G8sClient:  fake.NewSimpleClientset(),
+		IndexCache: indexCache,
+		K8sClient:  clientgofake.NewSimpleClientset(),
+		Logger:     microloggertest.New(),
+
+		ChartNamespace:    "giantswarm",
+		HTTPClientTimeout: 5 * time.Second,
+	}
+	r, err := New(c)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{})
+
+	obj := &v1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				"app-operator.giantswarm.io/dry-run": "true",
+			},
+			Name:      "prometheus",
+			Namespace: "default",
+		},
+	}
+	currentChart := &v1alpha1.Chart{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "prometheus",
+			Namespace: "giantswarm",
+		},
+		Spec: v1alpha1.ChartSpec{
+			Version: "1.0.0",
+		},
+	}
+	desiredChart := &v1alpha1.Chart{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "prometheus",
+			Namespace: "giantswarm",
+		},
+		Spec: v1alpha1.ChartSpec{
+			Version: "1.1.0",
+		},
+	}
+
+	patch, err := r.NewUpdatePatch(ctx, obj, currentChart, desiredChart)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if !reflect.DeepEqual(patch, crud.NewPatch()) {
+		t.Fatalf("patch == %#v, want empty", patch)
+	}
+
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if len(cc.DryRunChanges) != 1 {
+		t.Fatalf("dry run changes == %d, want 1", len(cc.DryRunChanges))
+	}
+	if !strings.Contains(cc.DryRunChanges[0].Diff, `"1.1.0"`) {
+		t.Fatalf("diff == %#q, want version change", cc.DryRunChanges[0].Diff)
+	}
+}
+
+func Test_Resource_NewUpdatePatch_adoption(t *testing.T) {
+	release := &helmclient.ReleaseContent{
+		AppVersion: "2.0.0",
+		Name:       "prometheus",
+		Status:     "deployed",
+		Version:    "1.0.0",
+	}
+
+	tests := []struct {
+		name           string
+		annotations    map[string]string
+		helmClient     helmclient.Interface
+		expectedHeld   bool
+		expectedStatus string
+	}{
+		{
+			name: "case 0: no helm client",
+		},
+		{
+			name:        "case 1: existing release is adopted",
+			annotations: map[string]string{"app-operator.giantswarm.io/adopt": "true"},
+			helmClient: helmclienttest.New(helmclienttest.Config{
+				DefaultReleaseContent: release,
+			}),
+		},
+		{
+			name: "case 2: existing release is not adopted",
+			helmClient: helmclienttest.New(helmclienttest.Config{
+				DefaultReleaseContent: release,
+			}),
+			expectedHeld:   true,
+			expectedStatus: "release-already-exists",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			indexCache, err := indexcache.New(indexcache.Config{Logger: microloggertest.New()})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			c := Config{
+				G8sClient:  fake.NewSimpleClientset(),
+				IndexCache: indexCache,
+				K8sClient:  clientgofake.NewSimpleClientset(),
+				Logger:     microloggertest.New(),
+
+				ChartNamespace:    "giantswarm",
+				HTTPClientTimeout: 5 * time.Second,
+			}
+			r, err := New(c)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+				Clients: controllercontext.Clients{
+					Helm: tc.helmClient,
+				},
+			})
+
+			obj := &v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tc.annotations,
+					Name:        "prometheus",
+					Namespace:   "default",
+				},
+				Spec: v1alpha1.AppSpec{
+					Name:      "prometheus",
+					Namespace: "monitoring",
+				},
+			}
+			desiredChart := &v1alpha1.Chart{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "prometheus",
+					Namespace: "giantswarm",
+				},
+			}
+
+			patch, err := r.NewUpdatePatch(ctx, obj, &v1alpha1.Chart{}, desiredChart)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			held := reflect.DeepEqual(patch, crud.NewPatch())
+			if held != tc.expectedHeld {
+				t.Fatalf("held == %t, want %t", held, tc.expectedHeld)
+			}
+
+			cc, err := controllercontext.FromContext(ctx)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			if cc.Status.ChartStatus.Status != tc.expectedStatus {
+				t.Fatalf("status == %#q, want %#q", cc.Status.ChartStatus.Status, tc.expectedStatus)
+			}
+			if tc.expectedHeld && !strings.Contains(cc.Status.ChartStatus.Reason, "`1.0.0`") {
+				t.Fatalf("reason == %#q, want chart version", cc.Status.ChartStatus.Reason)
+			}
+		})
+	}
+}
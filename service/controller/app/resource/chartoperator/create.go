@@ -3,6 +3,7 @@ package chartoperator
 import (
 	"context"
 
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
 	"github.com/giantswarm/app/v5/pkg/key"
 	"github.com/giantswarm/errors/tenant"
 	"github.com/giantswarm/helmclient/v4/pkg/helmclient"
@@ -11,6 +12,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/app-operator/v5/pkg/status"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
 )
 
@@ -84,6 +86,10 @@ func (r Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 				r.logger.Debugf(ctx, "canceling reconciliation")
 				reconciliationcanceledcontext.SetCanceled(ctx)
 
+				return nil
+			} else if isSignatureVerificationFailed(err) {
+				r.setSignatureVerificationFailed(ctx, cc, cr, err)
+				r.logger.Debugf(ctx, "canceling resource")
 				return nil
 			} else if err != nil {
 				return microerror.Mask(err)
@@ -106,7 +112,11 @@ func (r Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 			r.logger.Debugf(ctx, "updating release %#q", cr.Name)
 
 			err = r.updateChartOperator(ctx, cr)
-			if err != nil {
+			if isSignatureVerificationFailed(err) {
+				r.setSignatureVerificationFailed(ctx, cc, cr, err)
+				r.logger.Debugf(ctx, "canceling resource")
+				return nil
+			} else if err != nil {
 				return microerror.Mask(err)
 			}
 
@@ -131,3 +141,14 @@ func (r Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 
 	return nil
 }
+
+// setSignatureVerificationFailed sets the status of the app CR so the status
+// resource reports that the chart-operator tarball was not installed.
+func (r Resource) setSignatureVerificationFailed(ctx context.Context, cc *controllercontext.Context, cr v1alpha1.App, err error) {
+	r.logger.Debugf(ctx, "provenance verification of release %#q failed: %s", cr.Name, err.Error())
+
+	cc.Status.ChartStatus = controllercontext.ChartStatus{
+		Reason: err.Error(),
+		Status: status.SignatureVerificationFailedStatus,
+	}
+}
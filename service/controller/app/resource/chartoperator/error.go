@@ -1,6 +1,11 @@
 package chartoperator
 
-import "github.com/giantswarm/microerror"
+import (
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/app-operator/v5/service/internal/digest"
+	"github.com/giantswarm/app-operator/v5/service/internal/provenance"
+)
 
 var invalidConfigError = &microerror.Error{
 	Kind: "invalidConfigError",
@@ -19,3 +24,11 @@ var notReadyError = &microerror.Error{
 func IsNotReady(err error) bool {
 	return microerror.Cause(err) == notReadyError
 }
+
+// isSignatureVerificationFailed asserts the provenance of the chart-operator
+// tarball could not be verified, including a missing keyring, or the tarball
+// does not match the signed or catalog digest or the catalog has no digest for
+// its version.
+func isSignatureVerificationFailed(err error) bool {
+	return provenance.IsVerificationFailed(err) || provenance.IsNotFound(err) || digest.IsMismatch(err)
+}
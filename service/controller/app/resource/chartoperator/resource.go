@@ -2,12 +2,11 @@ package chartoperator
 
 import (
 	"context"
+	"time"
 
 	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
 	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned"
 	"github.com/giantswarm/app/v5/pkg/key"
-	"github.com/giantswarm/app/v5/pkg/values"
-	"github.com/giantswarm/appcatalog"
 	"github.com/giantswarm/helmclient/v4/pkg/helmclient"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
@@ -17,6 +16,11 @@ import (
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/digest"
+	"github.com/giantswarm/app-operator/v5/service/internal/helmrepo"
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
+	"github.com/giantswarm/app-operator/v5/service/internal/provenance"
+	"github.com/giantswarm/app-operator/v5/service/internal/values"
 )
 
 const (
@@ -28,24 +32,28 @@ type Config struct {
 	// Dependencies.
 	FileSystem afero.Fs
 	G8sClient  versioned.Interface
+	IndexCache *indexcache.Resource
 	K8sClient  kubernetes.Interface
 	Logger     micrologger.Logger
 	Values     *values.Values
 
 	// Settings.
-	ChartNamespace string
+	ChartNamespace    string
+	HTTPClientTimeout time.Duration
 }
 
 type Resource struct {
 	// Dependencies.
 	fileSystem afero.Fs
 	g8sClient  versioned.Interface
+	indexCache *indexcache.Resource
 	k8sClient  kubernetes.Interface
 	logger     micrologger.Logger
 	values     *values.Values
 
 	// Settings.
-	chartNamespace string
+	chartNamespace    string
+	httpClientTimeout time.Duration
 }
 
 // New creates a new configured chartoperator resource.
@@ -56,6 +64,9 @@ func New(config Config) (*Resource, error) {
 	if config.G8sClient == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
 	}
+	if config.IndexCache == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.IndexCache must not be empty", config)
+	}
 	if config.K8sClient == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
 	}
@@ -69,16 +80,21 @@ func New(config Config) (*Resource, error) {
 	if config.ChartNamespace == "" {
 		return nil, microerror.Maskf(invalidConfigError, "%T.ChartNamespace must not be empty", config)
 	}
+	if config.HTTPClientTimeout == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.HTTPClientTimeout must not be empty", config)
+	}
 
 	r := &Resource{
 		// Dependencies.
 		fileSystem: config.FileSystem,
 		g8sClient:  config.G8sClient,
+		indexCache: config.IndexCache,
 		k8sClient:  config.K8sClient,
 		logger:     config.Logger,
 		values:     config.Values,
 
-		chartNamespace: config.ChartNamespace,
+		chartNamespace:    config.ChartNamespace,
+		httpClientTimeout: config.HTTPClientTimeout,
 	}
 
 	return r, nil
@@ -99,18 +115,14 @@ func (r Resource) installChartOperator(ctx context.Context, cr v1alpha1.App) err
 		return microerror.Mask(err)
 	}
 
-	// check app CR for chart-operator and fetching app-catalog name and version.
-	var tarballURL string
+	var tarballPath string
 	{
-		tarballURL, err = appcatalog.NewTarballURL(key.CatalogStorageURL(cc.Catalog), key.AppName(cr), key.Version(cr))
+		tarballURL, err := r.chartTarballURL(ctx, cr)
 		if err != nil {
 			return microerror.Mask(err)
 		}
-	}
 
-	var tarballPath string
-	{
-		tarballPath, err = cc.Clients.Helm.PullChartTarball(ctx, tarballURL)
+		tarballPath, err = r.pullChartTarball(ctx, tarballURL)
 		if err != nil {
 			return microerror.Mask(err)
 		}
@@ -121,6 +133,11 @@ func (r Resource) installChartOperator(ctx context.Context, cr v1alpha1.App) err
 				r.logger.Errorf(ctx, err, "deletion of %#q failed", tarballPath)
 			}
 		}()
+
+		err = r.verifyChartTarball(ctx, cr, tarballURL, tarballPath)
+		if err != nil {
+			return microerror.Mask(err)
+		}
 	}
 
 	{
@@ -151,18 +168,14 @@ func (r Resource) updateChartOperator(ctx context.Context, cr v1alpha1.App) erro
 		return microerror.Mask(err)
 	}
 
-	// check app CR for chart-operator and fetching app-catalog name and version.
-	var tarballURL string
+	var tarballPath string
 	{
-		tarballURL, err = appcatalog.NewTarballURL(key.CatalogStorageURL(cc.Catalog), key.AppName(cr), key.Version(cr))
+		tarballURL, err := r.chartTarballURL(ctx, cr)
 		if err != nil {
 			return microerror.Mask(err)
 		}
-	}
 
-	var tarballPath string
-	{
-		tarballPath, err = cc.Clients.Helm.PullChartTarball(ctx, tarballURL)
+		tarballPath, err = r.pullChartTarball(ctx, tarballURL)
 		if err != nil {
 			return microerror.Mask(err)
 		}
@@ -173,6 +186,11 @@ func (r Resource) updateChartOperator(ctx context.Context, cr v1alpha1.App) erro
 				r.logger.Errorf(ctx, err, "deletion of %#q failed", tarballPath)
 			}
 		}()
+
+		err = r.verifyChartTarball(ctx, cr, tarballURL, tarballPath)
+		if err != nil {
+			return microerror.Mask(err)
+		}
 	}
 
 	{
@@ -193,6 +211,119 @@ func (r Resource) updateChartOperator(ctx context.Context, cr v1alpha1.App) erro
 	return nil
 }
 
+// chartTarballURL returns the URL of the chart-operator tarball from the
+// index of the catalog.
+func (r Resource) chartTarballURL(ctx context.Context, cr v1alpha1.App) (string, error) {
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	client, err := helmrepo.NewCatalogHTTPClient(ctx, r.k8sClient, cc.Catalog, r.httpClientTimeout)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	// check app CR for chart-operator and fetching app-catalog name and version.
+	tarballURL, err := r.indexCache.TarballURL(ctx, client, key.CatalogStorageURL(cc.Catalog), key.AppName(cr), cc.ChartVersion(cr))
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return tarballURL, nil
+}
+
+// pullChartTarball pulls the chart-operator tarball from the catalog. The
+// repository credentials of the catalog are used if it references an auth
+// secret.
+func (r Resource) pullChartTarball(ctx context.Context, tarballURL string) (string, error) {
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	credentials, err := helmrepo.GetCredentials(ctx, r.k8sClient, cc.Catalog)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	if credentials == nil {
+		tarballPath, err := cc.Clients.Helm.PullChartTarball(ctx, tarballURL)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		return tarballPath, nil
+	}
+
+	client, err := helmrepo.NewHTTPClient(key.CatalogStorageURL(cc.Catalog), credentials, r.httpClientTimeout)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	tarballPath, err := helmrepo.PullTarball(ctx, client, r.fileSystem, tarballURL)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return tarballPath, nil
+}
+
+// verifyChartTarball verifies the pulled tarball against the digest from the
+// appcatalogentry CR or the catalog index so tampered or republished charts
+// are refused. If the catalog requires provenance verification the tarball is
+// also verified against the signed digest of its provenance file.
+func (r Resource) verifyChartTarball(ctx context.Context, cr v1alpha1.App, tarballURL, tarballPath string) error {
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	client, err := helmrepo.NewCatalogHTTPClient(ctx, r.k8sClient, cc.Catalog, r.httpClientTimeout)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	expected, err := digest.Get(ctx, r.g8sClient, r.indexCache, client, cc.Catalog, key.AppName(cr), cc.ChartVersion(cr))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if expected == "" {
+		r.logger.Debugf(ctx, "no digest found for %#q version %#q, skipping verification", key.AppName(cr), cc.ChartVersion(cr))
+	} else {
+		err = digest.Verify(r.fileSystem, tarballPath, expected)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.Debugf(ctx, "verified digest of %#q version %#q", key.AppName(cr), cc.ChartVersion(cr))
+	}
+
+	keyring, err := provenance.GetKeyring(ctx, r.k8sClient, cc.Catalog)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if keyring == nil {
+		return nil
+	}
+
+	signed, err := provenance.VerifyChart(ctx, client, keyring, tarballURL)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = digest.Verify(r.fileSystem, tarballPath, signed)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.Debugf(ctx, "verified provenance of %#q version %#q", key.AppName(cr), cc.ChartVersion(cr))
+
+	return nil
+}
+
 func (r Resource) uninstallChartOperator(ctx context.Context, cr v1alpha1.App) error {
 	cc, err := controllercontext.FromContext(ctx)
 	if err != nil {
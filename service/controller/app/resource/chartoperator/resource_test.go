@@ -0,0 +1,188 @@
+package chartoperator
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgofake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
+	"github.com/giantswarm/app-operator/v5/service/internal/values"
+)
+
+func Test_Resource_verifyChartTarball(t *testing.T) {
+	prov, err := ioutil.ReadFile("../../../../internal/provenance/testdata/hashtest-1.2.3.tgz.prov")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	keyring, err := ioutil.ReadFile("../../../../internal/provenance/testdata/helm-test-key.pub")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	var index string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.yaml":
+			_, _ = w.Write([]byte(index))
+		case "/hashtest-1.2.3.tgz.prov":
+			_, _ = w.Write(prov)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		appCatalogEntry *v1alpha1.AppCatalogEntry
+		index           string
+	}{
+		{
+			name: "case 0: tampered tarball with valid provenance file",
+			index: `entries:
+  hashtest:
+  - name: hashtest
+    urls:
+    - hashtest-1.2.3.tgz
+    version: 1.2.3
+`,
+		},
+		{
+			name: "case 1: tarball does not match catalog digest",
+			appCatalogEntry: &v1alpha1.AppCatalogEntry{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "giantswarm-hashtest-1.2.3",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"application.giantswarm.io/chart-digest": "c6841b3a895f1444a6738b5d04564a57e860ce42f8519c3be807fb6d9bee7888",
+					},
+				},
+			},
+		},
+		{
+			name: "case 2: tarball does not match digest in index of pruned entry",
+			index: `entries:
+  hashtest:
+  - digest: c6841b3a895f1444a6738b5d04564a57e860ce42f8519c3be807fb6d9bee7888
+    name: hashtest
+    urls:
+    - hashtest-1.2.3.tgz
+    version: 1.2.3
+`,
+		},
+		{
+			name: "case 3: chart version not found in index fails provenance verification",
+			index: `entries:
+  hashtest:
+  - digest: c6841b3a895f1444a6738b5d04564a57e860ce42f8519c3be807fb6d9bee7888
+    name: hashtest
+    urls:
+    - hashtest-1.2.4.tgz
+    version: 1.2.4
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			index = tc.index
+
+			fs := afero.NewMemMapFs()
+			err := afero.WriteFile(fs, "/tmp/hashtest-1.2.3.tgz", []byte("tampered"), 0644)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			g8sObjs := make([]runtime.Object, 0)
+			if tc.appCatalogEntry != nil {
+				g8sObjs = append(g8sObjs, tc.appCatalogEntry)
+			}
+
+			k8sClient := clientgofake.NewSimpleClientset(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "giantswarm-keyring",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					"keyring": keyring,
+				},
+			})
+
+			indexCache, err := indexcache.New(indexcache.Config{Logger: microloggertest.New()})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			v, err := values.New(values.Config{
+				K8sClient: k8sClient,
+				Logger:    microloggertest.New(),
+			})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			r, err := New(Config{
+				FileSystem: fs,
+				G8sClient:  fake.NewSimpleClientset(g8sObjs...),
+				IndexCache: indexCache,
+				K8sClient:  k8sClient,
+				Logger:     microloggertest.New(),
+				Values:     v,
+
+				ChartNamespace:    "giantswarm",
+				HTTPClientTimeout: 5 * time.Second,
+			})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			c := controllercontext.Context{
+				Catalog: v1alpha1.Catalog{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "giantswarm",
+						Namespace: "default",
+						Annotations: map[string]string{
+							"application.giantswarm.io/provenance-keyring-secret": "giantswarm-keyring",
+						},
+					},
+					Spec: v1alpha1.CatalogSpec{
+						Storage: v1alpha1.CatalogSpecStorage{
+							Type: "helm",
+							URL:  server.URL,
+						},
+					},
+				},
+			}
+			ctx := controllercontext.NewContext(context.Background(), c)
+
+			app := v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hashtest",
+					Namespace: "default",
+				},
+				Spec: v1alpha1.AppSpec{
+					Catalog: "giantswarm",
+					Name:    "hashtest",
+					Version: "1.2.3",
+				},
+			}
+
+			err = r.verifyChartTarball(ctx, app, server.URL+"/hashtest-1.2.3.tgz", "/tmp/hashtest-1.2.3.tgz")
+			if !isSignatureVerificationFailed(err) {
+				t.Fatalf("error == %#v, want signature verification failed", err)
+			}
+		})
+	}
+}
@@ -11,7 +11,9 @@ import (
 	"github.com/giantswarm/kubeconfig/v4"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/app-operator/v5/pkg/condition"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
 	"github.com/giantswarm/app-operator/v5/service/internal/clientcache"
 )
@@ -87,6 +89,7 @@ func (r *Resource) addClientsToContext(ctx context.Context, cr v1alpha1.App) err
 			K8s:  r.k8sClient,
 			Helm: r.helmClient,
 		}
+		cc.SetCondition(cr, condition.ClusterReachable, metav1.ConditionTrue, "InCluster", "")
 
 		return nil
 	}
@@ -96,6 +99,7 @@ func (r *Resource) addClientsToContext(ctx context.Context, cr v1alpha1.App) err
 		// Set status so we don't try to connect to the workload cluster
 		// again in this reconciliation loop.
 		cc.Status.ClusterStatus.IsUnavailable = true
+		cc.SetCondition(cr, condition.ClusterReachable, metav1.ConditionFalse, "KubeConfigNotFound", err.Error())
 
 		r.logger.Debugf(ctx, "kubeconfig secret not found")
 		r.logger.Debugf(ctx, "canceling resource")
@@ -104,6 +108,7 @@ func (r *Resource) addClientsToContext(ctx context.Context, cr v1alpha1.App) err
 		// Set status so we don't try to connect to the workload cluster
 		// again in this reconciliation loop.
 		cc.Status.ClusterStatus.IsUnavailable = true
+		cc.SetCondition(cr, condition.ClusterReachable, metav1.ConditionFalse, "APINotAvailable", err.Error())
 
 		r.logger.Debugf(ctx, "workload API not available yet")
 		r.logger.Debugf(ctx, "canceling resource")
@@ -116,6 +121,7 @@ func (r *Resource) addClientsToContext(ctx context.Context, cr v1alpha1.App) err
 		K8s:  clients.K8sClient,
 		Helm: clients.HelmClient,
 	}
+	cc.SetCondition(cr, condition.ClusterReachable, metav1.ConditionTrue, "Reachable", "")
 
 	return nil
 }
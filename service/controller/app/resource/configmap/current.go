@@ -12,6 +12,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/app-operator/v5/pkg/status"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
 )
 
@@ -42,6 +43,13 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 		return nil, nil
 	}
 
+	if cc.Status.ChartStatus.Status == status.ValuesSchemaInvalidStatus {
+		r.logger.Debugf(ctx, "values of app %#q do not match values schema, not updating configmap", cr.Name)
+		r.logger.Debugf(ctx, "canceling resource")
+		resourcecanceledcontext.SetCanceled(ctx)
+		return nil, nil
+	}
+
 	if key.IsAppCordoned(cr) {
 		r.logger.Debugf(ctx, "app %#q is cordoned", cr.Name)
 		r.logger.Debugf(ctx, "canceling resource")
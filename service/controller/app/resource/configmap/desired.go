@@ -2,10 +2,10 @@ package configmap
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/giantswarm/app/v5/pkg/key"
-	"github.com/giantswarm/app/v5/pkg/values"
 	"github.com/giantswarm/k8smetadata/pkg/annotation"
 	"github.com/giantswarm/k8smetadata/pkg/label"
 	"github.com/giantswarm/microerror"
@@ -14,9 +14,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 
+	pkgannotation "github.com/giantswarm/app-operator/v5/pkg/annotation"
+	"github.com/giantswarm/app-operator/v5/pkg/condition"
 	"github.com/giantswarm/app-operator/v5/pkg/project"
 	"github.com/giantswarm/app-operator/v5/pkg/status"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/values"
 )
 
 func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interface{}, error) {
@@ -30,6 +33,8 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 	}
 
 	if key.IsDeleted(cr) {
+		r.valuesProvenance.Delete(cr.Namespace, cr.Name)
+
 		// Return empty chart configmap so it is deleted.
 		configMap := &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -41,10 +46,11 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 		return configMap, nil
 	}
 
-	mergedData, err := r.values.MergeConfigMapData(ctx, cr, cc.Catalog)
+	layers, err := r.values.ConfigMapLayers(ctx, cr, cc.Catalog)
 	if values.IsNotFound(err) {
 		r.logger.LogCtx(ctx, "level", "warning", "message", "dependent configMaps are not found")
 		addStatusToContext(cc, err.Error(), status.ConfigmapMergeFailedStatus)
+		cc.SetCondition(cr, condition.ValuesMerged, metav1.ConditionFalse, condition.Reason(status.ConfigmapMergeFailedStatus), err.Error())
 
 		r.logger.Debugf(ctx, "canceling resource")
 		resourcecanceledcontext.SetCanceled(ctx)
@@ -52,6 +58,20 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 	} else if values.IsParsingError(err) {
 		r.logger.LogCtx(ctx, "level", "warning", "message", "failed to merging configMaps")
 		addStatusToContext(cc, err.Error(), status.ConfigmapMergeFailedStatus)
+		cc.SetCondition(cr, condition.ValuesMerged, metav1.ConditionFalse, condition.Reason(status.ConfigmapMergeFailedStatus), err.Error())
+
+		r.logger.Debugf(ctx, "canceling resource")
+		resourcecanceledcontext.SetCanceled(ctx)
+		return nil, nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	mergedData, err := r.values.MergeAndRender(cr, layers)
+	if values.IsTemplateError(err) {
+		r.logger.LogCtx(ctx, "level", "warning", "message", "failed to render configmap values")
+		addStatusToContext(cc, err.Error(), status.ValuesTemplateFailedStatus)
+		cc.SetCondition(cr, condition.ValuesMerged, metav1.ConditionFalse, condition.Reason(status.ValuesTemplateFailedStatus), err.Error())
 
 		r.logger.Debugf(ctx, "canceling resource")
 		resourcecanceledcontext.SetCanceled(ctx)
@@ -60,6 +80,16 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 		return nil, microerror.Mask(err)
 	}
 
+	provenance, err := values.Provenance(layers)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	r.valuesProvenance.SetConfigMap(cr.Namespace, cr.Name, provenance)
+
+	// The secret resource sets the condition to false when merging the
+	// secrets fails.
+	cc.SetCondition(cr, condition.ValuesMerged, metav1.ConditionTrue, "Merged", "")
+
 	if mergedData == nil {
 		// Return early.
 		return nil, nil
@@ -86,5 +116,14 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 		},
 	}
 
+	if cr.Annotations[pkgannotation.AppValuesProvenance] == "true" {
+		bytes, err := json.Marshal(provenance)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		configMap.Annotations[pkgannotation.ChartConfigMapValuesProvenance] = string(bytes)
+	}
+
 	return configMap, nil
 }
@@ -7,7 +7,6 @@ import (
 	"testing"
 
 	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
-	"github.com/giantswarm/app/v5/pkg/values"
 	"github.com/giantswarm/k8smetadata/pkg/annotation"
 	"github.com/giantswarm/k8smetadata/pkg/label"
 	"github.com/giantswarm/micrologger/microloggertest"
@@ -17,7 +16,10 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgofake "k8s.io/client-go/kubernetes/fake"
 
+	pkgannotation "github.com/giantswarm/app-operator/v5/pkg/annotation"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/values"
+	"github.com/giantswarm/app-operator/v5/service/valuesprovenance"
 )
 
 func Test_Resource_GetDesiredState(t *testing.T) {
@@ -100,6 +102,76 @@ func Test_Resource_GetDesiredState(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "case 2: values provenance is stored when enabled",
+			obj: &v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-prometheus",
+					Namespace: "giantswarm",
+					Annotations: map[string]string{
+						pkgannotation.AppValuesProvenance: "true",
+					},
+				},
+				Spec: v1alpha1.AppSpec{
+					Catalog:   "app-catalog",
+					Name:      "prometheus",
+					Namespace: "monitoring",
+					Config: v1alpha1.AppSpecConfig{
+						ConfigMap: v1alpha1.AppSpecConfigConfigMap{
+							Name:      "test-cluster-values",
+							Namespace: "giantswarm",
+						},
+					},
+					UserConfig: v1alpha1.AppSpecUserConfig{
+						ConfigMap: v1alpha1.AppSpecUserConfigConfigMap{
+							Name:      "test-user-values",
+							Namespace: "giantswarm",
+						},
+					},
+				},
+			},
+			catalog: v1alpha1.Catalog{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-catalog",
+				},
+			},
+			configMaps: []*corev1.ConfigMap{
+				{
+					Data: map[string]string{
+						"values": "cluster: yaml\nreplicas: 1\n",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-cluster-values",
+						Namespace: "giantswarm",
+					},
+				},
+				{
+					Data: map[string]string{
+						"values": "replicas: 3\n",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-user-values",
+						Namespace: "giantswarm",
+					},
+				},
+			},
+			expectedConfigMap: &corev1.ConfigMap{
+				Data: map[string]string{
+					"values": "cluster: yaml\nreplicas: 3\n",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-prometheus-chart-values",
+					Namespace: "giantswarm",
+					Annotations: map[string]string{
+						annotation.Notes: "DO NOT EDIT. Values managed by app-operator.",
+						pkgannotation.ChartConfigMapValuesProvenance: `{"cluster":"cluster configmap giantswarm/test-cluster-values","replicas":"user configmap giantswarm/test-user-values"}`,
+					},
+					Labels: map[string]string{
+						label.ManagedBy: "app-operator",
+					},
+				},
+			},
+		},
 	}
 
 	var err error
@@ -133,8 +205,9 @@ func Test_Resource_GetDesiredState(t *testing.T) {
 			}
 
 			c := Config{
-				Logger: microloggertest.New(),
-				Values: valuesService,
+				Logger:           microloggertest.New(),
+				Values:           valuesService,
+				ValuesProvenance: valuesprovenance.New(),
 
 				ChartNamespace: "giantswarm",
 			}
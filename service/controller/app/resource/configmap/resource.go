@@ -4,12 +4,13 @@ import (
 	"reflect"
 
 	"github.com/ghodss/yaml"
-	"github.com/giantswarm/app/v5/pkg/values"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/values"
+	"github.com/giantswarm/app-operator/v5/service/valuesprovenance"
 )
 
 const (
@@ -22,8 +23,9 @@ const (
 // Config represents the configuration used to create a new configmap resource.
 type Config struct {
 	// Dependencies.
-	Logger micrologger.Logger
-	Values *values.Values
+	Logger           micrologger.Logger
+	Values           *values.Values
+	ValuesProvenance *valuesprovenance.Service
 
 	// Settings.
 	ChartNamespace string
@@ -32,8 +34,9 @@ type Config struct {
 // Resource implements the configmap resource.
 type Resource struct {
 	// Dependencies.
-	logger micrologger.Logger
-	values *values.Values
+	logger           micrologger.Logger
+	values           *values.Values
+	valuesProvenance *valuesprovenance.Service
 
 	// Settings.
 	chartNamespace string
@@ -47,14 +50,18 @@ func New(config Config) (*Resource, error) {
 	if config.Values == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Values must not be empty", config)
 	}
+	if config.ValuesProvenance == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ValuesProvenance must not be empty", config)
+	}
 
 	if config.ChartNamespace == "" {
 		return nil, microerror.Maskf(invalidConfigError, "%T.ChartNamespace must not be empty", config)
 	}
 
 	r := &Resource{
-		logger: config.Logger,
-		values: config.Values,
+		logger:           config.Logger,
+		values:           config.Values,
+		valuesProvenance: config.ValuesProvenance,
 
 		chartNamespace: config.ChartNamespace,
 	}
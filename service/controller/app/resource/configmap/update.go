@@ -3,12 +3,16 @@ package configmap
 import (
 	"context"
 
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/app/v5/pkg/key"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/operatorkit/v5/pkg/resource/crud"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/app-operator/v5/pkg/status"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/dryrun"
 )
 
 func (r *Resource) ApplyUpdateChange(ctx context.Context, obj, updateChange interface{}) error {
@@ -52,6 +56,27 @@ func (r *Resource) NewUpdatePatch(ctx context.Context, obj, currentConfigMap, de
 		return nil, microerror.Mask(err)
 	}
 
+	cr, err := key.ToApp(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if dryrun.IsEnabled(cr) {
+		err = r.addDryRunChange(ctx, currentConfigMap, desiredConfigMap)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return crud.NewPatch(), nil
+	}
+
+	held, err := r.heldForMaintenance(ctx, cr, update, delete)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if held {
+		return crud.NewPatch(), nil
+	}
+
 	patch := crud.NewPatch()
 	patch.SetCreateChange(create)
 	patch.SetUpdateChange(update)
@@ -88,3 +113,76 @@ func (r *Resource) newUpdateChange(ctx context.Context, currentResource, desired
 
 	return updateConfigMap, nil
 }
+
+// addDryRunChange adds the diff of the configmap to the controller context
+// instead of applying it.
+func (r *Resource) addDryRunChange(ctx context.Context, currentResource, desiredResource interface{}) error {
+	currentConfigMap, err := toConfigMap(currentResource)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	desiredConfigMap, err := toConfigMap(desiredResource)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if equals(currentConfigMap, desiredConfigMap) {
+		return nil
+	}
+
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	name := desiredConfigMap.Name
+	if isEmpty(desiredConfigMap) {
+		name = currentConfigMap.Name
+	}
+
+	r.logger.Debugf(ctx, "dry run enabled, not applying changes to configmap %#q", name)
+
+	cc.DryRunChanges = append(cc.DryRunChanges, dryrun.Change{
+		Kind: "configmap",
+		Name: name,
+		Diff: dryrun.ConfigMapDiff(currentConfigMap, desiredConfigMap),
+	})
+
+	return nil
+}
+
+// heldForMaintenance returns true if the update or deletion of the configmap is
+// held back because the maintenance window of the app is closed. Otherwise
+// chart-operator would upgrade the release with the new values outside the
+// window.
+func (r *Resource) heldForMaintenance(ctx context.Context, cr v1alpha1.App, updateChange, deleteChange interface{}) (bool, error) {
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	if !cc.Maintenance.HoldsConfigChanges() {
+		return false, nil
+	}
+
+	update, err := toConfigMap(updateChange)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	delete, err := toConfigMap(deleteChange)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	if isEmpty(update) && isEmpty(delete) {
+		return false, nil
+	}
+
+	r.logger.Debugf(ctx, "holding back changes of configmap until the maintenance window of app %#q opens", cr.Name)
+	cc.Status.ChartStatus = controllercontext.ChartStatus{
+		Reason: cc.Maintenance.Reason,
+		Status: status.WaitingForMaintenanceWindowStatus,
+	}
+
+	return true, nil
+}
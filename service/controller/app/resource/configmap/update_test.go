@@ -0,0 +1,122 @@
+package configmap
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/v5/pkg/resource/crud"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgofake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/values"
+	"github.com/giantswarm/app-operator/v5/service/valuesprovenance"
+)
+
+func Test_Resource_NewUpdatePatch_maintenance(t *testing.T) {
+	tests := []struct {
+		name           string
+		maintenance    controllercontext.Maintenance
+		expectedHeld   bool
+		expectedStatus string
+	}{
+		{
+			name: "case 0: values change without maintenance window",
+		},
+		{
+			name: "case 1: values change outside maintenance window",
+			maintenance: controllercontext.Maintenance{
+				Closed: true,
+				Reason: "waiting for maintenance window opening at 2021-09-01T22:00:00Z",
+			},
+			expectedHeld:   true,
+			expectedStatus: "waiting-for-maintenance-window",
+		},
+		{
+			name: "case 2: values change outside maintenance window exempting config changes",
+			maintenance: controllercontext.Maintenance{
+				Closed:              true,
+				ConfigChangesExempt: true,
+				Reason:              "waiting for maintenance window opening at 2021-09-01T22:00:00Z",
+			},
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			valuesService, err := values.New(values.Config{
+				K8sClient: clientgofake.NewSimpleClientset(),
+				Logger:    microloggertest.New(),
+			})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			c := Config{
+				Logger:           microloggertest.New(),
+				Values:           valuesService,
+				ValuesProvenance: valuesprovenance.New(),
+
+				ChartNamespace: "giantswarm",
+			}
+			r, err := New(c)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+				Maintenance: tc.maintenance,
+			})
+
+			obj := &v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "prometheus",
+					Namespace: "default",
+				},
+			}
+			currentConfigMap := &corev1.ConfigMap{
+				Data: map[string]string{
+					"values": "replicas: 1\n",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "prometheus-chart-values",
+					Namespace: "giantswarm",
+				},
+			}
+			desiredConfigMap := &corev1.ConfigMap{
+				Data: map[string]string{
+					"values": "replicas: 3\n",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "prometheus-chart-values",
+					Namespace: "giantswarm",
+				},
+			}
+
+			patch, err := r.NewUpdatePatch(ctx, obj, currentConfigMap, desiredConfigMap)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			held := reflect.DeepEqual(patch, crud.NewPatch())
+			if held != tc.expectedHeld {
+				t.Fatalf("held == %t, want %t", held, tc.expectedHeld)
+			}
+
+			cc, err := controllercontext.FromContext(ctx)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			if cc.Status.ChartStatus.Status != tc.expectedStatus {
+				t.Fatalf("status == %#q, want %#q", cc.Status.ChartStatus.Status, tc.expectedStatus)
+			}
+		})
+	}
+}
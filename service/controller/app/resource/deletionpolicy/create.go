@@ -0,0 +1,11 @@
+package deletionpolicy
+
+import (
+	"context"
+)
+
+// EnsureCreated is a no-op because the deletion policy only applies when the
+// app CR is deleted.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	return nil
+}
@@ -0,0 +1,76 @@
+package deletionpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/giantswarm/app/v5/pkg/key"
+	k8smetadataannotation "github.com/giantswarm/k8smetadata/pkg/annotation"
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+	"github.com/giantswarm/app-operator/v5/pkg/project"
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+)
+
+// EnsureDeleted cordons the chart CR of apps with the orphan deletion policy
+// before the chart resource deletes it. chart-operator skips the release
+// resource of cordoned charts, so it removes its finalizer without
+// uninstalling the Helm release. The generated values are still deleted by
+// the configmap and secret resources.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	cr, err := key.ToApp(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if policy, ok := cr.GetAnnotations()[annotation.AppDeletionPolicy]; ok && policy != OrphanPolicy && policy != DeletePolicy {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("unknown deletion policy %#q of app %#q, deleting Helm release", policy, cr.Name))
+	}
+	if !IsOrphan(cr) {
+		return nil
+	}
+
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if cc.Status.ClusterStatus.IsDeleting || cc.Status.ClusterStatus.IsUnavailable {
+		r.logger.Debugf(ctx, "workload cluster is deleting or unavailable")
+		r.logger.Debugf(ctx, "canceling resource")
+		return nil
+	}
+
+	r.logger.Debugf(ctx, "orphaning Chart CR %#q in namespace %#q", cr.Name, r.chartNamespace)
+
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				k8smetadataannotation.ChartOperatorCordonReason: fmt.Sprintf("app %#q is deleted with orphan deletion policy by %s", cr.Name, project.Name()),
+				k8smetadataannotation.ChartOperatorCordonUntil:  key.CordonUntilDate(),
+			},
+		},
+	}
+
+	bytes, err := json.Marshal(patch)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	_, err = cc.Clients.K8s.G8sClient().ApplicationV1alpha1().Charts(r.chartNamespace).Patch(ctx, cr.Name, types.MergePatchType, bytes, metav1.PatchOptions{})
+	if apierrors.IsNotFound(err) {
+		r.logger.Debugf(ctx, "Chart CR %#q in namespace %#q not found", cr.Name, r.chartNamespace)
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.Debugf(ctx, "orphaned Chart CR %#q in namespace %#q", cr.Name, r.chartNamespace)
+
+	return nil
+}
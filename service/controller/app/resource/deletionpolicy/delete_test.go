@@ -0,0 +1,251 @@
+package deletionpolicy
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/k8sclient/v5/pkg/k8sclienttest"
+	k8smetadataannotation "github.com/giantswarm/k8smetadata/pkg/annotation"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/v5/pkg/resource"
+	"github.com/giantswarm/operatorkit/v5/pkg/resource/crud"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgofake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	chartresource "github.com/giantswarm/app-operator/v5/service/controller/app/resource/chart"
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
+)
+
+func Test_Resource_EnsureDeleted(t *testing.T) {
+	tests := []struct {
+		name             string
+		deletionPolicy   string
+		expectedCordoned bool
+	}{
+		{
+			name:             "case 0: chart is not changed by default",
+			expectedCordoned: false,
+		},
+		{
+			name:             "case 1: chart is cordoned",
+			deletionPolicy:   OrphanPolicy,
+			expectedCordoned: true,
+		},
+		{
+			name:             "case 2: chart is not changed with delete policy",
+			deletionPolicy:   DeletePolicy,
+			expectedCordoned: false,
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			app := &v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-prometheus",
+					Namespace: "default",
+				},
+			}
+			if tc.deletionPolicy != "" {
+				app.Annotations = map[string]string{
+					annotation.AppDeletionPolicy: tc.deletionPolicy,
+				}
+			}
+
+			chart := &v1alpha1.Chart{
+				ObjectMeta: metav1.ObjectMeta{
+					Finalizers: []string{
+						"operatorkit.giantswarm.io/chart-operator-chart",
+					},
+					Name:      "my-prometheus",
+					Namespace: "giantswarm",
+				},
+			}
+
+			wcG8sClient := fake.NewSimpleClientset(chart)
+
+			r, err := New(Config{
+				Logger: microloggertest.New(),
+
+				ChartNamespace: "giantswarm",
+			})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			c := controllercontext.Context{
+				Clients: controllercontext.Clients{
+					K8s: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+						G8sClient: wcG8sClient,
+					}),
+				},
+			}
+			ctx := controllercontext.NewContext(context.Background(), c)
+
+			err = r.EnsureDeleted(ctx, app)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			result, err := wcG8sClient.ApplicationV1alpha1().Charts("giantswarm").Get(ctx, "my-prometheus", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			if isCordoned(result) != tc.expectedCordoned {
+				t.Fatalf("cordoned == %t, want %t", isCordoned(result), tc.expectedCordoned)
+			}
+			// The finalizer of chart-operator must be kept so it still
+			// reconciles the deletion and skips the cordoned release.
+			if len(result.Finalizers) != 1 {
+				t.Fatalf("finalizers == %#v, want 1", result.Finalizers)
+			}
+		})
+	}
+}
+
+// Test_Resource_EnsureDeleted_chart runs the deletionpolicy and chart
+// resources in the order of the app controller and checks that the chart CR
+// is cordoned when it is deleted.
+func Test_Resource_EnsureDeleted_chart(t *testing.T) {
+	tests := []struct {
+		name             string
+		deletionPolicy   string
+		expectedCordoned bool
+	}{
+		{
+			name:             "case 0: chart is deleted",
+			expectedCordoned: false,
+		},
+		{
+			name:             "case 1: chart is cordoned before it is deleted",
+			deletionPolicy:   OrphanPolicy,
+			expectedCordoned: true,
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			app := &v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+					Name:              "my-prometheus",
+					Namespace:         "default",
+				},
+				Spec: v1alpha1.AppSpec{
+					Name: "prometheus",
+				},
+			}
+			if tc.deletionPolicy != "" {
+				app.Annotations = map[string]string{
+					annotation.AppDeletionPolicy: tc.deletionPolicy,
+				}
+			}
+
+			chart := &v1alpha1.Chart{
+				ObjectMeta: metav1.ObjectMeta{
+					Finalizers: []string{
+						"operatorkit.giantswarm.io/chart-operator-chart",
+					},
+					Name:      "my-prometheus",
+					Namespace: "giantswarm",
+				},
+			}
+
+			wcG8sClient := fake.NewSimpleClientset(chart)
+
+			var deleted, cordoned bool
+			wcG8sClient.PrependReactor("delete", "charts", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				obj, err := wcG8sClient.Tracker().Get(action.GetResource(), action.GetNamespace(), "my-prometheus")
+				if err != nil {
+					return true, nil, err
+				}
+
+				deleted = true
+				cordoned = isCordoned(obj.(*v1alpha1.Chart))
+
+				return false, nil, nil
+			})
+
+			indexCache, err := indexcache.New(indexcache.Config{Logger: microloggertest.New()})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			var resources []resource.Interface
+			{
+				r, err := New(Config{
+					Logger: microloggertest.New(),
+
+					ChartNamespace: "giantswarm",
+				})
+				if err != nil {
+					t.Fatalf("error == %#v, want nil", err)
+				}
+				resources = append(resources, r)
+			}
+			{
+				c, err := chartresource.New(chartresource.Config{
+					G8sClient:  wcG8sClient,
+					IndexCache: indexCache,
+					K8sClient:  clientgofake.NewSimpleClientset(),
+					Logger:     microloggertest.New(),
+
+					ChartNamespace:    "giantswarm",
+					HTTPClientTimeout: time.Second,
+				})
+				if err != nil {
+					t.Fatalf("error == %#v, want nil", err)
+				}
+
+				r, err := crud.NewResource(crud.ResourceConfig{
+					CRUD:   c,
+					Logger: microloggertest.New(),
+				})
+				if err != nil {
+					t.Fatalf("error == %#v, want nil", err)
+				}
+				resources = append(resources, r)
+			}
+
+			c := controllercontext.Context{
+				Clients: controllercontext.Clients{
+					K8s: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+						G8sClient: wcG8sClient,
+					}),
+				},
+			}
+			ctx := controllercontext.NewContext(context.Background(), c)
+
+			for _, r := range resources {
+				err = r.EnsureDeleted(ctx, app)
+				if err != nil {
+					t.Fatalf("error == %#v, want nil", err)
+				}
+			}
+
+			if !deleted {
+				t.Fatalf("chart was not deleted")
+			}
+			if cordoned != tc.expectedCordoned {
+				t.Fatalf("cordoned == %t, want %t", cordoned, tc.expectedCordoned)
+			}
+		})
+	}
+}
+
+func isCordoned(chart *v1alpha1.Chart) bool {
+	return chart.Annotations[k8smetadataannotation.ChartOperatorCordonReason] != "" && chart.Annotations[k8smetadataannotation.ChartOperatorCordonUntil] != ""
+}
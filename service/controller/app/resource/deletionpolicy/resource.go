@@ -0,0 +1,62 @@
+package deletionpolicy
+
+import (
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+)
+
+const (
+	Name = "deletionpolicy"
+
+	// DeletePolicy deletes the Helm release with the app CR. It is the
+	// default.
+	DeletePolicy = "delete"
+	// OrphanPolicy keeps the Helm release when the app CR is deleted.
+	OrphanPolicy = "orphan"
+)
+
+type Config struct {
+	Logger micrologger.Logger
+
+	ChartNamespace string
+}
+
+// Resource orphans the chart CR of apps with the orphan deletion policy
+// before the chart CR is deleted so chart-operator keeps the Helm release.
+type Resource struct {
+	logger micrologger.Logger
+
+	chartNamespace string
+}
+
+// New creates a new configured deletionpolicy resource.
+func New(config Config) (*Resource, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.ChartNamespace == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ChartNamespace must not be empty", config)
+	}
+
+	r := &Resource{
+		logger: config.Logger,
+
+		chartNamespace: config.ChartNamespace,
+	}
+
+	return r, nil
+}
+
+func (r Resource) Name() string {
+	return Name
+}
+
+// IsOrphan returns true if the Helm release of the app is kept when the app
+// CR is deleted.
+func IsOrphan(cr v1alpha1.App) bool {
+	return cr.GetAnnotations()[annotation.AppDeletionPolicy] == OrphanPolicy
+}
@@ -0,0 +1,9 @@
+package dependencyorder
+
+import "context"
+
+// EnsureCreated is a no-op. The chart resource holds back chart CRs until
+// their dependencies are deployed.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	return nil
+}
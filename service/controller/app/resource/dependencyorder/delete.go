@@ -0,0 +1,117 @@
+package dependencyorder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/app/v5/pkg/key"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/v5/pkg/controller/context/finalizerskeptcontext"
+	"github.com/giantswarm/operatorkit/v5/pkg/controller/context/reconciliationcanceledcontext"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/dependency"
+)
+
+// EnsureDeleted keeps the finalizer of the app CR while other app CRs that
+// depend on it are being deleted so their charts are deleted first. Apps
+// depending on it that are not being deleted do not block the deletion, a
+// warning event names them instead.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	cr, err := key.ToApp(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if cc.Status.ClusterStatus.IsDeleting {
+		r.logger.Debugf(ctx, "namespace %#q is being deleted, no need to wait for dependent apps", cr.Namespace)
+		return nil
+	}
+
+	apps, err := r.g8sClient.ApplicationV1alpha1().Apps(cr.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	dependents := dependency.Dependents(cr, apps.Items)
+	if len(dependents) == 0 {
+		return nil
+	}
+
+	if dependency.FindCycle(cr, apps.Items) != nil {
+		// Apps in a cycle would wait for each other forever.
+		r.logger.Debugf(ctx, "app %#q is part of a dependency cycle, not waiting for dependent apps", cr.Name)
+		return nil
+	}
+
+	deleting := dependency.Dependents(cr, deletingApps(apps.Items))
+
+	if len(deleting) < len(dependents) {
+		notDeleting := difference(dependents, deleting)
+
+		r.logger.Debugf(ctx, "app %#q is still required by apps %s which are not being deleted", cr.Name, strings.Join(quote(notDeleting), ", "))
+		r.event.EmitWarning(ctx, &cr, dependentsNotDeletedReason, "app is deleted while apps %s still depend on it", strings.Join(quote(notDeleting), ", "))
+	}
+
+	if len(deleting) == 0 {
+		return nil
+	}
+
+	r.logger.Debugf(ctx, "app %#q is still required by apps %s", cr.Name, strings.Join(quote(deleting), ", "))
+	r.event.Emit(ctx, &cr, waitingForDependentsReason, "waiting for deletion of dependent apps %s", strings.Join(quote(deleting), ", "))
+
+	r.logger.Debugf(ctx, "keeping finalizers")
+	finalizerskeptcontext.SetKept(ctx)
+
+	r.logger.Debugf(ctx, "canceling reconciliation")
+	reconciliationcanceledcontext.SetCanceled(ctx)
+
+	return nil
+}
+
+// deletingApps returns the app CRs that are being deleted.
+func deletingApps(apps []v1alpha1.App) []v1alpha1.App {
+	var deleting []v1alpha1.App
+	for _, app := range apps {
+		if key.IsDeleted(app) {
+			deleting = append(deleting, app)
+		}
+	}
+
+	return deleting
+}
+
+// difference returns the names in a that are not in b.
+func difference(a, b []string) []string {
+	var diff []string
+	for _, name := range a {
+		found := false
+		for _, other := range b {
+			if name == other {
+				found = true
+				break
+			}
+		}
+		if !found {
+			diff = append(diff, name)
+		}
+	}
+
+	return diff
+}
+
+func quote(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = fmt.Sprintf("%#q", n)
+	}
+
+	return quoted
+}
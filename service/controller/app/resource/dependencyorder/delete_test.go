@@ -0,0 +1,124 @@
+package dependencyorder
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/v5/pkg/controller/context/finalizerskeptcontext"
+	"github.com/giantswarm/operatorkit/v5/pkg/controller/context/reconciliationcanceledcontext"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	pkgruntime "k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+)
+
+type fakeRecorder struct {
+	reasons []string
+}
+
+func (f *fakeRecorder) Emit(ctx context.Context, obj pkgruntime.Object, reason, message string, args ...interface{}) {
+	f.reasons = append(f.reasons, reason)
+}
+
+func (f *fakeRecorder) EmitWarning(ctx context.Context, obj pkgruntime.Object, reason, message string, args ...interface{}) {
+	f.reasons = append(f.reasons, reason)
+}
+
+func Test_Resource_EnsureDeleted(t *testing.T) {
+	now := metav1.Now()
+
+	newApp := func(name string, deleted bool, dependsOn string) *v1alpha1.App {
+		app := &v1alpha1.App{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+		}
+		if deleted {
+			app.DeletionTimestamp = &now
+		}
+		if dependsOn != "" {
+			app.Annotations = map[string]string{
+				"app-operator.giantswarm.io/depends-on": dependsOn,
+			}
+		}
+
+		return app
+	}
+
+	tests := []struct {
+		name            string
+		apps            []pkgruntime.Object
+		expectedKept    bool
+		expectedReasons []string
+	}{
+		{
+			name: "case 0: no dependents",
+			apps: []pkgruntime.Object{
+				newApp("cert-manager", true, ""),
+			},
+		},
+		{
+			name: "case 1: dependent is being deleted",
+			apps: []pkgruntime.Object{
+				newApp("cert-manager", true, ""),
+				newApp("ingress", true, "cert-manager"),
+			},
+			expectedKept:    true,
+			expectedReasons: []string{waitingForDependentsReason},
+		},
+		{
+			name: "case 2: dependent is not being deleted",
+			apps: []pkgruntime.Object{
+				newApp("cert-manager", true, ""),
+				newApp("ingress", false, "cert-manager"),
+			},
+			expectedReasons: []string{dependentsNotDeletedReason},
+		},
+		{
+			name: "case 3: one dependent is being deleted",
+			apps: []pkgruntime.Object{
+				newApp("cert-manager", true, ""),
+				newApp("ingress", true, "cert-manager"),
+				newApp("kong", false, "cert-manager"),
+			},
+			expectedKept:    true,
+			expectedReasons: []string{dependentsNotDeletedReason, waitingForDependentsReason},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			event := &fakeRecorder{}
+
+			r, err := New(Config{
+				Event:     event,
+				G8sClient: fake.NewSimpleClientset(tc.apps...),
+				Logger:    microloggertest.New(),
+			})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{})
+			ctx = finalizerskeptcontext.NewContext(ctx, make(chan struct{}))
+			ctx = reconciliationcanceledcontext.NewContext(ctx, make(chan struct{}))
+
+			err = r.EnsureDeleted(ctx, tc.apps[0])
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			if finalizerskeptcontext.IsKept(ctx) != tc.expectedKept {
+				t.Fatalf("kept == %t, want %t", finalizerskeptcontext.IsKept(ctx), tc.expectedKept)
+			}
+			if !reflect.DeepEqual(event.reasons, tc.expectedReasons) {
+				t.Fatalf("reasons == %#v, want %#v", event.reasons, tc.expectedReasons)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package dependencyorder
+
+import (
+	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/app-operator/v5/service/internal/recorder"
+)
+
+const (
+	Name = "dependencyorder"
+
+	dependentsNotDeletedReason = "DependentsNotDeleted"
+	waitingForDependentsReason = "WaitingForDependents"
+)
+
+type Config struct {
+	Event     recorder.Interface
+	G8sClient versioned.Interface
+	Logger    micrologger.Logger
+}
+
+// Resource deletes apps in reverse dependency order. An app is only deleted
+// once all app CRs depending on it that are being deleted are gone.
+type Resource struct {
+	event     recorder.Interface
+	g8sClient versioned.Interface
+	logger    micrologger.Logger
+}
+
+// New creates a new configured dependencyorder resource.
+func New(config Config) (*Resource, error) {
+	if config.Event == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Event must not be empty", config)
+	}
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	r := &Resource{
+		event:     config.Event,
+		g8sClient: config.G8sClient,
+		logger:    config.Logger,
+	}
+
+	return r, nil
+}
+
+func (r Resource) Name() string {
+	return Name
+}
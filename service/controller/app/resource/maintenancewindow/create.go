@@ -0,0 +1,92 @@
+package maintenancewindow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/app/v5/pkg/key"
+	"github.com/giantswarm/errors/tenant"
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/maintenance"
+)
+
+// EnsureCreated sets the state of the maintenance window of the app in the
+// controller context.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	cr, err := key.ToApp(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	window, err := r.maintenance.Get(ctx, cr)
+	if maintenance.IsInvalidWindow(err) {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("maintenance window of app %#q is invalid", cr.Name), "stack", fmt.Sprintf("%#v", err))
+		cc.Maintenance = controllercontext.Maintenance{
+			Closed: true,
+			Reason: fmt.Sprintf("invalid maintenance window: %s", err.Error()),
+		}
+
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if window == nil {
+		return nil
+	}
+
+	now := time.Now()
+
+	if window.IsOpen(now) {
+		r.logger.Debugf(ctx, "maintenance window of app %#q is open", cr.Name)
+		return nil
+	}
+
+	exempt := false
+	if window.ExemptConfigChanges {
+		exempt, err = r.keepsChartVersion(ctx, cc, cr)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	r.logger.Debugf(ctx, "maintenance window of app %#q is closed", cr.Name)
+	cc.Maintenance = controllercontext.Maintenance{
+		Closed:              true,
+		ConfigChangesExempt: exempt,
+		Reason:              fmt.Sprintf("waiting for maintenance window opening at %s", window.NextOpen(now).Format(time.RFC3339)),
+	}
+
+	return nil
+}
+
+// keepsChartVersion returns true if the chart CR already has the chart
+// version of the app, so changes of the values are config changes. During a
+// held back version change the values of the new version are held back too.
+func (r *Resource) keepsChartVersion(ctx context.Context, cc *controllercontext.Context, cr v1alpha1.App) (bool, error) {
+	if cc.Status.ClusterStatus.IsUnavailable || cc.Clients.K8s == nil {
+		return false, nil
+	}
+
+	chart, err := cc.Clients.K8s.G8sClient().ApplicationV1alpha1().Charts(r.chartNamespace).Get(ctx, cr.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// The app is not installed yet so there is no version to keep.
+		return true, nil
+	} else if tenant.IsAPINotAvailable(err) {
+		return false, nil
+	} else if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return chart.Spec.Version == cc.ChartVersion(cr), nil
+}
@@ -0,0 +1,133 @@
+package maintenancewindow
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/k8sclient/v5/pkg/k8sclienttest"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgofake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+)
+
+func Test_Resource_EnsureCreated(t *testing.T) {
+	// The window opens for one minute each year so it is closed.
+	closedWindow := `{"schedule": "0 0 1 1 *", "duration": "1m", "exemptConfigChanges": true}`
+
+	tests := []struct {
+		name                string
+		window              string
+		charts              []runtime.Object
+		expectedMaintenance controllercontext.Maintenance
+	}{
+		{
+			name: "case 0: no maintenance window",
+		},
+		{
+			name:   "case 1: closed window keeping the chart version exempts config changes",
+			window: closedWindow,
+			charts: []runtime.Object{
+				newChart("1.0.0"),
+			},
+			expectedMaintenance: controllercontext.Maintenance{
+				Closed:              true,
+				ConfigChangesExempt: true,
+			},
+		},
+		{
+			name:   "case 2: closed window during a version change holds config changes",
+			window: closedWindow,
+			charts: []runtime.Object{
+				newChart("0.9.0"),
+			},
+			expectedMaintenance: controllercontext.Maintenance{
+				Closed: true,
+			},
+		},
+		{
+			name:   "case 3: invalid window holds all changes",
+			window: `{"schedule": "never", "duration": "1h"}`,
+			expectedMaintenance: controllercontext.Maintenance{
+				Closed: true,
+			},
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			c := Config{
+				K8sClient: clientgofake.NewSimpleClientset(),
+				Logger:    microloggertest.New(),
+
+				ChartNamespace: "giantswarm",
+			}
+			r, err := New(c)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			clients := k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+				G8sClient: fake.NewSimpleClientset(tc.charts...),
+			})
+			ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+				Clients: controllercontext.Clients{
+					K8s: clients,
+				},
+			})
+
+			obj := &v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{},
+					Name:        "prometheus",
+					Namespace:   "default",
+				},
+				Spec: v1alpha1.AppSpec{
+					Version: "1.0.0",
+				},
+			}
+			if tc.window != "" {
+				obj.Annotations[annotation.AppMaintenanceWindow] = tc.window
+			}
+
+			err = r.EnsureCreated(ctx, obj)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			cc, err := controllercontext.FromContext(ctx)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			if cc.Maintenance.Closed != tc.expectedMaintenance.Closed {
+				t.Fatalf("closed == %t, want %t", cc.Maintenance.Closed, tc.expectedMaintenance.Closed)
+			}
+			if cc.Maintenance.ConfigChangesExempt != tc.expectedMaintenance.ConfigChangesExempt {
+				t.Fatalf("config changes exempt == %t, want %t", cc.Maintenance.ConfigChangesExempt, tc.expectedMaintenance.ConfigChangesExempt)
+			}
+			if cc.Maintenance.Closed && cc.Maintenance.Reason == "" {
+				t.Fatalf("reason == %#q, want non-empty", cc.Maintenance.Reason)
+			}
+		})
+	}
+}
+
+func newChart(version string) *v1alpha1.Chart {
+	return &v1alpha1.Chart{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "prometheus",
+			Namespace: "giantswarm",
+		},
+		Spec: v1alpha1.ChartSpec{
+			Version: version,
+		},
+	}
+}
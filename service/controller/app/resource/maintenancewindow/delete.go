@@ -0,0 +1,10 @@
+package maintenancewindow
+
+import (
+	"context"
+)
+
+// EnsureDeleted is a no-op because deletions are not held back.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	return nil
+}
@@ -0,0 +1,78 @@
+package maintenancewindow
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/app-operator/v5/service/internal/maintenance"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "maintenancewindow"
+)
+
+// Config represents the configuration used to create a new maintenancewindow
+// resource.
+type Config struct {
+	// Dependencies.
+	K8sClient kubernetes.Interface
+	Logger    micrologger.Logger
+
+	// Settings.
+	ChartNamespace string
+}
+
+// Resource sets the state of the maintenance window of the app in the
+// controller context so the configmap, secret and chart resources hold back
+// their changes while it is closed.
+type Resource struct {
+	// Dependencies.
+	logger      micrologger.Logger
+	maintenance *maintenance.Resource
+
+	// Settings.
+	chartNamespace string
+}
+
+// New creates a new configured maintenancewindow resource.
+func New(config Config) (*Resource, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.ChartNamespace == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ChartNamespace must not be empty", config)
+	}
+
+	var err error
+
+	var m *maintenance.Resource
+	{
+		c := maintenance.Config{
+			K8sClient: config.K8sClient,
+		}
+
+		m, err = maintenance.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	r := &Resource{
+		logger:      config.Logger,
+		maintenance: m,
+
+		chartNamespace: config.ChartNamespace,
+	}
+
+	return r, nil
+}
+
+func (*Resource) Name() string {
+	return Name
+}
@@ -46,7 +46,7 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		return nil
 	}
 
-	if key.Version(cr) != cr.Status.Version {
+	if cc.ChartVersion(cr) != cr.Status.Version {
 		r.logger.Debugf(ctx, "app %#q is not reconciled to the latest desired status yet", key.AppName(cr))
 		r.logger.Debugf(ctx, "canceling resource")
 		return nil
@@ -0,0 +1,232 @@
+package rollback
+
+import (
+	"context"
+	"time"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/app/v5/pkg/key"
+	"github.com/giantswarm/errors/tenant"
+	"github.com/giantswarm/helmclient/v4/pkg/helmclient"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/dryrun"
+	"github.com/giantswarm/app-operator/v5/service/internal/rollback"
+)
+
+// EnsureCreated records the last release of the chart CR that reached
+// deployed and reverts the chart CR to it when a newer release fails or is not
+// deployed within the rollback timeout.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	cr, err := key.ToApp(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if !rollback.IsEnabled(cr) {
+		return nil
+	}
+	if dryrun.IsEnabled(cr) {
+		r.logger.Debugf(ctx, "dry run enabled, not rolling back chart %#q", cr.Name)
+		return nil
+	}
+
+	if cc.Status.ClusterStatus.IsDeleting || cc.Status.ClusterStatus.IsUnavailable {
+		r.logger.Debugf(ctx, "workload cluster is deleting or unavailable")
+		r.logger.Debugf(ctx, "canceling resource")
+		return nil
+	}
+
+	state, err := rollback.GetState(cr)
+	if err != nil {
+		// The state is written by app-operator only. Starting over is safer
+		// than blocking the app.
+		r.logger.Debugf(ctx, "failed to parse rollback state of app %#q, resetting it: %s", cr.Name, err.Error())
+		state = rollback.State{}
+	}
+
+	chart, err := cc.Clients.K8s.G8sClient().ApplicationV1alpha1().Charts(r.chartNamespace).Get(ctx, cr.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		r.logger.Debugf(ctx, "did not find chart %#q in namespace %#q", cr.Name, r.chartNamespace)
+		r.logger.Debugf(ctx, "canceling resource")
+		return nil
+	} else if tenant.IsAPINotAvailable(err) {
+		r.logger.Debugf(ctx, "workload cluster is not available")
+		r.logger.Debugf(ctx, "canceling resource")
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	current := rollback.NewRelease(*chart)
+	chartStatus := key.ChartStatus(*chart)
+	isLastDeployed := state.LastDeployed != nil && current.Equal(*state.LastDeployed)
+	// Without any state the deployed release can not be told apart from
+	// values changed in this reconciliation. It is trusted so there is a
+	// release to roll back to.
+	isFirstSeen := state.LastDeployed == nil && state.Pending == nil
+
+	if !isLastDeployed && (state.Pending == nil || !current.Equal(*state.Pending)) {
+		now := metav1.Now()
+		state.Pending = &current
+		state.PendingSince = &now
+	}
+
+	switch {
+	case isLastDeployed:
+		// The chart CR is already at the last deployed release, e.g. after
+		// a rollback.
+		state.Pending = nil
+		state.PendingSince = nil
+
+	case chartStatus.Release.Status == helmclient.StatusDeployed && chartStatus.Version == current.Version && (isFirstSeen || deployedSince(chartStatus, *state.PendingSince)):
+		r.logger.Debugf(ctx, "recording version %#q of chart %#q as last deployed release", current.Version, chart.Name)
+
+		release, err := r.snapshot(ctx, cc.Clients.K8s.K8sClient(), current)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		state.LastDeployed = &release
+		state.Pending = nil
+		state.PendingSince = nil
+		// A new release was deployed so the rollback is over.
+		state.RolledBack = nil
+
+	case state.LastDeployed == nil:
+		// There is nothing to roll back to.
+		r.logger.Debugf(ctx, "version %#q of chart %#q is not deployed yet", current.Version, chart.Name)
+
+	default:
+		timeout, err := rollback.Timeout(cr)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		failed := chartStatus.Release.Status == helmclient.StatusFailed && chartStatus.Version == current.Version
+		timedOut := time.Since(state.PendingSince.Time) > timeout
+
+		if !failed && !timedOut {
+			r.logger.Debugf(ctx, "waiting for version %#q of chart %#q to be deployed", current.Version, chart.Name)
+			break
+		}
+
+		r.logger.Debugf(ctx, "rolling back chart %#q from version %#q to version %#q", chart.Name, current.Version, state.LastDeployed.Version)
+
+		state.LastDeployed.Apply(chart)
+
+		_, err = cc.Clients.K8s.G8sClient().ApplicationV1alpha1().Charts(r.chartNamespace).Update(ctx, chart, metav1.UpdateOptions{})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		state.Pending = nil
+		state.PendingSince = nil
+		state.RolledBack = &current
+
+		reason := "release failed"
+		if !failed {
+			reason = "release was not deployed within " + timeout.String()
+		}
+		r.event.EmitWarning(ctx, &cr, rolledBackReason, "%s: %s", state.Message(), reason)
+
+		r.logger.Debugf(ctx, "rolled back chart %#q from version %#q to version %#q", chart.Name, current.Version, state.LastDeployed.Version)
+	}
+
+	cc.Status.Rollback = state.Message()
+
+	err = r.patchState(ctx, cr, state)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// deployedSince returns true if chart-operator deployed the chart after the
+// given time. The chart status keeps reporting the previous release as
+// deployed until chart-operator upgraded it, e.g. when only values changed.
+func deployedSince(chartStatus v1alpha1.ChartStatus, since metav1.Time) bool {
+	lastDeployed := chartStatus.Release.LastDeployed
+	return lastDeployed != nil && lastDeployed.After(since.Time)
+}
+
+// snapshot copies the values of the release so they are still available when
+// the app values change. The returned release references the copies and keeps
+// the original references as source.
+func (r *Resource) snapshot(ctx context.Context, k8sClient kubernetes.Interface, release rollback.Release) (rollback.Release, error) {
+	release.Source = release.Config
+
+	if release.Config.ConfigMap.Name != "" {
+		ref := release.Config.ConfigMap
+
+		current, err := k8sClient.CoreV1().ConfigMaps(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return rollback.Release{}, microerror.Mask(err)
+		}
+
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      snapshotName(ref.Name),
+				Namespace: ref.Namespace,
+			},
+			Data: current.Data,
+		}
+
+		copied, err := k8sClient.CoreV1().ConfigMaps(ref.Namespace).Update(ctx, cm, metav1.UpdateOptions{})
+		if apierrors.IsNotFound(err) {
+			copied, err = k8sClient.CoreV1().ConfigMaps(ref.Namespace).Create(ctx, cm, metav1.CreateOptions{})
+		}
+		if err != nil {
+			return rollback.Release{}, microerror.Mask(err)
+		}
+
+		release.Config.ConfigMap = v1alpha1.ChartSpecConfigConfigMap{
+			Name:            copied.Name,
+			Namespace:       copied.Namespace,
+			ResourceVersion: copied.ResourceVersion,
+		}
+	}
+
+	if release.Config.Secret.Name != "" {
+		ref := release.Config.Secret
+
+		current, err := k8sClient.CoreV1().Secrets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return rollback.Release{}, microerror.Mask(err)
+		}
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      snapshotName(ref.Name),
+				Namespace: ref.Namespace,
+			},
+			Data: current.Data,
+		}
+
+		copied, err := k8sClient.CoreV1().Secrets(ref.Namespace).Update(ctx, secret, metav1.UpdateOptions{})
+		if apierrors.IsNotFound(err) {
+			copied, err = k8sClient.CoreV1().Secrets(ref.Namespace).Create(ctx, secret, metav1.CreateOptions{})
+		}
+		if err != nil {
+			return rollback.Release{}, microerror.Mask(err)
+		}
+
+		release.Config.Secret = v1alpha1.ChartSpecConfigSecret{
+			Name:            copied.Name,
+			Namespace:       copied.Namespace,
+			ResourceVersion: copied.ResourceVersion,
+		}
+	}
+
+	return release, nil
+}
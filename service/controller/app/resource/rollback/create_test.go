@@ -0,0 +1,433 @@
+package rollback
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/k8sclient/v5/pkg/k8sclienttest"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	pkgruntime "k8s.io/apimachinery/pkg/runtime"
+	clientgofake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/rollback"
+)
+
+type fakeRecorder struct {
+	reasons []string
+}
+
+func (f *fakeRecorder) Emit(ctx context.Context, obj pkgruntime.Object, reason, message string, args ...interface{}) {
+	f.reasons = append(f.reasons, reason)
+}
+
+func (f *fakeRecorder) EmitWarning(ctx context.Context, obj pkgruntime.Object, reason, message string, args ...interface{}) {
+	f.reasons = append(f.reasons, reason)
+}
+
+func Test_Resource_EnsureCreated(t *testing.T) {
+	lastDeployed := &rollback.Release{
+		Config: v1alpha1.ChartSpecConfig{
+			ConfigMap: v1alpha1.ChartSpecConfigConfigMap{
+				Name:            "my-prometheus-chart-values-rollback",
+				Namespace:       "giantswarm",
+				ResourceVersion: "1",
+			},
+		},
+		TarballURL: "https://giantswarm.github.io/app-catalog/prometheus-1.0.0.tgz",
+		Version:    "1.0.0",
+	}
+	pending := &rollback.Release{
+		Config: v1alpha1.ChartSpecConfig{
+			ConfigMap: v1alpha1.ChartSpecConfigConfigMap{
+				Name:            "my-prometheus-chart-values",
+				Namespace:       "giantswarm",
+				ResourceVersion: "2",
+			},
+		},
+		TarballURL: "https://giantswarm.github.io/app-catalog/prometheus-2.0.0.tgz",
+		Version:    "2.0.0",
+	}
+
+	tests := []struct {
+		name                 string
+		chartStatus          string
+		chartVersion         string
+		chartLastDeployed    time.Time
+		state                rollback.State
+		expectedVersion      string
+		expectedLastDeployed string
+		expectedRolledBack   bool
+		expectedPending      bool
+	}{
+		{
+			name:                 "case 0: deployed release is recorded",
+			chartStatus:          "deployed",
+			chartVersion:         "2.0.0",
+			expectedVersion:      "2.0.0",
+			expectedLastDeployed: "2.0.0",
+		},
+		{
+			name:         "case 1: failed release is rolled back",
+			chartStatus:  "failed",
+			chartVersion: "2.0.0",
+			state: rollback.State{
+				LastDeployed: lastDeployed,
+				Pending:      pending,
+				PendingSince: &metav1.Time{Time: time.Now().Add(-time.Minute)},
+			},
+			expectedVersion:      "1.0.0",
+			expectedLastDeployed: "1.0.0",
+			expectedRolledBack:   true,
+		},
+		{
+			name:         "case 2: pending release within timeout is kept",
+			chartStatus:  "deployed",
+			chartVersion: "1.0.0",
+			state: rollback.State{
+				LastDeployed: lastDeployed,
+				Pending:      pending,
+				PendingSince: &metav1.Time{Time: time.Now().Add(-time.Minute)},
+			},
+			expectedVersion:      "2.0.0",
+			expectedLastDeployed: "1.0.0",
+			expectedPending:      true,
+		},
+		{
+			name:         "case 3: pending release after timeout is rolled back",
+			chartStatus:  "pending-upgrade",
+			chartVersion: "1.0.0",
+			state: rollback.State{
+				LastDeployed: lastDeployed,
+				Pending:      pending,
+				PendingSince: &metav1.Time{Time: time.Now().Add(-time.Hour)},
+			},
+			expectedVersion:      "1.0.0",
+			expectedLastDeployed: "1.0.0",
+			expectedRolledBack:   true,
+		},
+		{
+			name:              "case 4: pending release deployed after it was seen is recorded",
+			chartStatus:       "deployed",
+			chartVersion:      "2.0.0",
+			chartLastDeployed: time.Now(),
+			state: rollback.State{
+				LastDeployed: lastDeployed,
+				Pending:      pending,
+				PendingSince: &metav1.Time{Time: time.Now().Add(-time.Minute)},
+			},
+			expectedVersion:      "2.0.0",
+			expectedLastDeployed: "2.0.0",
+		},
+		{
+			name:              "case 5: release deployed before it was seen is not recorded",
+			chartStatus:       "deployed",
+			chartVersion:      "2.0.0",
+			chartLastDeployed: time.Now().Add(-time.Hour),
+			state: rollback.State{
+				LastDeployed: lastDeployed,
+			},
+			expectedVersion:      "2.0.0",
+			expectedLastDeployed: "1.0.0",
+			expectedPending:      true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			state, err := tc.state.Marshal()
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			app := &v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"app-operator.giantswarm.io/rollback":      "true",
+						"application.giantswarm.io/rollback-state": state,
+					},
+					Name:      "my-prometheus",
+					Namespace: "default",
+				},
+			}
+			var chartLastDeployed *metav1.Time
+			if !tc.chartLastDeployed.IsZero() {
+				chartLastDeployed = &metav1.Time{Time: tc.chartLastDeployed}
+			}
+			chart := &v1alpha1.Chart{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-prometheus",
+					Namespace: "giantswarm",
+				},
+				Spec: v1alpha1.ChartSpec{
+					Config: v1alpha1.ChartSpecConfig{
+						ConfigMap: v1alpha1.ChartSpecConfigConfigMap{
+							Name:            "my-prometheus-chart-values",
+							Namespace:       "giantswarm",
+							ResourceVersion: "2",
+						},
+					},
+					TarballURL: "https://giantswarm.github.io/app-catalog/prometheus-2.0.0.tgz",
+					Version:    "2.0.0",
+				},
+				Status: v1alpha1.ChartStatus{
+					Release: v1alpha1.ChartStatusRelease{
+						LastDeployed: chartLastDeployed,
+						Status:       tc.chartStatus,
+					},
+					Version: tc.chartVersion,
+				},
+			}
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-prometheus-chart-values",
+					Namespace: "giantswarm",
+				},
+				Data: map[string]string{
+					"values": "replicas: 2",
+				},
+			}
+
+			g8sClient := fake.NewSimpleClientset(app)
+			wcG8sClient := fake.NewSimpleClientset(chart)
+			wcK8sClient := clientgofake.NewSimpleClientset(configMap)
+
+			event := &fakeRecorder{}
+
+			r, err := New(Config{
+				Event:     event,
+				G8sClient: g8sClient,
+				Logger:    microloggertest.New(),
+
+				ChartNamespace: "giantswarm",
+			})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			c := controllercontext.Context{
+				Clients: controllercontext.Clients{
+					K8s: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+						G8sClient: wcG8sClient,
+						K8sClient: wcK8sClient,
+					}),
+				},
+			}
+			ctx := controllercontext.NewContext(context.Background(), c)
+
+			err = r.EnsureCreated(ctx, app)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			updatedChart, err := wcG8sClient.ApplicationV1alpha1().Charts("giantswarm").Get(ctx, "my-prometheus", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			if updatedChart.Spec.Version != tc.expectedVersion {
+				t.Fatalf("chart version == %#q, want %#q", updatedChart.Spec.Version, tc.expectedVersion)
+			}
+
+			updatedApp, err := g8sClient.ApplicationV1alpha1().Apps("default").Get(ctx, "my-prometheus", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			updatedState, err := rollback.GetState(*updatedApp)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			if updatedState.LastDeployed == nil {
+				t.Fatalf("last deployed == nil, want non-nil")
+			}
+			if updatedState.LastDeployed.Version != tc.expectedLastDeployed {
+				t.Fatalf("last deployed version == %#q, want %#q", updatedState.LastDeployed.Version, tc.expectedLastDeployed)
+			}
+			if (updatedState.RolledBack != nil) != tc.expectedRolledBack {
+				t.Fatalf("rolled back == %#v, want %t", updatedState.RolledBack, tc.expectedRolledBack)
+			}
+			if (updatedState.PendingSince != nil) != tc.expectedPending {
+				t.Fatalf("pending since == %#v, want %t", updatedState.PendingSince, tc.expectedPending)
+			}
+			if (len(event.reasons) > 0) != tc.expectedRolledBack {
+				t.Fatalf("events == %#v, want rolled back %t", event.reasons, tc.expectedRolledBack)
+			}
+
+			if tc.state.LastDeployed == nil {
+				snapshot, err := wcK8sClient.CoreV1().ConfigMaps("giantswarm").Get(ctx, "my-prometheus-chart-values-rollback", metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("error == %#v, want nil", err)
+				}
+				if snapshot.Data["values"] != "replicas: 2" {
+					t.Fatalf("snapshot values == %#q, want %#q", snapshot.Data["values"], "replicas: 2")
+				}
+				if updatedState.LastDeployed.Config.ConfigMap.Name != snapshot.Name {
+					t.Fatalf("last deployed config map == %#q, want %#q", updatedState.LastDeployed.Config.ConfigMap.Name, snapshot.Name)
+				}
+			}
+		})
+	}
+}
+
+func Test_Resource_EnsureCreated_valuesOnly(t *testing.T) {
+	ctx := context.Background()
+
+	lastDeployed := rollback.Release{
+		Config: v1alpha1.ChartSpecConfig{
+			ConfigMap: v1alpha1.ChartSpecConfigConfigMap{
+				Name:            "my-prometheus-chart-values-rollback",
+				Namespace:       "giantswarm",
+				ResourceVersion: "1",
+			},
+		},
+		Source: v1alpha1.ChartSpecConfig{
+			ConfigMap: v1alpha1.ChartSpecConfigConfigMap{
+				Name:            "my-prometheus-chart-values",
+				Namespace:       "giantswarm",
+				ResourceVersion: "1",
+			},
+		},
+		TarballURL: "https://giantswarm.github.io/app-catalog/prometheus-1.0.0.tgz",
+		Version:    "1.0.0",
+	}
+	state, err := rollback.State{LastDeployed: &lastDeployed}.Marshal()
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	app := &v1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				"app-operator.giantswarm.io/rollback":      "true",
+				"application.giantswarm.io/rollback-state": state,
+			},
+			Name:      "my-prometheus",
+			Namespace: "default",
+		},
+	}
+	// The configmap resource already wrote the new values and the chart
+	// resource references them while the chart status still reports the
+	// release with the previous values.
+	chart := &v1alpha1.Chart{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-prometheus",
+			Namespace: "giantswarm",
+		},
+		Spec: v1alpha1.ChartSpec{
+			Config: v1alpha1.ChartSpecConfig{
+				ConfigMap: v1alpha1.ChartSpecConfigConfigMap{
+					Name:            "my-prometheus-chart-values",
+					Namespace:       "giantswarm",
+					ResourceVersion: "2",
+				},
+			},
+			TarballURL: "https://giantswarm.github.io/app-catalog/prometheus-1.0.0.tgz",
+			Version:    "1.0.0",
+		},
+		Status: v1alpha1.ChartStatus{
+			Release: v1alpha1.ChartStatusRelease{
+				LastDeployed: &metav1.Time{Time: time.Now().Add(-time.Hour)},
+				Status:       "deployed",
+			},
+			Version: "1.0.0",
+		},
+	}
+	values := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-prometheus-chart-values",
+			Namespace: "giantswarm",
+		},
+		Data: map[string]string{
+			"values": "replicas: -1",
+		},
+	}
+	snapshot := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-prometheus-chart-values-rollback",
+			Namespace: "giantswarm",
+		},
+		Data: map[string]string{
+			"values": "replicas: 2",
+		},
+	}
+
+	g8sClient := fake.NewSimpleClientset(app)
+	wcG8sClient := fake.NewSimpleClientset(chart)
+	wcK8sClient := clientgofake.NewSimpleClientset(values, snapshot)
+
+	r, err := New(Config{
+		Event:     &fakeRecorder{},
+		G8sClient: g8sClient,
+		Logger:    microloggertest.New(),
+
+		ChartNamespace: "giantswarm",
+	})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	c := controllercontext.Context{
+		Clients: controllercontext.Clients{
+			K8s: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+				G8sClient: wcG8sClient,
+				K8sClient: wcK8sClient,
+			}),
+		},
+	}
+	ctx = controllercontext.NewContext(ctx, c)
+
+	// The new values are not recorded while chart-operator did not deploy
+	// them yet.
+	err = r.EnsureCreated(ctx, app)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	app, err = g8sClient.ApplicationV1alpha1().Apps("default").Get(ctx, "my-prometheus", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	updatedState, err := rollback.GetState(*app)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if !updatedState.LastDeployed.Equal(lastDeployed) {
+		t.Fatalf("last deployed == %#v, want %#v", updatedState.LastDeployed, lastDeployed)
+	}
+	if updatedState.Pending == nil {
+		t.Fatalf("pending == nil, want non-nil")
+	}
+
+	// The upgrade with the new values fails.
+	chart.Status.Release.Status = "failed"
+	_, err = wcG8sClient.ApplicationV1alpha1().Charts("giantswarm").Update(ctx, chart, metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	err = r.EnsureCreated(ctx, app)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	updatedChart, err := wcG8sClient.ApplicationV1alpha1().Charts("giantswarm").Get(ctx, "my-prometheus", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if updatedChart.Spec.Config.ConfigMap.Name != snapshot.Name {
+		t.Fatalf("chart config map == %#q, want %#q", updatedChart.Spec.Config.ConfigMap.Name, snapshot.Name)
+	}
+
+	updatedSnapshot, err := wcK8sClient.CoreV1().ConfigMaps("giantswarm").Get(ctx, snapshot.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if updatedSnapshot.Data["values"] != "replicas: 2" {
+		t.Fatalf("snapshot values == %#q, want %#q", updatedSnapshot.Data["values"], "replicas: 2")
+	}
+}
@@ -0,0 +1,61 @@
+package rollback
+
+import (
+	"context"
+
+	"github.com/giantswarm/app/v5/pkg/key"
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/rollback"
+)
+
+// EnsureDeleted deletes the copies of the values of the last deployed
+// release.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	cr, err := key.ToApp(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if cc.Status.ClusterStatus.IsDeleting || cc.Status.ClusterStatus.IsUnavailable {
+		r.logger.Debugf(ctx, "workload cluster is deleting or unavailable")
+		r.logger.Debugf(ctx, "canceling resource")
+		return nil
+	}
+
+	state, err := rollback.GetState(cr)
+	if err != nil || state.LastDeployed == nil {
+		return nil
+	}
+
+	k8sClient := cc.Clients.K8s.K8sClient()
+
+	if ref := state.LastDeployed.Config.ConfigMap; ref.Name != "" {
+		err = k8sClient.CoreV1().ConfigMaps(ref.Namespace).Delete(ctx, ref.Name, metav1.DeleteOptions{})
+		if apierrors.IsNotFound(err) {
+			// no-op
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	if ref := state.LastDeployed.Config.Secret; ref.Name != "" {
+		err = k8sClient.CoreV1().Secrets(ref.Namespace).Delete(ctx, ref.Name, metav1.DeleteOptions{})
+		if apierrors.IsNotFound(err) {
+			// no-op
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	r.logger.Debugf(ctx, "deleted rollback values of app %#q", cr.Name)
+
+	return nil
+}
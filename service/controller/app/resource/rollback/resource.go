@@ -0,0 +1,111 @@
+package rollback
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+	"github.com/giantswarm/app-operator/v5/service/internal/recorder"
+	"github.com/giantswarm/app-operator/v5/service/internal/rollback"
+)
+
+const (
+	Name = "rollback"
+
+	rolledBackReason = "RolledBack"
+)
+
+type Config struct {
+	Event     recorder.Interface
+	G8sClient versioned.Interface
+	Logger    micrologger.Logger
+
+	ChartNamespace string
+}
+
+// Resource reverts the chart CR of apps that opted in to automatic rollbacks
+// to the last release that reached deployed when an upgrade fails or is not
+// deployed within the rollback timeout.
+type Resource struct {
+	event     recorder.Interface
+	g8sClient versioned.Interface
+	logger    micrologger.Logger
+
+	chartNamespace string
+}
+
+// New creates a new configured rollback resource.
+func New(config Config) (*Resource, error) {
+	if config.Event == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Event must not be empty", config)
+	}
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.ChartNamespace == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ChartNamespace must not be empty", config)
+	}
+
+	r := &Resource{
+		event:     config.Event,
+		g8sClient: config.G8sClient,
+		logger:    config.Logger,
+
+		chartNamespace: config.ChartNamespace,
+	}
+
+	return r, nil
+}
+
+func (r Resource) Name() string {
+	return Name
+}
+
+func (r *Resource) patchState(ctx context.Context, cr v1alpha1.App, state rollback.State) error {
+	value, err := state.Marshal()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if cr.GetAnnotations()[annotation.AppRollbackState] == value {
+		return nil
+	}
+
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				annotation.AppRollbackState: value,
+			},
+		},
+	}
+
+	bytes, err := json.Marshal(patch)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	_, err = r.g8sClient.ApplicationV1alpha1().Apps(cr.Namespace).Patch(ctx, cr.Name, types.MergePatchType, bytes, metav1.PatchOptions{})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// snapshotName returns the name of the copy of the chart values that is kept
+// for rolling back.
+func snapshotName(name string) string {
+	return fmt.Sprintf("%s-rollback", name)
+}
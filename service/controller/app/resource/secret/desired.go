@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	"github.com/giantswarm/app/v5/pkg/key"
-	"github.com/giantswarm/app/v5/pkg/values"
 	"github.com/giantswarm/k8smetadata/pkg/annotation"
 	"github.com/giantswarm/k8smetadata/pkg/label"
 	"github.com/giantswarm/microerror"
@@ -14,9 +13,11 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 
+	"github.com/giantswarm/app-operator/v5/pkg/condition"
 	"github.com/giantswarm/app-operator/v5/pkg/project"
 	"github.com/giantswarm/app-operator/v5/pkg/status"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/values"
 )
 
 func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interface{}, error) {
@@ -41,10 +42,11 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 		return secret, nil
 	}
 
-	mergedData, err := r.values.MergeSecretData(ctx, cr, cc.Catalog)
+	layers, err := r.values.SecretLayers(ctx, cr, cc.Catalog)
 	if values.IsNotFound(err) {
 		r.logger.LogCtx(ctx, "level", "warning", "message", "dependent secrets are not found")
 		addStatusToContext(cc, err.Error(), status.SecretMergeFailedStatus)
+		cc.SetCondition(cr, condition.ValuesMerged, metav1.ConditionFalse, condition.Reason(status.SecretMergeFailedStatus), err.Error())
 
 		r.logger.Debugf(ctx, "canceling resource")
 		resourcecanceledcontext.SetCanceled(ctx)
@@ -52,6 +54,7 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 	} else if values.IsParsingError(err) {
 		r.logger.LogCtx(ctx, "level", "warning", "message", "failed to merging secrets")
 		addStatusToContext(cc, err.Error(), status.SecretMergeFailedStatus)
+		cc.SetCondition(cr, condition.ValuesMerged, metav1.ConditionFalse, condition.Reason(status.SecretMergeFailedStatus), err.Error())
 
 		r.logger.Debugf(ctx, "canceling resource")
 		resourcecanceledcontext.SetCanceled(ctx)
@@ -60,6 +63,26 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 		return nil, microerror.Mask(err)
 	}
 
+	mergedData, err := r.values.MergeAndRender(cr, layers)
+	if values.IsTemplateError(err) {
+		r.logger.LogCtx(ctx, "level", "warning", "message", "failed to render secret values")
+		addStatusToContext(cc, err.Error(), status.ValuesTemplateFailedStatus)
+		cc.SetCondition(cr, condition.ValuesMerged, metav1.ConditionFalse, condition.Reason(status.ValuesTemplateFailedStatus), err.Error())
+
+		r.logger.Debugf(ctx, "canceling resource")
+		resourcecanceledcontext.SetCanceled(ctx)
+		return nil, nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	// Only the keys of the secret values are kept.
+	provenance, err := values.Provenance(layers)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	r.valuesProvenance.SetSecret(cr.Namespace, cr.Name, provenance)
+
 	if mergedData == nil {
 		// Return early.
 		return nil, nil
@@ -7,7 +7,6 @@ import (
 	"testing"
 
 	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
-	"github.com/giantswarm/app/v5/pkg/values"
 	"github.com/giantswarm/k8smetadata/pkg/annotation"
 	"github.com/giantswarm/k8smetadata/pkg/label"
 	"github.com/giantswarm/micrologger/microloggertest"
@@ -18,6 +17,8 @@ import (
 	clientgofake "k8s.io/client-go/kubernetes/fake"
 
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/values"
+	"github.com/giantswarm/app-operator/v5/service/valuesprovenance"
 )
 
 func Test_Resource_GetDesiredState(t *testing.T) {
@@ -133,8 +134,9 @@ func Test_Resource_GetDesiredState(t *testing.T) {
 			}
 
 			c := Config{
-				Logger: microloggertest.New(),
-				Values: valuesService,
+				Logger:           microloggertest.New(),
+				Values:           valuesService,
+				ValuesProvenance: valuesprovenance.New(),
 
 				ChartNamespace: "giantswarm",
 			}
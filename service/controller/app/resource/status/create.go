@@ -8,9 +8,12 @@ import (
 	"github.com/giantswarm/errors/tenant"
 	"github.com/giantswarm/microerror"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/conditions"
+	"github.com/giantswarm/app-operator/v5/service/internal/dryrun"
 )
 
 func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
@@ -30,75 +33,96 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		return nil
 	}
 
-	var desiredStatus v1alpha1.AppStatus
+	err = r.publishDryRun(ctx, cr, cc.DryRunChanges)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	desiredStatus, err := r.getDesiredStatus(ctx, cc, cr)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if desiredStatus != nil && dryrun.IsEnabled(cr) {
+		desiredStatus.Release.Reason = conditions.JoinReason(dryrun.Summary(cc.DryRunChanges), desiredStatus.Release.Reason)
+	}
+
+	// The conditions are set even if the status is unknown, e.g. when the
+	// workload cluster is unavailable.
+	updated, err := conditions.Update(ctx, r.dynClient, cr, desiredStatus, cc.Conditions)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if updated {
+		r.logger.Debugf(ctx, "status set for app %#q in namespace %#q", cr.Name, cr.Namespace)
+	} else {
+		r.logger.Debugf(ctx, "status already set for app %#q in namespace %#q", cr.Name, cr.Namespace)
+	}
+
+	return nil
+}
 
+// getDesiredStatus returns the status of the app CR from the controller
+// context or the chart CR. It returns nil when the status is unknown. The
+// Deployed condition is set from the chart CR status.
+func (r *Resource) getDesiredStatus(ctx context.Context, cc *controllercontext.Context, cr v1alpha1.App) (*v1alpha1.AppStatus, error) {
 	if cc.Status.ChartStatus.Status != "" {
-		desiredStatus = v1alpha1.AppStatus{
+		desiredStatus := &v1alpha1.AppStatus{
 			Release: v1alpha1.AppStatusRelease{
 				Reason: cc.Status.ChartStatus.Reason,
 				Status: cc.Status.ChartStatus.Status,
 			},
 		}
-	} else {
-		if cc.Status.ClusterStatus.IsUnavailable {
-			r.logger.Debugf(ctx, "workload cluster is unavailable")
-			r.logger.Debugf(ctx, "canceling resource")
-			return nil
-		}
 
-		r.logger.Debugf(ctx, "finding status for chart %#q in namespace %#q", cr.Name, r.chartNamespace)
-
-		chart, err := cc.Clients.K8s.G8sClient().ApplicationV1alpha1().Charts(r.chartNamespace).Get(ctx, cr.Name, metav1.GetOptions{})
-		if apierrors.IsNotFound(err) {
-			r.logger.Debugf(ctx, "did not find chart %#q in namespace %#q", cr.Name, r.chartNamespace)
-			r.logger.Debugf(ctx, "canceling resource")
-			return nil
-		} else if tenant.IsAPINotAvailable(err) {
-			// We should not hammer tenant API if it is not available, the workload cluster
-			// might be initializing. We will retry on next reconciliation loop.
-			r.logger.Debugf(ctx, "workload cluster is not available.")
-			r.logger.Debugf(ctx, "canceling resource")
-			return nil
-		} else if err != nil {
-			return microerror.Mask(err)
-		}
+		return desiredStatus, nil
+	}
 
-		r.logger.Debugf(ctx, "found status for chart %#q in namespace %#q", cr.Name, r.chartNamespace)
+	if cc.Status.ClusterStatus.IsUnavailable {
+		r.logger.Debugf(ctx, "workload cluster is unavailable")
+		return nil, nil
+	}
 
-		chartStatus := key.ChartStatus(*chart)
-		desiredStatus = v1alpha1.AppStatus{
-			AppVersion: chartStatus.AppVersion,
-			Release: v1alpha1.AppStatusRelease{
-				Reason: chartStatus.Reason,
-				Status: chartStatus.Release.Status,
-			},
-			Version: chartStatus.Version,
-		}
-		if chartStatus.Release.LastDeployed != nil {
-			desiredStatus.Release.LastDeployed = *chartStatus.Release.LastDeployed
-		}
+	r.logger.Debugf(ctx, "finding status for chart %#q in namespace %#q", cr.Name, r.chartNamespace)
+
+	chart, err := cc.Clients.K8s.G8sClient().ApplicationV1alpha1().Charts(r.chartNamespace).Get(ctx, cr.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		r.logger.Debugf(ctx, "did not find chart %#q in namespace %#q", cr.Name, r.chartNamespace)
+		return nil, nil
+	} else if tenant.IsAPINotAvailable(err) {
+		// We should not hammer tenant API if it is not available, the workload cluster
+		// might be initializing. We will retry on next reconciliation loop.
+		r.logger.Debugf(ctx, "workload cluster is not available.")
+		return nil, nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
 	}
 
-	if !equals(desiredStatus, key.AppStatus(cr)) {
-		r.logger.Debugf(ctx, "setting status for app %#q in namespace %#q", cr.Name, cr.Namespace)
+	r.logger.Debugf(ctx, "found status for chart %#q in namespace %#q", cr.Name, r.chartNamespace)
 
-		// Get app CR again to ensure the resource version is correct.
-		currentCR, err := r.g8sClient.ApplicationV1alpha1().Apps(cr.Namespace).Get(ctx, cr.Name, metav1.GetOptions{})
-		if err != nil {
-			return microerror.Mask(err)
-		}
+	_, err = conditions.AddRelease(ctx, r.dynClient, cr, *chart)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
 
-		currentCR.Status = desiredStatus
+	chartStatus := key.ChartStatus(*chart)
+	desiredStatus := &v1alpha1.AppStatus{
+		AppVersion: chartStatus.AppVersion,
+		Release: v1alpha1.AppStatusRelease{
+			Reason: chartStatus.Reason,
+			Status: chartStatus.Release.Status,
+		},
+		Version: chartStatus.Version,
+	}
+	if chartStatus.Release.LastDeployed != nil {
+		desiredStatus.Release.LastDeployed = *chartStatus.Release.LastDeployed
+	}
 
-		_, err = r.g8sClient.ApplicationV1alpha1().Apps(cr.Namespace).UpdateStatus(ctx, currentCR, metav1.UpdateOptions{})
-		if err != nil {
-			return microerror.Mask(err)
-		}
+	meta.SetStatusCondition(&cc.Conditions, conditions.Deployed(cr, desiredStatus.Release))
 
-		r.logger.Debugf(ctx, "status set for app %#q in namespace %#q", cr.Name, cr.Namespace)
-	} else {
-		r.logger.Debugf(ctx, "status already set for app %#q in namespace %#q", cr.Name, cr.Namespace)
+	if cc.Status.Rollback != "" {
+		desiredStatus.Release.Reason = conditions.JoinReason(cc.Status.Rollback, desiredStatus.Release.Reason)
 	}
 
-	return nil
+	return desiredStatus, nil
 }
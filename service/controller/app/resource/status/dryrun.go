@@ -0,0 +1,63 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+	"github.com/giantswarm/app-operator/v5/service/internal/dryrun"
+)
+
+const (
+	dryRunReason = "DryRun"
+)
+
+// publishDryRun sets the diff of the changes that were not applied in the dry
+// run annotation of the app CR and emits it as event when it changed. The
+// annotation is removed when the app is no longer in dry run mode.
+func (r *Resource) publishDryRun(ctx context.Context, cr v1alpha1.App, changes []dryrun.Change) error {
+	current, ok := cr.GetAnnotations()[annotation.AppDryRunDiff]
+
+	var desired *string
+	if dryrun.IsEnabled(cr) {
+		diff := dryrun.Format(changes)
+		if current == diff {
+			return nil
+		}
+		desired = &diff
+	} else if !ok {
+		return nil
+	}
+
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]*string{
+				annotation.AppDryRunDiff: desired,
+			},
+		},
+	}
+
+	bytes, err := json.Marshal(patch)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	_, err = r.g8sClient.ApplicationV1alpha1().Apps(cr.Namespace).Patch(ctx, cr.Name, types.MergePatchType, bytes, metav1.PatchOptions{})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if desired != nil {
+		r.logger.Debugf(ctx, "set dry run diff of app %#q in namespace %#q", cr.Name, cr.Namespace)
+		r.event.Emit(ctx, &cr, dryRunReason, "%s", dryrun.EventMessage(changes))
+	} else {
+		r.logger.Debugf(ctx, "removed dry run diff of app %#q in namespace %#q", cr.Name, cr.Namespace)
+	}
+
+	return nil
+}
@@ -1,10 +1,12 @@
 package status
 
 import (
-	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
 	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/giantswarm/app-operator/v5/service/internal/recorder"
 )
 
 const (
@@ -13,6 +15,8 @@ const (
 
 // Config represents the configuration used to create a new chartstatus resource.
 type Config struct {
+	DynClient dynamic.Interface
+	Event     recorder.Interface
 	G8sClient versioned.Interface
 	Logger    micrologger.Logger
 
@@ -21,6 +25,8 @@ type Config struct {
 
 // Resource implements the chartstatus resource.
 type Resource struct {
+	dynClient dynamic.Interface
+	event     recorder.Interface
 	g8sClient versioned.Interface
 	logger    micrologger.Logger
 
@@ -28,6 +34,12 @@ type Resource struct {
 }
 
 func New(config Config) (*Resource, error) {
+	if config.DynClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.DynClient must not be empty", config)
+	}
+	if config.Event == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Event must not be empty", config)
+	}
 	if config.G8sClient == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
 	}
@@ -37,6 +49,8 @@ func New(config Config) (*Resource, error) {
 
 	r := &Resource{
 		// Dependencies.
+		dynClient: config.DynClient,
+		event:     config.Event,
 		g8sClient: config.G8sClient,
 		logger:    config.Logger,
 
@@ -49,25 +63,3 @@ func New(config Config) (*Resource, error) {
 func (r Resource) Name() string {
 	return Name
 }
-
-// equals asseses the equality of AppStatuses with regards to distinguishing
-// fields.
-func equals(a, b v1alpha1.AppStatus) bool {
-	if a.AppVersion != b.AppVersion {
-		return false
-	}
-	if a.Release.LastDeployed != b.Release.LastDeployed {
-		return false
-	}
-	if a.Release.Reason != b.Release.Reason {
-		return false
-	}
-	if a.Release.Status != b.Release.Status {
-		return false
-	}
-	if a.Version != b.Version {
-		return false
-	}
-
-	return true
-}
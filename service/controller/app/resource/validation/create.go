@@ -11,7 +11,10 @@ import (
 	"github.com/giantswarm/operatorkit/v5/pkg/controller/context/reconciliationcanceledcontext"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/app-operator/v5/pkg/condition"
 	"github.com/giantswarm/app-operator/v5/pkg/status"
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/conditions"
 )
 
 func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
@@ -20,11 +23,18 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		return microerror.Mask(err)
 	}
 
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
 	_, err = r.appValidator.ValidateApp(ctx, cr)
 	if validation.IsValidationError(err) {
 		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("validation error %s", err.Error()))
 
-		err = r.updateAppStatus(ctx, cr, err.Error())
+		cc.SetCondition(cr, condition.Validated, metav1.ConditionFalse, "ValidationFailed", err.Error())
+
+		err = r.updateAppStatus(ctx, cc, cr, err.Error())
 		if err != nil {
 			return microerror.Mask(err)
 		}
@@ -36,26 +46,22 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		return microerror.Mask(err)
 	}
 
+	cc.SetCondition(cr, condition.Validated, metav1.ConditionTrue, "Valid", "")
+
 	return nil
 }
 
-func (r *Resource) updateAppStatus(ctx context.Context, cr v1alpha1.App, reason string) error {
+func (r *Resource) updateAppStatus(ctx context.Context, cc *controllercontext.Context, cr v1alpha1.App, reason string) error {
 	r.logger.Debugf(ctx, "setting status for app %#q in namespace %#q", cr.Name, cr.Namespace)
 
-	// Get app CR again to ensure the resource version is correct.
-	currentCR, err := r.g8sClient.ApplicationV1alpha1().Apps(cr.Namespace).Get(ctx, cr.Name, metav1.GetOptions{})
-	if err != nil {
-		return microerror.Mask(err)
-	}
-
-	currentCR.Status = v1alpha1.AppStatus{
+	desiredStatus := v1alpha1.AppStatus{
 		Release: v1alpha1.AppStatusRelease{
 			Reason: reason,
 			Status: status.ResourceNotFoundStatus,
 		},
 	}
 
-	_, err = r.g8sClient.ApplicationV1alpha1().Apps(cr.Namespace).UpdateStatus(ctx, currentCR, metav1.UpdateOptions{})
+	_, err := conditions.Update(ctx, r.dynClient, cr, &desiredStatus, cc.Conditions)
 	if err != nil {
 		return microerror.Mask(err)
 	}
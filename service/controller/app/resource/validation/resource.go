@@ -5,6 +5,7 @@ import (
 	"github.com/giantswarm/app/v5/pkg/validation"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -14,6 +15,7 @@ const (
 
 // Config represents the configuration used to create a new chartstatus resource.
 type Config struct {
+	DynClient dynamic.Interface
 	G8sClient versioned.Interface
 	K8sClient kubernetes.Interface
 	Logger    micrologger.Logger
@@ -24,11 +26,14 @@ type Config struct {
 // Resource implements the chartstatus resource.
 type Resource struct {
 	appValidator *validation.Validator
-	g8sClient    versioned.Interface
+	dynClient    dynamic.Interface
 	logger       micrologger.Logger
 }
 
 func New(config Config) (*Resource, error) {
+	if config.DynClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.DynClient must not be empty", config)
+	}
 	if config.G8sClient == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
 	}
@@ -63,7 +68,7 @@ func New(config Config) (*Resource, error) {
 	r := &Resource{
 		// Dependencies.
 		appValidator: appValidator,
-		g8sClient:    config.G8sClient,
+		dynClient:    config.DynClient,
 		logger:       config.Logger,
 	}
 
@@ -0,0 +1,109 @@
+package valuesschema
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/app/v5/pkg/key"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/app-operator/v5/pkg/status"
+	"github.com/giantswarm/app-operator/v5/service/controller/app/controllercontext"
+	"github.com/giantswarm/app-operator/v5/service/internal/helmrepo"
+	"github.com/giantswarm/app-operator/v5/service/internal/oci"
+	"github.com/giantswarm/app-operator/v5/service/internal/values"
+	"github.com/giantswarm/app-operator/v5/service/internal/valuesschema"
+)
+
+// EnsureCreated validates the merged values of the app against the values
+// schema of its chart. Violations are set in the controller context with the
+// values-schema-invalid status so the configmap, secret and chart resources
+// do not apply them.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	cr, err := key.ToApp(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if key.IsAppCordoned(cr) {
+		r.logger.Debugf(ctx, "app %#q is cordoned", cr.Name)
+		r.logger.Debugf(ctx, "canceling resource")
+		return nil
+	}
+
+	if oci.IsOCIStorage(cc.Catalog) {
+		r.logger.Debugf(ctx, "values schema validation is not supported for OCI catalog %#q", cc.Catalog.Name)
+		r.logger.Debugf(ctx, "canceling resource")
+		return nil
+	}
+
+	schema, err := r.getSchema(ctx, cc.Catalog, key.AppName(cr), cc.ChartVersion(cr))
+	if err != nil {
+		// Charts that can not be pulled fail in chart-operator with a more
+		// precise status so they are not blocked here.
+		r.logger.Errorf(ctx, err, "failed to get values schema of chart %#q", key.AppName(cr))
+		r.logger.Debugf(ctx, "canceling resource")
+		return nil
+	}
+	if len(schema) == 0 {
+		r.logger.Debugf(ctx, "chart %#q has no values schema", key.AppName(cr))
+		return nil
+	}
+
+	merged, err := r.values.MergeAll(ctx, cr, cc.Catalog)
+	if values.IsNotFound(err) || values.IsParsingError(err) || values.IsTemplateError(err) {
+		// The configmap and secret resources set the status.
+		r.logger.Debugf(ctx, "failed to merge values of app %#q", cr.Name)
+		r.logger.Debugf(ctx, "canceling resource")
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	violations, err := valuesschema.Validate(schema, merged)
+	if valuesschema.IsInvalidSchema(err) {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("values schema of chart %#q is invalid", key.AppName(cr)), "stack", fmt.Sprintf("%#v", err))
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if len(violations) == 0 {
+		r.logger.Debugf(ctx, "values of app %#q match values schema", cr.Name)
+		return nil
+	}
+
+	r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("values of app %#q do not match values schema", cr.Name), "violations", strings.Join(violations, "; "))
+
+	cc.Status.ChartStatus = controllercontext.ChartStatus{
+		Reason: fmt.Sprintf("values do not match %s of chart %#q: %s", valuesschema.SchemaFileName, key.AppName(cr), strings.Join(violations, "; ")),
+		Status: status.ValuesSchemaInvalidStatus,
+	}
+
+	return nil
+}
+
+func (r *Resource) getSchema(ctx context.Context, catalog v1alpha1.Catalog, appName, version string) ([]byte, error) {
+	client, err := helmrepo.NewCatalogHTTPClient(ctx, r.k8sClient, catalog, r.httpClientTimeout)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	tarballURL, err := r.indexCache.TarballURL(ctx, client, key.CatalogStorageURL(catalog), appName, version)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	schema, err := r.schemaCache.Get(ctx, client, tarballURL)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return schema, nil
+}
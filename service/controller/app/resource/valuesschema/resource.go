@@ -0,0 +1,79 @@
+package valuesschema
+
+import (
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
+	"github.com/giantswarm/app-operator/v5/service/internal/values"
+	"github.com/giantswarm/app-operator/v5/service/internal/valuesschema"
+)
+
+const (
+	Name = "valuesschema"
+)
+
+type Config struct {
+	IndexCache  *indexcache.Resource
+	K8sClient   kubernetes.Interface
+	Logger      micrologger.Logger
+	SchemaCache *valuesschema.Cache
+	Values      *values.Values
+
+	HTTPClientTimeout time.Duration
+}
+
+// Resource validates the merged values of the app against the
+// values.schema.json file of its chart. Invalid values block updates of the
+// chart CR and the values in the workload cluster.
+type Resource struct {
+	indexCache  *indexcache.Resource
+	k8sClient   kubernetes.Interface
+	logger      micrologger.Logger
+	schemaCache *valuesschema.Cache
+	values      *values.Values
+
+	httpClientTimeout time.Duration
+}
+
+// New creates a new configured valuesschema resource.
+func New(config Config) (*Resource, error) {
+	if config.IndexCache == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.IndexCache must not be empty", config)
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.SchemaCache == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.SchemaCache must not be empty", config)
+	}
+	if config.Values == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Values must not be empty", config)
+	}
+
+	if config.HTTPClientTimeout == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.HTTPClientTimeout must not be empty", config)
+	}
+
+	r := &Resource{
+		indexCache:  config.IndexCache,
+		k8sClient:   config.K8sClient,
+		logger:      config.Logger,
+		schemaCache: config.SchemaCache,
+		values:      config.Values,
+
+		httpClientTimeout: config.HTTPClientTimeout,
+	}
+
+	return r, nil
+}
+
+func (r Resource) Name() string {
+	return Name
+}
@@ -3,7 +3,6 @@ package app
 import (
 	"time"
 
-	"github.com/giantswarm/app/v5/pkg/values"
 	"github.com/giantswarm/helmclient/v4/pkg/helmclient"
 	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
 	"github.com/giantswarm/microerror"
@@ -16,6 +15,7 @@ import (
 
 	"github.com/giantswarm/app-operator/v5/service/controller/app/resource/appfinalizermigration"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/resource/appnamespace"
+	"github.com/giantswarm/app-operator/v5/service/controller/app/resource/appversion"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/resource/authtokenmigration"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/resource/catalog"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/resource/chart"
@@ -23,22 +23,35 @@ import (
 	"github.com/giantswarm/app-operator/v5/service/controller/app/resource/chartoperator"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/resource/clients"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/resource/configmap"
+	"github.com/giantswarm/app-operator/v5/service/controller/app/resource/deletionpolicy"
+	"github.com/giantswarm/app-operator/v5/service/controller/app/resource/dependencyorder"
+	"github.com/giantswarm/app-operator/v5/service/controller/app/resource/maintenancewindow"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/resource/releasemigration"
+	"github.com/giantswarm/app-operator/v5/service/controller/app/resource/rollback"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/resource/secret"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/resource/status"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/resource/tcnamespace"
 	"github.com/giantswarm/app-operator/v5/service/controller/app/resource/validation"
+	valuesschemaresource "github.com/giantswarm/app-operator/v5/service/controller/app/resource/valuesschema"
 	"github.com/giantswarm/app-operator/v5/service/internal/clientcache"
 	"github.com/giantswarm/app-operator/v5/service/internal/crdcache"
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
+	"github.com/giantswarm/app-operator/v5/service/internal/recorder"
+	"github.com/giantswarm/app-operator/v5/service/internal/values"
+	"github.com/giantswarm/app-operator/v5/service/internal/valuesschema"
+	"github.com/giantswarm/app-operator/v5/service/valuesprovenance"
 )
 
 type appResourcesConfig struct {
 	// Dependencies.
-	ClientCache *clientcache.Resource
-	CRDCache    *crdcache.Resource
-	FileSystem  afero.Fs
-	K8sClient   k8sclient.Interface
-	Logger      micrologger.Logger
+	ClientCache      *clientcache.Resource
+	CRDCache         *crdcache.Resource
+	Event            recorder.Interface
+	FileSystem       afero.Fs
+	IndexCache       *indexcache.Resource
+	K8sClient        k8sclient.Interface
+	Logger           micrologger.Logger
+	ValuesProvenance *valuesprovenance.Service
 
 	// Settings.
 	ChartNamespace    string
@@ -64,9 +77,18 @@ func newAppResources(config appResourcesConfig) ([]resource.Interface, error) {
 	if config.CRDCache == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.CRDCache must not be empty", config)
 	}
+	if config.Event == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Event must not be empty", config)
+	}
+	if config.IndexCache == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.IndexCache must not be empty", config)
+	}
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
+	if config.ValuesProvenance == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ValuesProvenance must not be empty", config)
+	}
 
 	// Settings.
 	if config.ChartNamespace == "" {
@@ -87,6 +109,8 @@ func newAppResources(config appResourcesConfig) ([]resource.Interface, error) {
 		c := values.Config{
 			K8sClient: config.K8sClient.K8sClient(),
 			Logger:    config.Logger,
+
+			Provider: config.Provider,
 		}
 
 		valuesService, err = values.New(c)
@@ -98,6 +122,7 @@ func newAppResources(config appResourcesConfig) ([]resource.Interface, error) {
 	var catalogResource resource.Interface
 	{
 		c := catalog.Config{
+			DynClient: config.K8sClient.DynClient(),
 			G8sClient: config.K8sClient.G8sClient(),
 			Logger:    config.Logger,
 		}
@@ -131,6 +156,24 @@ func newAppResources(config appResourcesConfig) ([]resource.Interface, error) {
 		}
 	}
 
+	var appVersionResource resource.Interface
+	{
+		c := appversion.Config{
+			DynClient:  config.K8sClient.DynClient(),
+			G8sClient:  config.K8sClient.G8sClient(),
+			IndexCache: config.IndexCache,
+			K8sClient:  config.K8sClient.K8sClient(),
+			Logger:     config.Logger,
+
+			HTTPClientTimeout: config.HTTPClientTimeout,
+			Provider:          config.Provider,
+		}
+		appVersionResource, err = appversion.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var authTokenMigrationResource resource.Interface
 	{
 		c := authtokenmigration.Config{
@@ -148,11 +191,13 @@ func newAppResources(config appResourcesConfig) ([]resource.Interface, error) {
 		c := chartoperator.Config{
 			FileSystem: config.FileSystem,
 			G8sClient:  config.K8sClient.G8sClient(),
+			IndexCache: config.IndexCache,
 			K8sClient:  config.K8sClient.K8sClient(),
 			Logger:     config.Logger,
 			Values:     valuesService,
 
-			ChartNamespace: config.ChartNamespace,
+			ChartNamespace:    config.ChartNamespace,
+			HTTPClientTimeout: config.HTTPClientTimeout,
 		}
 		chartOperatorResource, err = chartoperator.New(c)
 		if err != nil {
@@ -163,9 +208,13 @@ func newAppResources(config appResourcesConfig) ([]resource.Interface, error) {
 	var chartResource resource.Interface
 	{
 		c := chart.Config{
-			Logger: config.Logger,
+			G8sClient:  config.K8sClient.G8sClient(),
+			IndexCache: config.IndexCache,
+			K8sClient:  config.K8sClient.K8sClient(),
+			Logger:     config.Logger,
 
-			ChartNamespace: config.ChartNamespace,
+			ChartNamespace:    config.ChartNamespace,
+			HTTPClientTimeout: config.HTTPClientTimeout,
 		}
 
 		ops, err := chart.New(c)
@@ -192,6 +241,20 @@ func newAppResources(config appResourcesConfig) ([]resource.Interface, error) {
 		}
 	}
 
+	var dependencyOrderResource resource.Interface
+	{
+		c := dependencyorder.Config{
+			Event:     config.Event,
+			G8sClient: config.K8sClient.G8sClient(),
+			Logger:    config.Logger,
+		}
+
+		dependencyOrderResource, err = dependencyorder.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var helmClient helmclient.Interface
 	{
 		c := helmclient.Config{
@@ -228,8 +291,9 @@ func newAppResources(config appResourcesConfig) ([]resource.Interface, error) {
 	var configMapResource resource.Interface
 	{
 		c := configmap.Config{
-			Logger: config.Logger,
-			Values: valuesService,
+			Logger:           config.Logger,
+			Values:           valuesService,
+			ValuesProvenance: config.ValuesProvenance,
 
 			ChartNamespace: config.ChartNamespace,
 		}
@@ -245,6 +309,21 @@ func newAppResources(config appResourcesConfig) ([]resource.Interface, error) {
 		}
 	}
 
+	var maintenanceWindowResource resource.Interface
+	{
+		c := maintenancewindow.Config{
+			K8sClient: config.K8sClient.K8sClient(),
+			Logger:    config.Logger,
+
+			ChartNamespace: config.ChartNamespace,
+		}
+
+		maintenanceWindowResource, err = maintenancewindow.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var releaseMigrationResource resource.Interface
 	{
 		c := releasemigration.Config{
@@ -260,11 +339,55 @@ func newAppResources(config appResourcesConfig) ([]resource.Interface, error) {
 		}
 	}
 
+	var deletionPolicyResource resource.Interface
+	{
+		c := deletionpolicy.Config{
+			Logger: config.Logger,
+
+			ChartNamespace: config.ChartNamespace,
+		}
+
+		deletionPolicyResource, err = deletionpolicy.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var rollbackResource resource.Interface
+	{
+		c := rollback.Config{
+			Event:     config.Event,
+			G8sClient: config.K8sClient.G8sClient(),
+			Logger:    config.Logger,
+
+			ChartNamespace: config.ChartNamespace,
+		}
+
+		rollbackResource, err = rollback.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var schemaCache *valuesschema.Cache
+	{
+		c := valuesschema.Config{
+			FileSystem: config.FileSystem,
+			Logger:     config.Logger,
+		}
+
+		schemaCache, err = valuesschema.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var secretResource resource.Interface
 	{
 		c := secret.Config{
-			Logger: config.Logger,
-			Values: valuesService,
+			Logger:           config.Logger,
+			Values:           valuesService,
+			ValuesProvenance: config.ValuesProvenance,
 
 			ChartNamespace: config.ChartNamespace,
 		}
@@ -283,6 +406,8 @@ func newAppResources(config appResourcesConfig) ([]resource.Interface, error) {
 	var statusResource resource.Interface
 	{
 		c := status.Config{
+			DynClient: config.K8sClient.DynClient(),
+			Event:     config.Event,
 			G8sClient: config.K8sClient.G8sClient(),
 			Logger:    config.Logger,
 
@@ -307,9 +432,28 @@ func newAppResources(config appResourcesConfig) ([]resource.Interface, error) {
 		}
 	}
 
+	var valuesSchemaResource resource.Interface
+	{
+		c := valuesschemaresource.Config{
+			IndexCache:  config.IndexCache,
+			K8sClient:   config.K8sClient.K8sClient(),
+			Logger:      config.Logger,
+			SchemaCache: schemaCache,
+			Values:      valuesService,
+
+			HTTPClientTimeout: config.HTTPClientTimeout,
+		}
+
+		valuesSchemaResource, err = valuesschemaresource.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var validationResource resource.Interface
 	{
 		c := validation.Config{
+			DynClient: config.K8sClient.DynClient(),
 			G8sClient: config.K8sClient.G8sClient(),
 			K8sClient: config.K8sClient.K8sClient(),
 			Logger:    config.Logger,
@@ -333,7 +477,9 @@ func newAppResources(config appResourcesConfig) ([]resource.Interface, error) {
 		// Following resources manage controller context information.
 		appNamespaceResource,
 		catalogResource,
+		appVersionResource,
 		clientsResource,
+		maintenanceWindowResource,
 
 		// authTokenMigrationResource deletes auth token secrets that are no
 		// longer used.
@@ -345,10 +491,26 @@ func newAppResources(config appResourcesConfig) ([]resource.Interface, error) {
 		chartOperatorResource,
 		releaseMigrationResource,
 
+		// dependencyOrderResource deletes apps after the apps depending on
+		// them.
+		dependencyOrderResource,
+
+		// deletionPolicyResource orphans the chart CR before the generated
+		// values and the chart CR are deleted.
+		deletionPolicyResource,
+
+		// valuesSchemaResource blocks values that do not match the values
+		// schema of the chart.
+		valuesSchemaResource,
+
 		// Following resources process app CRs.
 		configMapResource,
 		secretResource,
 		chartResource,
+
+		// rollbackResource reverts failed upgrades to the last deployed
+		// release before the status is updated.
+		rollbackResource,
 		statusResource,
 	}
 
@@ -10,16 +10,22 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/giantswarm/app-operator/v5/pkg/project"
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
+	"github.com/giantswarm/app-operator/v5/service/internal/recorder"
 )
 
 const catalogControllerSuffix = "-catalog"
 
 type Config struct {
-	K8sClient k8sclient.Interface
-	Logger    micrologger.Logger
+	Event      recorder.Interface
+	IndexCache *indexcache.Resource
+	K8sClient  k8sclient.Interface
+	Logger     micrologger.Logger
 
-	MaxEntriesPerApp int
-	UniqueApp        bool
+	MaxEntriesPerApp    int
+	MetadataConcurrency int
+	MetadataRateLimit   float64
+	UniqueApp           bool
 }
 
 type Catalog struct {
@@ -29,6 +35,12 @@ type Catalog struct {
 func NewCatalog(config Config) (*Catalog, error) {
 	var err error
 
+	if config.Event == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Event must not be empty", config)
+	}
+	if config.IndexCache == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.IndexCache must not be empty", config)
+	}
 	if config.K8sClient == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
 	}
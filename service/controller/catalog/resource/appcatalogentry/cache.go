@@ -0,0 +1,91 @@
+package appcatalogentry
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/app/v5/pkg/key"
+)
+
+const (
+	// resyncPeriod is the maximum time a catalog with an unchanged index is
+	// skipped. The appMetadata files are not covered by the validators of the
+	// index so they are only refreshed by a full resync.
+	resyncPeriod = 30 * time.Minute
+)
+
+// syncedCatalog records the index used in the last successful sync of a
+// catalog and the number of appcatalogentry CRs that were created for it.
+type syncedCatalog struct {
+	catalogType string
+	entries     int
+	generation  int64
+	syncedAt    time.Time
+	validator   string
+}
+
+// syncCache tracks which catalogs are in sync with their index so unchanged
+// catalogs can be skipped.
+type syncCache struct {
+	mutex  sync.Mutex
+	synced map[string]syncedCatalog
+}
+
+func newSyncCache() *syncCache {
+	return &syncCache{
+		synced: map[string]syncedCatalog{},
+	}
+}
+
+// isSynced returns true if the catalog was synced with the index matching the
+// validator and the number of appcatalogentry CRs did not change since.
+func (c *syncCache) isSynced(cr v1alpha1.Catalog, validator string, entries int) bool {
+	if validator == "" {
+		return false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	s, ok := c.synced[catalogKey(cr)]
+	if !ok {
+		return false
+	}
+
+	if time.Since(s.syncedAt) > resyncPeriod {
+		return false
+	}
+
+	return s.validator == validator && s.generation == cr.Generation && s.catalogType == key.CatalogType(cr) && s.entries == entries
+}
+
+func (c *syncCache) setSynced(cr v1alpha1.Catalog, validator string, entries int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if validator == "" {
+		delete(c.synced, catalogKey(cr))
+		return
+	}
+
+	c.synced[catalogKey(cr)] = syncedCatalog{
+		catalogType: key.CatalogType(cr),
+		entries:     entries,
+		generation:  cr.Generation,
+		syncedAt:    time.Now(),
+		validator:   validator,
+	}
+}
+
+func (c *syncCache) deleteSynced(cr v1alpha1.Catalog) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.synced, catalogKey(cr))
+}
+
+func catalogKey(cr v1alpha1.Catalog) string {
+	return fmt.Sprintf("%s/%s", cr.Namespace, cr.Name)
+}
@@ -2,8 +2,8 @@ package appcatalogentry
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"net/http"
 	"sort"
 	"strconv"
 
@@ -18,8 +18,11 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	pkgannotation "github.com/giantswarm/app-operator/v5/pkg/annotation"
 	pkglabel "github.com/giantswarm/app-operator/v5/pkg/label"
 	"github.com/giantswarm/app-operator/v5/pkg/project"
+	"github.com/giantswarm/app-operator/v5/service/internal/digest"
+	"github.com/giantswarm/app-operator/v5/service/internal/helmrepo"
 )
 
 // EnsureCreated ensures appcatalogentry CRs are created or updated for this
@@ -47,13 +50,54 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		return microerror.Mask(err)
 	}
 
-	index, err := r.getIndex(ctx, key.CatalogStorageURL(cr))
+	credentials, err := helmrepo.GetCredentials(ctx, r.k8sClient.K8sClient(), cr)
 	if err != nil {
+		r.setSyncFailed(ctx, cr, repositoryAuthReason, err)
 		return microerror.Mask(err)
 	}
 
-	desiredEntryCRs, err := r.newAppCatalogEntries(ctx, cr, index)
+	client, err := helmrepo.NewHTTPClient(key.CatalogStorageURL(cr), credentials, httpClientTimeout)
 	if err != nil {
+		r.setSyncFailed(ctx, cr, repositoryAuthReason, err)
+		return microerror.Mask(err)
+	}
+
+	index, validator, err := r.getIndex(ctx, client, credentials, cr)
+	if err != nil {
+		r.event.EmitWarning(ctx, &cr, indexFetchFailedReason, "failed to get index of catalog %#q: %s", cr.Name, err.Error())
+		r.setSyncFailed(ctx, cr, indexFetchFailedReason, err)
+		return microerror.Mask(err)
+	}
+
+	policy, err := r.getRetentionPolicy(cr)
+	if err != nil {
+		r.setSyncFailed(ctx, cr, invalidRetentionPolicyReason, err)
+		return microerror.Mask(err)
+	}
+
+	var referenced map[string]bool
+	if policy.Referenced {
+		referenced, err = r.getReferencedVersions(ctx, cr)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	if validator != "" {
+		// Changes to the retention policy or the referenced versions must
+		// also trigger a sync.
+		validator = fmt.Sprintf("%s/%s", validator, retentionHash(policy, referenced))
+	}
+
+	if r.syncCache.isSynced(cr, validator, len(currentEntryCRs)) {
+		r.logger.Debugf(ctx, "index of catalog %#q is unchanged since last sync", cr.Name)
+		r.logger.Debugf(ctx, "canceling resource")
+		return nil
+	}
+
+	desiredEntryCRs, metadataErrors, err := r.newAppCatalogEntries(ctx, client, cr, index, policy, referenced)
+	if err != nil {
+		r.setSyncFailed(ctx, cr, entrySyncFailedReason, err)
 		return microerror.Mask(err)
 	}
 
@@ -89,6 +133,7 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("appCatalogEntry %#q has to be updated", currentEntryCR.Name), "diff", fmt.Sprintf("(-current +desired):\n%s", diff))
 			err := r.updateAppCatalogEntry(ctx, desiredEntryCR)
 			if err != nil {
+				r.setSyncFailed(ctx, cr, entrySyncFailedReason, err)
 				return microerror.Mask(err)
 			}
 
@@ -96,6 +141,7 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		} else {
 			err := r.createAppCatalogEntry(ctx, desiredEntryCR)
 			if err != nil {
+				r.setSyncFailed(ctx, cr, entrySyncFailedReason, err)
 				return microerror.Mask(err)
 			}
 
@@ -110,6 +156,7 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		if !ok {
 			err := r.deleteAppCatalogEntry(ctx, currentEntryCR)
 			if err != nil {
+				r.setSyncFailed(ctx, cr, entrySyncFailedReason, err)
 				return microerror.Mask(err)
 			}
 
@@ -119,6 +166,29 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 
 	r.logger.Debugf(ctx, "created %d updated %d deleted %d appcatalogentries for catalog %#q", created, updated, deleted, cr.Name)
 
+	err = r.updateMetadataErrors(ctx, cr, metadataErrors)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	status := catalogStatus{
+		IndexGenerated: index.Generated,
+		Entries:        len(desiredEntryCRs),
+		Created:        created,
+		Updated:        updated,
+		Deleted:        deleted,
+	}
+	err = r.setSyncSucceeded(ctx, cr, status)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if len(metadataErrors) == 0 {
+		// Catalogs with failures are synced again to retry pulling the
+		// appMetadata files.
+		r.syncCache.setSynced(cr, validator, len(desiredEntryCRs))
+	}
+
 	return nil
 }
 
@@ -170,20 +240,10 @@ func (r *Resource) updateAppCatalogEntry(ctx context.Context, entryCR *v1alpha1.
 	return nil
 }
 
-func (r *Resource) getDesiredAppCatalogEntryCR(ctx context.Context, cr *v1alpha1.Catalog, e entry, isLatest bool) (*v1alpha1.AppCatalogEntry, error) {
+func (r *Resource) getDesiredAppCatalogEntryCR(cr *v1alpha1.Catalog, e entry, isLatest, isLatestPrerelease bool, rawMetadata []byte) (*v1alpha1.AppCatalogEntry, error) {
 	var err error
 	name := key.AppCatalogEntryName(cr.Name, e.Name, e.Version)
 
-	var rawMetadata []byte
-	{
-		if url, ok := e.Annotations[annotation.AppMetadata]; ok {
-			rawMetadata, err = r.getMetadata(ctx, url)
-			if err != nil {
-				r.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("failed to get appMetadata for entry %#q in catalog %#q", e.Name, cr.Name), "stack", fmt.Sprintf("%#v", err))
-			}
-		}
-	}
-
 	// Until we add support for appMetadata files the updated time will be
 	// the same as the created time.
 	updatedTime := e.Created.DeepCopy()
@@ -213,6 +273,7 @@ func (r *Resource) getDesiredAppCatalogEntryCR(ctx context.Context, cr *v1alpha1
 				label.CatalogName:          cr.Name,
 				label.CatalogType:          key.CatalogType(*cr),
 				pkglabel.Latest:            strconv.FormatBool(isLatest),
+				pkglabel.LatestPrerelease:  strconv.FormatBool(isLatestPrerelease),
 				label.ManagedBy:            key.AppCatalogEntryManagedBy(project.Name()),
 			},
 			OwnerReferences: []metav1.OwnerReference{
@@ -251,6 +312,17 @@ func (r *Resource) getDesiredAppCatalogEntryCR(ctx context.Context, cr *v1alpha1
 		entryCR.Spec.DateUpdated = m.DataCreated
 	}
 
+	if e.Digest != "" {
+		// Annotations are copied so the cached index is not modified.
+		annotations := map[string]string{}
+		for k, v := range entryCR.Annotations {
+			annotations[k] = v
+		}
+		annotations[pkgannotation.ChartDigest] = digest.Normalize(e.Digest)
+
+		entryCR.Annotations = annotations
+	}
+
 	if entryCR.Spec.Chart.APIVersion == "" {
 		// chartAPIVersion default is `v1`.
 		entryCR.Spec.Chart.APIVersion = "v1"
@@ -265,89 +337,124 @@ func (r *Resource) getDesiredAppCatalogEntryCR(ctx context.Context, cr *v1alpha1
 	return entryCR, nil
 }
 
-// getLatestEntry returns the entry with the highest version without considering the creation date.
-func (r *Resource) getLatestEntry(ctx context.Context, entries []entry) (entry, error) {
-	var latestIndex int
-	var latestVersion semver.Version
-	var latestCreated metav1.Time
+// getLatestEntry returns the entry with the highest version using semver
+// precedence. Prerelease versions are only considered if prerelease is true.
+// For equal versions, e.g. with different build metadata, the most recently
+// created entry is returned. It returns false if there is no matching entry.
+func (r *Resource) getLatestEntry(ctx context.Context, entries []entry, prerelease bool) (entry, bool) {
+	latestIndex := -1
+	var latestVersion *semver.Version
 
 	for i := 0; i < len(entries); i++ {
 		v, err := semver.NewVersion(entries[i].Version)
-		if errors.As(err, &semver.ErrInvalidSemVer) {
+		if err != nil {
 			r.logger.Debugf(ctx, "invalid semver from converting app entry %s, version is %s", entries[i].Name, entries[i].Version)
 			continue
-		} else if err != nil {
-			return entry{}, microerror.Mask(err)
 		}
 
-		// Removing Prerelease from version since they are mostly SHA strings which we cannot compare.
-		nextVersion, err := v.SetPrerelease("")
-		if err != nil {
-			return entry{}, microerror.Mask(err)
+		if v.Prerelease() != "" && !prerelease {
+			continue
 		}
 
-		if nextVersion.GreaterThan(&latestVersion) {
+		if latestVersion == nil || v.GreaterThan(latestVersion) {
 			latestIndex = i
-			latestVersion = nextVersion
-			latestCreated = entries[i].Created
+			latestVersion = v
 			continue
 		}
 
-		if nextVersion.Equal(&latestVersion) {
-			if entries[i].Created.Time.After(latestCreated.Time) {
-				latestIndex = i
-				latestVersion = nextVersion
-				latestCreated = entries[i].Created
-			}
+		if v.Equal(latestVersion) && entries[i].Created.Time.After(entries[latestIndex].Created.Time) {
+			latestIndex = i
+			latestVersion = v
 		}
 	}
 
-	return entries[latestIndex], nil
+	if latestIndex < 0 {
+		return entry{}, false
+	}
+
+	return entries[latestIndex], true
 }
 
-func (r *Resource) newAppCatalogEntries(ctx context.Context, cr v1alpha1.Catalog, index index) (map[string]*v1alpha1.AppCatalogEntry, error) {
-	entryCRs := map[string]*v1alpha1.AppCatalogEntry{}
+// newAppCatalogEntries returns the desired appcatalogentry CRs for the index
+// that are kept by the retention policy.
+// The appMetadata files are pulled concurrently and failures to pull them are
+// returned so they can be reported on the catalog CR.
+func (r *Resource) newAppCatalogEntries(ctx context.Context, client *http.Client, cr v1alpha1.Catalog, index index, policy RetentionPolicy, referenced map[string]bool) (map[string]*v1alpha1.AppCatalogEntry, []metadataError, error) {
+	type desiredEntry struct {
+		entry              entry
+		isLatest           bool
+		isLatestPrerelease bool
+	}
+
+	var desiredEntries []desiredEntry
 
 	for _, entries := range index.Entries {
+		// Entries are sorted in place so we copy them to not modify the
+		// cached index.
+		entries = append([]entry(nil), entries...)
+
 		sort.Slice(entries, func(i, j int) bool {
 			return entries[i].Created.After(entries[j].Created.Time)
 		})
 
-		maxEntries := r.maxEntriesPerApp
-		if len(entries) < maxEntries {
-			maxEntries = len(entries)
+		latestEntry, hasStable := r.getLatestEntry(ctx, entries, false)
+		latestPrereleaseEntry, hasPrerelease := r.getLatestEntry(ctx, entries, true)
+
+		isLatest := func(e entry) bool {
+			return hasStable && e.Version == latestEntry.Version
+		}
+		isLatestPrerelease := func(e entry) bool {
+			return hasPrerelease && e.Version == latestPrereleaseEntry.Version
 		}
 
-		var latestEntryCR *v1alpha1.AppCatalogEntry
-		{
-			latestEntry, err := r.getLatestEntry(ctx, entries)
-			if err != nil {
-				return nil, microerror.Mask(err)
+		added := map[string]bool{}
+		add := func(e entry) {
+			if added[e.Version] {
+				return
 			}
+			added[e.Version] = true
 
-			latestEntryCR, err = r.getDesiredAppCatalogEntryCR(ctx, &cr, latestEntry, true)
-			if err != nil {
-				return nil, microerror.Mask(err)
-			}
+			desiredEntries = append(desiredEntries, desiredEntry{entry: e, isLatest: isLatest(e), isLatestPrerelease: isLatestPrerelease(e)})
 		}
 
-		for i := 0; i < maxEntries; i++ {
-			e := entries[i]
+		for _, e := range retainedEntries(cr, entries, policy, referenced) {
+			add(e)
+		}
 
-			entryCR, err := r.getDesiredAppCatalogEntryCR(ctx, &cr, e, latestEntryCR.Spec.Version == e.Version)
-			if err != nil {
-				return nil, microerror.Mask(err)
-			}
+		// If the latest entries are not included in the desired CRs, we add them so users can always see the latest CRs.
+		if hasStable {
+			add(latestEntry)
+		}
+		if hasPrerelease {
+			add(latestPrereleaseEntry)
+		}
+	}
 
-			entryCRs[entryCR.Name] = entryCR
+	var requests []metadataRequest
+	for _, d := range desiredEntries {
+		if u, ok := d.entry.Annotations[annotation.AppMetadata]; ok {
+			requests = append(requests, metadataRequest{
+				entry: key.AppCatalogEntryName(cr.Name, d.entry.Name, d.entry.Version),
+				url:   u,
+			})
 		}
+	}
 
-		// If the latest entry is not included in the desired CRs, we add it so users can always see the latest CR.
-		_, ok := entryCRs[latestEntryCR.Name]
-		if !ok {
-			entryCRs[latestEntryCR.Name] = latestEntryCR
+	metadataFiles, failures := r.getMetadataFiles(ctx, client, requests)
+	for _, f := range failures {
+		r.logger.Debugf(ctx, "failed to get appMetadata for entry %#q in catalog %#q: %s", f.Entry, cr.Name, f.Error)
+	}
+
+	entryCRs := map[string]*v1alpha1.AppCatalogEntry{}
+
+	for _, d := range desiredEntries {
+		entryCR, err := r.getDesiredAppCatalogEntryCR(&cr, d.entry, d.isLatest, d.isLatestPrerelease, metadataFiles[d.entry.Annotations[annotation.AppMetadata]])
+		if err != nil {
+			return nil, nil, microerror.Mask(err)
 		}
+
+		entryCRs[entryCR.Name] = entryCR
 	}
 
-	return entryCRs, nil
+	return entryCRs, failures, nil
 }
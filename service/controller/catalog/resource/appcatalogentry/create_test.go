@@ -0,0 +1,88 @@
+package appcatalogentry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_getLatestEntry(t *testing.T) {
+	newEntries := func(versions ...string) []entry {
+		var entries []entry
+		for i, v := range versions {
+			entries = append(entries, entry{
+				Created: metav1.NewTime(time.Date(2021, 9, 1+i, 0, 0, 0, 0, time.UTC)),
+				Name:    "prometheus",
+				Version: v,
+			})
+		}
+
+		return entries
+	}
+
+	tests := []struct {
+		name              string
+		entries           []entry
+		prerelease        bool
+		expectedVersion   string
+		expectedHasLatest bool
+	}{
+		{
+			name:              "case 0: prerelease of a higher version is not the latest stable",
+			entries:           newEntries("1.0.0", "1.1.0-rc.1", "1.0.1"),
+			expectedVersion:   "1.0.1",
+			expectedHasLatest: true,
+		},
+		{
+			name:              "case 1: prerelease of a higher version is the latest prerelease",
+			entries:           newEntries("1.0.0", "1.1.0-rc.1", "1.0.1"),
+			prerelease:        true,
+			expectedVersion:   "1.1.0-rc.1",
+			expectedHasLatest: true,
+		},
+		{
+			name:              "case 2: release has precedence over its prereleases",
+			entries:           newEntries("1.1.0-rc.2", "1.1.0", "1.1.0-rc.3"),
+			prerelease:        true,
+			expectedVersion:   "1.1.0",
+			expectedHasLatest: true,
+		},
+		{
+			name:              "case 3: prereleases are ordered by precedence",
+			entries:           newEntries("1.1.0-rc.10", "1.1.0-rc.9", "1.1.0-beta.1"),
+			prerelease:        true,
+			expectedVersion:   "1.1.0-rc.10",
+			expectedHasLatest: true,
+		},
+		{
+			name:              "case 4: equal versions use the most recent entry",
+			entries:           newEntries("1.0.0+build.2", "1.0.0+build.1"),
+			expectedVersion:   "1.0.0+build.1",
+			expectedHasLatest: true,
+		},
+		{
+			name:              "case 5: no stable version",
+			entries:           newEntries("1.0.0-abc", "invalid"),
+			expectedHasLatest: false,
+		},
+	}
+
+	r := &Resource{
+		logger: microloggertest.New(),
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			latest, ok := r.getLatestEntry(context.Background(), tc.entries, tc.prerelease)
+			if ok != tc.expectedHasLatest {
+				t.Fatalf("has latest == %t, want %t", ok, tc.expectedHasLatest)
+			}
+			if latest.Version != tc.expectedVersion {
+				t.Fatalf("version == %#q, want %#q", latest.Version, tc.expectedVersion)
+			}
+		})
+	}
+}
@@ -21,6 +21,8 @@ func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
 		return microerror.Mask(err)
 	}
 
+	r.syncCache.deleteSynced(cr)
+
 	entryCRs, err := r.getCurrentEntryCRs(ctx, cr)
 	if err != nil {
 		return microerror.Mask(err)
@@ -2,6 +2,15 @@ package appcatalogentry
 
 import "github.com/giantswarm/microerror"
 
+var executionFailedError = &microerror.Error{
+	Kind: "executionFailedError",
+}
+
+// IsExecutionFailed asserts executionFailedError.
+func IsExecutionFailed(err error) bool {
+	return microerror.Cause(err) == executionFailedError
+}
+
 var invalidConfigError = &microerror.Error{
 	Kind: "invalidConfigError",
 }
@@ -10,3 +19,12 @@ var invalidConfigError = &microerror.Error{
 func IsInvalidConfig(err error) bool {
 	return microerror.Cause(err) == invalidConfigError
 }
+
+var invalidRetentionPolicyError = &microerror.Error{
+	Kind: "invalidRetentionPolicyError",
+}
+
+// IsInvalidRetentionPolicy asserts invalidRetentionPolicyError.
+func IsInvalidRetentionPolicy(err error) bool {
+	return microerror.Cause(err) == invalidRetentionPolicyError
+}
@@ -0,0 +1,191 @@
+package appcatalogentry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/to"
+	"golang.org/x/time/rate"
+
+	pkgannotation "github.com/giantswarm/app-operator/v5/pkg/annotation"
+)
+
+const (
+	// maxReportedMetadataErrors limits the size of the annotation on the
+	// catalog CR.
+	maxReportedMetadataErrors = 10
+)
+
+// metadataError is a failure to pull an appMetadata file. Failures do not
+// stop the catalog from being synced and are reported on the catalog CR.
+type metadataError struct {
+	Entry string `json:"entry"`
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+// metadataRequest is an appMetadata file referenced by an index entry.
+type metadataRequest struct {
+	entry string
+	url   string
+}
+
+// hostLimiters rate limits requests per host. The limiters are kept across
+// reconciliations so the limit applies to all catalogs using the host.
+type hostLimiters struct {
+	burst int
+	limit rate.Limit
+
+	mutex    sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func newHostLimiters(limit float64, burst int) *hostLimiters {
+	return &hostLimiters{
+		burst: burst,
+		limit: rate.Limit(limit),
+
+		limiters: map[string]*rate.Limiter{},
+	}
+}
+
+func (h *hostLimiters) get(host string) *rate.Limiter {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	l, ok := h.limiters[host]
+	if !ok {
+		l = rate.NewLimiter(h.limit, h.burst)
+		h.limiters[host] = l
+	}
+
+	return l
+}
+
+// getMetadataFiles pulls the appMetadata files using a bounded number of
+// workers. The files are returned by URL and failures are sorted by URL so
+// the results do not depend on the order requests complete.
+func (r *Resource) getMetadataFiles(ctx context.Context, client *http.Client, requests []metadataRequest) (map[string][]byte, []metadataError) {
+	// Deduplicate so each file is only pulled once.
+	var urls []string
+	entries := map[string]string{}
+	for _, req := range requests {
+		if _, ok := entries[req.url]; ok {
+			continue
+		}
+
+		entries[req.url] = req.entry
+		urls = append(urls, req.url)
+	}
+	sort.Strings(urls)
+
+	files := make([][]byte, len(urls))
+	errs := make([]error, len(urls))
+
+	workers := r.metadataConcurrency
+	if len(urls) < workers {
+		workers = len(urls)
+	}
+
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				files[i], errs[i] = r.getRateLimitedMetadata(ctx, client, urls[i])
+			}
+		}()
+	}
+
+	for i := range urls {
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	result := map[string][]byte{}
+	var failures []metadataError
+
+	for i, u := range urls {
+		if errs[i] != nil {
+			failures = append(failures, metadataError{
+				Entry: entries[u],
+				URL:   u,
+				Error: errs[i].Error(),
+			})
+			continue
+		}
+
+		if files[i] != nil {
+			result[u] = files[i]
+		}
+	}
+
+	return result, failures
+}
+
+func (r *Resource) getRateLimitedMetadata(ctx context.Context, client *http.Client, mainURL string) ([]byte, error) {
+	u, err := url.Parse(mainURL)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	err = r.limiters.get(u.Host).Wait(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	body, err := r.getMetadata(ctx, client, mainURL)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return body, nil
+}
+
+// updateMetadataErrors sets the failures to pull appMetadata files as an
+// annotation on the catalog CR. The annotation is removed when there are no
+// failures and the catalog CR is only patched if the value changed.
+func (r *Resource) updateMetadataErrors(ctx context.Context, cr v1alpha1.Catalog, failures []metadataError) error {
+	var desired *string
+	if len(failures) > 0 {
+		if len(failures) > maxReportedMetadataErrors {
+			failures = failures[:maxReportedMetadataErrors]
+		}
+
+		b, err := json.Marshal(failures)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		desired = to.StringP(string(b))
+	}
+
+	current, ok := cr.GetAnnotations()[pkgannotation.CatalogMetadataErrors]
+	if !ok && desired == nil || ok && desired != nil && current == *desired {
+		return nil
+	}
+
+	r.logger.Debugf(ctx, "patching metadata errors of catalog %#q", cr.Name)
+
+	err := r.patchAnnotation(ctx, cr, pkgannotation.CatalogMetadataErrors, desired)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.Debugf(ctx, "patched metadata errors of catalog %#q", cr.Name)
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package appcatalogentry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_getMetadataFiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing/main.yaml":
+			w.WriteHeader(http.StatusNotFound)
+		case "/broken/main.yaml":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			fmt.Fprintf(w, "upstreamChartVersion: %s\n", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	r := &Resource{
+		limiters: newHostLimiters(100, 2),
+		logger:   microloggertest.New(),
+
+		metadataConcurrency: 2,
+	}
+
+	var requests []metadataRequest
+	for _, name := range []string{"broken", "kiam", "missing", "prometheus", "kiam"} {
+		requests = append(requests, metadataRequest{
+			entry: fmt.Sprintf("giantswarm-%s", name),
+			url:   fmt.Sprintf("%s/%s/main.yaml", server.URL, name),
+		})
+	}
+
+	files, failures := r.getMetadataFiles(context.Background(), server.Client(), requests)
+
+	expectedFiles := map[string][]byte{
+		server.URL + "/kiam/main.yaml":       []byte("upstreamChartVersion: /kiam/main.yaml\n"),
+		server.URL + "/prometheus/main.yaml": []byte("upstreamChartVersion: /prometheus/main.yaml\n"),
+	}
+	if !reflect.DeepEqual(files, expectedFiles) {
+		t.Fatalf("want matching files \n %s", cmp.Diff(files, expectedFiles))
+	}
+
+	if len(failures) != 1 {
+		t.Fatalf("failures == %d, want 1", len(failures))
+	}
+	if failures[0].Entry != "giantswarm-broken" || failures[0].URL != server.URL+"/broken/main.yaml" {
+		t.Fatalf("failure == %#v, want broken entry", failures[0])
+	}
+}
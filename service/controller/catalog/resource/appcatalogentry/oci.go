@@ -0,0 +1,127 @@
+package appcatalogentry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/app/v5/pkg/key"
+	"github.com/giantswarm/microerror"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pkgannotation "github.com/giantswarm/app-operator/v5/pkg/annotation"
+	"github.com/giantswarm/app-operator/v5/service/internal/digest"
+	"github.com/giantswarm/app-operator/v5/service/internal/helmrepo"
+	"github.com/giantswarm/app-operator/v5/service/internal/oci"
+)
+
+// getOCIIndex builds an index from the chart repositories and tags of an OCI
+// registry so OCI catalogs are processed the same way as Helm repositories.
+// The charts are listed in the catalog annotation or, if it is not set,
+// discovered from the repositories of the registry.
+func (r *Resource) getOCIIndex(ctx context.Context, credentials *helmrepo.Credentials, cr v1alpha1.Catalog) (index, error) {
+	eventName := "list_oci_tags"
+
+	t := prometheus.NewTimer(histogram.WithLabelValues(eventName))
+	defer t.ObserveDuration()
+
+	storageURL := key.CatalogStorageURL(cr)
+
+	names, err := r.getOCICharts(ctx, credentials, cr)
+	if err != nil {
+		return index{}, microerror.Mask(err)
+	}
+
+	i := index{
+		Entries:   map[string][]entry{},
+		Generated: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	for _, name := range names {
+		versions, err := r.ociClient.ListVersions(ctx, credentials, storageURL, name)
+		if err != nil {
+			return index{}, microerror.Mask(err)
+		}
+
+		var entries []entry
+
+		for _, version := range versions {
+			_, err := semver.NewVersion(version)
+			if err != nil {
+				// Tags like latest or commit SHAs are not chart versions.
+				r.logger.Debugf(ctx, "skipping tag %#q of chart %#q that is not a valid semver", version, name)
+				continue
+			}
+
+			chart, err := r.ociClient.GetChart(ctx, credentials, storageURL, name, version)
+			if oci.IsNotFound(err) {
+				r.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("skipping tag %#q of chart %#q", version, name), "stack", fmt.Sprintf("%#v", err))
+				continue
+			} else if err != nil {
+				return index{}, microerror.Mask(err)
+			}
+
+			ref, err := oci.ChartReference(storageURL, name, version)
+			if err != nil {
+				return index{}, microerror.Mask(err)
+			}
+
+			entries = append(entries, entry{
+				Annotations: chart.Metadata.Annotations,
+				AppVersion:  chart.Metadata.AppVersion,
+				Created:     metav1.NewTime(chart.Created),
+				Description: chart.Metadata.Description,
+				Digest:      digest.Normalize(chart.Digest),
+				Home:        chart.Metadata.Home,
+				Icon:        chart.Metadata.Icon,
+				Keywords:    chart.Metadata.Keywords,
+				Name:        name,
+				URLs:        []string{ref},
+				Version:     version,
+			})
+		}
+
+		if len(entries) == 0 {
+			continue
+		}
+
+		i.Entries[name] = entries
+	}
+
+	r.logger.Debugf(ctx, "listed %d charts in OCI registry %#q", len(i.Entries), storageURL)
+
+	return i, nil
+}
+
+// getOCICharts returns the chart names of the catalog annotation or lists
+// them in the registry.
+func (r *Resource) getOCICharts(ctx context.Context, credentials *helmrepo.Credentials, cr v1alpha1.Catalog) ([]string, error) {
+	if value, ok := cr.GetAnnotations()[pkgannotation.CatalogOCICharts]; ok {
+		var names []string
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+
+		return names, nil
+	}
+
+	storageURL := key.CatalogStorageURL(cr)
+
+	r.logger.Debugf(ctx, "listing charts in OCI registry %#q", storageURL)
+
+	names, err := r.ociClient.ListCharts(ctx, credentials, storageURL)
+	if oci.IsCatalogNotSupported(err) {
+		return nil, microerror.Maskf(executionFailedError, "%s, list the charts in the %#q annotation of the catalog", err.Error(), pkgannotation.CatalogOCICharts)
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return names, nil
+}
@@ -0,0 +1,44 @@
+package appcatalogentry
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pkgannotation "github.com/giantswarm/app-operator/v5/pkg/annotation"
+)
+
+func Test_getOCICharts(t *testing.T) {
+	r := &Resource{
+		logger: microloggertest.New(),
+	}
+
+	cr := v1alpha1.Catalog{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "giantswarm",
+			Namespace: "default",
+			Annotations: map[string]string{
+				pkgannotation.CatalogOCICharts: " kiam, prometheus,,",
+			},
+		},
+		Spec: v1alpha1.CatalogSpec{
+			Storage: v1alpha1.CatalogSpecStorage{
+				Type: "oci",
+				URL:  "oci://ghcr.io/giantswarm/charts",
+			},
+		},
+	}
+
+	// The registry is not asked for the charts listed in the annotation.
+	names, err := r.getOCICharts(context.Background(), nil, cr)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if !reflect.DeepEqual(names, []string{"kiam", "prometheus"}) {
+		t.Fatalf("names == %#v, want %#v", names, []string{"kiam", "prometheus"})
+	}
+}
@@ -2,10 +2,11 @@ package appcatalogentry
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
 	"github.com/giantswarm/app/v5/pkg/key"
@@ -15,9 +16,14 @@ import (
 	"github.com/giantswarm/micrologger"
 	"github.com/prometheus/client_golang/prometheus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/yaml"
 
 	"github.com/giantswarm/app-operator/v5/pkg/project"
+	"github.com/giantswarm/app-operator/v5/service/internal/helmrepo"
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
+	"github.com/giantswarm/app-operator/v5/service/internal/oci"
+	"github.com/giantswarm/app-operator/v5/service/internal/recorder"
 )
 
 const (
@@ -28,43 +34,88 @@ const (
 	kindCatalog          = "Catalog"
 	kindAppCatalogEntry  = "AppCatalogEntry"
 	maxEntriesPerApp     = 5
+	httpClientTimeout    = 30 * time.Second
+	metadataConcurrency  = 10
+	metadataRateLimit    = 10
 )
 
 type Config struct {
-	K8sClient k8sclient.Interface
-	Logger    micrologger.Logger
-
-	MaxEntriesPerApp int
-	UniqueApp        bool
+	Event      recorder.Interface
+	IndexCache *indexcache.Resource
+	K8sClient  k8sclient.Interface
+	Logger     micrologger.Logger
+	OCIClient  *oci.Client
+
+	MetadataConcurrency int
+	MetadataRateLimit   float64
+	// RetentionPolicy is used for catalogs without retention policy
+	// annotation.
+	RetentionPolicy RetentionPolicy
+	UniqueApp       bool
 }
 
 type Resource struct {
-	k8sClient k8sclient.Interface
-	logger    micrologger.Logger
-
-	maxEntriesPerApp int
-	uniqueApp        bool
+	event      recorder.Interface
+	indexCache *indexcache.Resource
+	k8sClient  k8sclient.Interface
+	logger     micrologger.Logger
+	ociClient  *oci.Client
+	limiters   *hostLimiters
+	syncCache  *syncCache
+
+	metadataConcurrency int
+	metadataRateLimit   float64
+	retentionPolicy     RetentionPolicy
+	uniqueApp           bool
 }
 
 // New creates a new configured tcnamespace resource.
 func New(config Config) (*Resource, error) {
+	if config.Event == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Event must not be empty", config)
+	}
+	if config.IndexCache == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.IndexCache must not be empty", config)
+	}
 	if config.K8sClient == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
 	}
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
+	if config.OCIClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.OCIClient must not be empty", config)
+	}
 
-	if config.MaxEntriesPerApp == 0 {
-		config.MaxEntriesPerApp = maxEntriesPerApp
+	if config.RetentionPolicy == (RetentionPolicy{}) {
+		config.RetentionPolicy = RetentionPolicy{
+			MaxEntries: maxEntriesPerApp,
+		}
+	}
+	err := config.RetentionPolicy.validate()
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.RetentionPolicy is invalid: %s", config, err.Error())
+	}
+	if config.MetadataConcurrency == 0 {
+		config.MetadataConcurrency = metadataConcurrency
+	}
+	if config.MetadataRateLimit == 0 {
+		config.MetadataRateLimit = metadataRateLimit
 	}
 
 	r := &Resource{
-		k8sClient: config.K8sClient,
-		logger:    config.Logger,
-
-		maxEntriesPerApp: config.MaxEntriesPerApp,
-		uniqueApp:        config.UniqueApp,
+		event:      config.Event,
+		indexCache: config.IndexCache,
+		k8sClient:  config.K8sClient,
+		limiters:   newHostLimiters(config.MetadataRateLimit, config.MetadataConcurrency),
+		logger:     config.Logger,
+		ociClient:  config.OCIClient,
+		syncCache:  newSyncCache(),
+
+		metadataConcurrency: config.MetadataConcurrency,
+		metadataRateLimit:   config.MetadataRateLimit,
+		retentionPolicy:     config.RetentionPolicy,
+		uniqueApp:           config.UniqueApp,
 	}
 
 	return r, nil
@@ -96,36 +147,36 @@ func (r *Resource) getCurrentEntryCRs(ctx context.Context, cr v1alpha1.Catalog)
 	return currentEntryCRs, nil
 }
 
-func (r *Resource) getIndex(ctx context.Context, storageURL string) (index, error) {
-	indexURL := fmt.Sprintf("%s/index.yaml", strings.TrimRight(storageURL, "/"))
-
-	r.logger.Debugf(ctx, "getting index.yaml from %#q", indexURL)
-
-	// We use https in catalog URLs so we can disable the linter in this case.
-	resp, err := http.Get(indexURL) // #nosec
-	if err != nil {
-		return index{}, microerror.Mask(err)
+// getIndex returns the index of the catalog and a validator that changes when
+// the index changes. The validator is empty for OCI catalogs and Helm
+// repositories that return no validators.
+func (r *Resource) getIndex(ctx context.Context, client *http.Client, credentials *helmrepo.Credentials, cr v1alpha1.Catalog) (index, string, error) {
+	if oci.IsOCIStorage(cr) {
+		i, err := r.getOCIIndex(ctx, credentials, cr)
+		if err != nil {
+			return index{}, "", microerror.Mask(err)
+		}
+
+		return i, "", nil
 	}
-	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return index{}, microerror.Mask(err)
-	}
+	start := time.Now()
 
-	var i index
-
-	err = yaml.Unmarshal(body, &i)
+	i, validator, modified, err := r.indexCache.Revalidate(ctx, client, key.CatalogStorageURL(cr))
 	if err != nil {
-		return i, microerror.Mask(err)
+		return index{}, "", microerror.Mask(err)
 	}
 
-	r.logger.Debugf(ctx, "got index.yaml from %#q", indexURL)
+	if modified {
+		histogram.WithLabelValues("index_cache_miss").Observe(time.Since(start).Seconds())
+	} else {
+		histogram.WithLabelValues("index_cache_hit").Observe(time.Since(start).Seconds())
+	}
 
-	return i, nil
+	return i, validator, nil
 }
 
-func (r *Resource) getMetadata(ctx context.Context, mainURL string) ([]byte, error) {
+func (r *Resource) getMetadata(ctx context.Context, client *http.Client, mainURL string) ([]byte, error) {
 	eventName := "pull_metadata_file"
 
 	t := prometheus.NewTimer(histogram.WithLabelValues(eventName))
@@ -133,8 +184,13 @@ func (r *Resource) getMetadata(ctx context.Context, mainURL string) ([]byte, err
 
 	r.logger.Debugf(ctx, "getting main.yaml from %#q", mainURL)
 
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mainURL, nil)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
 	// We use https in catalog URLs so we can disable the linter in this case.
-	resp, err := http.Get(mainURL) // #nosec
+	resp, err := client.Do(req) // #nosec
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
@@ -143,6 +199,8 @@ func (r *Resource) getMetadata(ctx context.Context, mainURL string) ([]byte, err
 	if resp.StatusCode == http.StatusNotFound {
 		r.logger.Debugf(ctx, "no main.yaml generated at %#q", mainURL)
 		return nil, nil
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, microerror.Maskf(executionFailedError, "expected status code %d for %#q, got %d", http.StatusOK, mainURL, resp.StatusCode)
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
@@ -155,6 +213,30 @@ func (r *Resource) getMetadata(ctx context.Context, mainURL string) ([]byte, err
 	return body, nil
 }
 
+// patchAnnotation sets the annotation on the catalog CR or removes it if the
+// value is nil.
+func (r *Resource) patchAnnotation(ctx context.Context, cr v1alpha1.Catalog, name string, value *string) error {
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]*string{
+				name: value,
+			},
+		},
+	}
+
+	b, err := json.Marshal(patch)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	_, err = r.k8sClient.G8sClient().ApplicationV1alpha1().Catalogs(cr.Namespace).Patch(ctx, cr.Name, types.MergePatchType, b, metav1.PatchOptions{})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
 func parseMetadata(rawMetadata []byte) (*appMetadata, error) {
 	var m appMetadata
 
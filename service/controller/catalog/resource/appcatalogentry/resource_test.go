@@ -0,0 +1,91 @@
+package appcatalogentry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
+)
+
+func Test_getIndex(t *testing.T) {
+	var downloads int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		downloads++
+
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, "entries:\n  prometheus:\n  - name: prometheus\n    version: 1.0.0\ngenerated: \"2021-09-01T10:00:00Z\"\n")
+	}))
+	defer server.Close()
+
+	indexCache, err := indexcache.New(indexcache.Config{Logger: microloggertest.New()})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	r := &Resource{
+		indexCache: indexCache,
+		logger:     microloggertest.New(),
+		syncCache:  newSyncCache(),
+	}
+
+	ctx := context.Background()
+
+	cr := v1alpha1.Catalog{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "giantswarm",
+			Namespace:  "default",
+			Generation: 1,
+		},
+		Spec: v1alpha1.CatalogSpec{
+			Storage: v1alpha1.CatalogSpecStorage{
+				Type: "helm",
+				URL:  server.URL,
+			},
+		},
+	}
+
+	var validator string
+	for i := 0; i < 2; i++ {
+		index, v, err := r.getIndex(ctx, server.Client(), nil, cr)
+		if err != nil {
+			t.Fatalf("error == %#v, want nil", err)
+		}
+		if len(index.Entries["prometheus"]) != 1 {
+			t.Fatalf("entries == %d, want 1", len(index.Entries["prometheus"]))
+		}
+		if v == "" {
+			t.Fatalf("validator == %#q, want non-empty", v)
+		}
+		validator = v
+	}
+
+	if downloads != 1 {
+		t.Fatalf("downloads == %d, want 1", downloads)
+	}
+
+	r.syncCache.setSynced(cr, validator, 1)
+	if !r.syncCache.isSynced(cr, validator, 1) {
+		t.Fatalf("catalog is not synced, want synced")
+	}
+	if r.syncCache.isSynced(cr, validator, 0) {
+		t.Fatalf("catalog with deleted entries is synced, want not synced")
+	}
+
+	cr.Generation = 2
+	if r.syncCache.isSynced(cr, validator, 1) {
+		t.Fatalf("changed catalog is synced, want not synced")
+	}
+}
@@ -0,0 +1,188 @@
+package appcatalogentry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/app/v5/pkg/key"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pkgannotation "github.com/giantswarm/app-operator/v5/pkg/annotation"
+)
+
+// RetentionPolicy configures which appcatalogentry CRs are kept per app. An
+// entry is kept if any of the rules match it. The latest entry is always kept.
+type RetentionPolicy struct {
+	// MaxEntries keeps the most recently created entries.
+	MaxEntries int `json:"maxEntries,omitempty"`
+	// PatchesPerMinor keeps the highest patch versions of each minor version.
+	PatchesPerMinor int `json:"patchesPerMinor,omitempty"`
+	// NewerThan keeps all versions higher than this version.
+	NewerThan string `json:"newerThan,omitempty"`
+	// Referenced keeps all versions used by app CRs.
+	Referenced bool `json:"referenced,omitempty"`
+}
+
+func (p RetentionPolicy) validate() error {
+	if p.MaxEntries < 0 {
+		return microerror.Maskf(invalidRetentionPolicyError, "maxEntries must not be negative")
+	}
+	if p.PatchesPerMinor < 0 {
+		return microerror.Maskf(invalidRetentionPolicyError, "patchesPerMinor must not be negative")
+	}
+	if p.NewerThan != "" {
+		_, err := semver.NewVersion(p.NewerThan)
+		if err != nil {
+			return microerror.Maskf(invalidRetentionPolicyError, "newerThan %#q is not a valid semver", p.NewerThan)
+		}
+	}
+
+	return nil
+}
+
+// getRetentionPolicy returns the retention policy from the catalog annotation
+// or the default policy if the catalog has none.
+func (r *Resource) getRetentionPolicy(cr v1alpha1.Catalog) (RetentionPolicy, error) {
+	v, ok := cr.GetAnnotations()[pkgannotation.CatalogRetentionPolicy]
+	if !ok {
+		return r.retentionPolicy, nil
+	}
+
+	var p RetentionPolicy
+
+	err := json.Unmarshal([]byte(v), &p)
+	if err != nil {
+		return RetentionPolicy{}, microerror.Maskf(invalidRetentionPolicyError, "annotation %#q: %s", pkgannotation.CatalogRetentionPolicy, err.Error())
+	}
+
+	err = p.validate()
+	if err != nil {
+		return RetentionPolicy{}, microerror.Mask(err)
+	}
+
+	return p, nil
+}
+
+// getReferencedVersions returns the app versions used by app CRs for this
+// catalog keyed by appcatalogentry name.
+func (r *Resource) getReferencedVersions(ctx context.Context, cr v1alpha1.Catalog) (map[string]bool, error) {
+	r.logger.Debugf(ctx, "getting app versions referencing catalog %#q", cr.Name)
+
+	apps, err := r.k8sClient.G8sClient().ApplicationV1alpha1().Apps(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	referenced := map[string]bool{}
+
+	for _, app := range apps.Items {
+		if key.CatalogName(app) != cr.Name {
+			continue
+		}
+		// App CRs without catalog namespace may use catalogs in several
+		// namespaces so their versions are kept to be safe.
+		if key.CatalogNamespace(app) != "" && key.CatalogNamespace(app) != cr.Namespace {
+			continue
+		}
+
+		version := key.Version(app)
+		// App CRs with a version constraint reference the version it was
+		// resolved to.
+		if resolved := app.GetAnnotations()[pkgannotation.AppResolvedVersion]; resolved != "" {
+			version = resolved
+		}
+
+		referenced[key.AppCatalogEntryName(cr.Name, key.AppName(app), version)] = true
+	}
+
+	r.logger.Debugf(ctx, "got %d app versions referencing catalog %#q", len(referenced), cr.Name)
+
+	return referenced, nil
+}
+
+// retainedEntries returns the entries kept by the policy. The entries must be
+// sorted by creation date with the most recent first.
+func retainedEntries(cr v1alpha1.Catalog, entries []entry, policy RetentionPolicy, referenced map[string]bool) []entry {
+	keep := make([]bool, len(entries))
+
+	for i := 0; i < policy.MaxEntries && i < len(entries); i++ {
+		keep[i] = true
+	}
+
+	versions := make([]*semver.Version, len(entries))
+	for i, e := range entries {
+		// Invalid versions are only kept by the other rules.
+		versions[i], _ = semver.NewVersion(e.Version)
+	}
+
+	if policy.PatchesPerMinor > 0 {
+		minors := map[string][]int{}
+		for i, v := range versions {
+			if v == nil {
+				continue
+			}
+
+			minor := fmt.Sprintf("%d.%d", v.Major(), v.Minor())
+			minors[minor] = append(minors[minor], i)
+		}
+
+		for _, indexes := range minors {
+			sort.SliceStable(indexes, func(a, b int) bool {
+				return versions[indexes[a]].GreaterThan(versions[indexes[b]])
+			})
+
+			for j := 0; j < policy.PatchesPerMinor && j < len(indexes); j++ {
+				keep[indexes[j]] = true
+			}
+		}
+	}
+
+	if policy.NewerThan != "" {
+		newerThan := semver.MustParse(policy.NewerThan)
+
+		for i, v := range versions {
+			if v != nil && v.GreaterThan(newerThan) {
+				keep[i] = true
+			}
+		}
+	}
+
+	for i, e := range entries {
+		if referenced[key.AppCatalogEntryName(cr.Name, e.Name, e.Version)] {
+			keep[i] = true
+		}
+	}
+
+	var retained []entry
+	for i, e := range entries {
+		if keep[i] {
+			retained = append(retained, e)
+		}
+	}
+
+	return retained
+}
+
+// retentionHash changes when the policy or the referenced versions change so
+// catalogs with an unchanged index are synced again.
+func retentionHash(policy RetentionPolicy, referenced map[string]bool) string {
+	var names []string
+	for name := range referenced {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%#v", policy)
+	for _, name := range names {
+		fmt.Fprintln(h, name)
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -0,0 +1,73 @@
+package appcatalogentry
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_retainedEntries(t *testing.T) {
+	cr := v1alpha1.Catalog{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "giantswarm",
+		},
+	}
+
+	// Entries are sorted by creation date with the most recent first.
+	var entries []entry
+	for i, version := range []string{"2.1.0", "1.2.2", "2.0.1", "1.2.1", "2.0.0", "1.2.0", "1.1.0", "1.0.0"} {
+		entries = append(entries, entry{
+			Created: metav1.NewTime(time.Date(2021, 9, 10-i, 0, 0, 0, 0, time.UTC)),
+			Name:    "prometheus",
+			Version: version,
+		})
+	}
+
+	tests := []struct {
+		name             string
+		policy           RetentionPolicy
+		referenced       map[string]bool
+		expectedVersions []string
+	}{
+		{
+			name:             "case 0: max entries",
+			policy:           RetentionPolicy{MaxEntries: 2},
+			expectedVersions: []string{"2.1.0", "1.2.2"},
+		},
+		{
+			name:             "case 1: patches per minor",
+			policy:           RetentionPolicy{PatchesPerMinor: 1},
+			expectedVersions: []string{"2.1.0", "1.2.2", "2.0.1", "1.1.0", "1.0.0"},
+		},
+		{
+			name:             "case 2: newer than",
+			policy:           RetentionPolicy{NewerThan: "1.2.1"},
+			expectedVersions: []string{"2.1.0", "1.2.2", "2.0.1", "2.0.0"},
+		},
+		{
+			name:   "case 3: referenced and max entries",
+			policy: RetentionPolicy{MaxEntries: 1, Referenced: true},
+			referenced: map[string]bool{
+				"giantswarm-prometheus-1.0.0": true,
+			},
+			expectedVersions: []string{"2.1.0", "1.0.0"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var versions []string
+			for _, e := range retainedEntries(cr, entries, tc.policy, tc.referenced) {
+				versions = append(versions, e.Version)
+			}
+
+			if !reflect.DeepEqual(versions, tc.expectedVersions) {
+				t.Fatalf("want matching versions \n %s", cmp.Diff(versions, tc.expectedVersions))
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package appcatalogentry
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/to"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pkgannotation "github.com/giantswarm/app-operator/v5/pkg/annotation"
+)
+
+const (
+	// Reasons for failed syncs set in the catalog status.
+	entrySyncFailedReason        = "EntrySyncFailed"
+	indexFetchFailedReason       = "IndexFetchFailed"
+	invalidRetentionPolicyReason = "InvalidRetentionPolicy"
+	repositoryAuthReason         = "RepositoryAuthFailed"
+)
+
+// catalogStatus is the sync status stored in the catalog status annotation.
+type catalogStatus struct {
+	// LastSyncTime is the time of the last successful sync.
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// IndexGenerated is the generated timestamp of the synced index.
+	IndexGenerated string `json:"indexGenerated,omitempty"`
+	Entries        int    `json:"entries"`
+	Created        int    `json:"created"`
+	Updated        int    `json:"updated"`
+	Deleted        int    `json:"deleted"`
+	// LastError is the error of the last sync. It is removed once a sync
+	// succeeds.
+	LastError *catalogStatusError `json:"lastError,omitempty"`
+}
+
+type catalogStatusError struct {
+	Message string      `json:"message"`
+	Reason  string      `json:"reason"`
+	Time    metav1.Time `json:"time"`
+}
+
+func getCatalogStatus(cr v1alpha1.Catalog) catalogStatus {
+	var s catalogStatus
+
+	v, ok := cr.GetAnnotations()[pkgannotation.CatalogStatus]
+	if !ok {
+		return s
+	}
+
+	err := json.Unmarshal([]byte(v), &s)
+	if err != nil {
+		// An invalid status is overwritten by the next sync.
+		return catalogStatus{}
+	}
+
+	return s
+}
+
+// setSyncSucceeded sets the result of a successful sync in the catalog status.
+// The status is not written when only the sync time would change unless it is
+// older than the resync period. Otherwise each status update would trigger a
+// new reconciliation and sync.
+func (r *Resource) setSyncSucceeded(ctx context.Context, cr v1alpha1.Catalog, desired catalogStatus) error {
+	current := getCatalogStatus(cr)
+
+	now := metav1.Now()
+	desired.LastSyncTime = &now
+
+	if current.LastSyncTime != nil && time.Since(current.LastSyncTime.Time) < resyncPeriod {
+		c := current
+		c.LastSyncTime = desired.LastSyncTime
+		if reflect.DeepEqual(c, desired) {
+			return nil
+		}
+	}
+
+	err := r.updateCatalogStatus(ctx, cr, desired)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// setSyncFailed sets the error of a failed sync in the catalog status. The
+// results of the last successful sync are kept. The status is only written
+// when the error changes.
+func (r *Resource) setSyncFailed(ctx context.Context, cr v1alpha1.Catalog, reason string, syncErr error) {
+	desired := getCatalogStatus(cr)
+
+	message := syncErr.Error()
+	if desired.LastError != nil && desired.LastError.Reason == reason && desired.LastError.Message == message {
+		return
+	}
+
+	desired.LastError = &catalogStatusError{
+		Message: message,
+		Reason:  reason,
+		Time:    metav1.Now(),
+	}
+
+	err := r.updateCatalogStatus(ctx, cr, desired)
+	if err != nil {
+		// The sync error is returned to operatorkit so we only log failures
+		// to update the status.
+		r.logger.Errorf(ctx, err, "failed to set status of catalog %#q", cr.Name)
+	}
+}
+
+func (r *Resource) updateCatalogStatus(ctx context.Context, cr v1alpha1.Catalog, status catalogStatus) error {
+	b, err := json.Marshal(status)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.Debugf(ctx, "setting status of catalog %#q", cr.Name)
+
+	err = r.patchAnnotation(ctx, cr, pkgannotation.CatalogStatus, to.StringP(string(b)))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.Debugf(ctx, "set status of catalog %#q", cr.Name)
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package appcatalogentry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/k8sclient/v5/pkg/k8sclienttest"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pkgannotation "github.com/giantswarm/app-operator/v5/pkg/annotation"
+)
+
+func Test_catalogStatus(t *testing.T) {
+	cr := &v1alpha1.Catalog{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "giantswarm",
+			Namespace: "default",
+		},
+	}
+
+	g8sClient := fake.NewSimpleClientset(cr)
+
+	r := &Resource{
+		k8sClient: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+			G8sClient: g8sClient,
+		}),
+		logger: microloggertest.New(),
+	}
+
+	ctx := context.Background()
+
+	getCatalog := func() v1alpha1.Catalog {
+		c, err := g8sClient.ApplicationV1alpha1().Catalogs(cr.Namespace).Get(ctx, cr.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("error == %#v, want nil", err)
+		}
+
+		return *c
+	}
+
+	r.setSyncFailed(ctx, getCatalog(), indexFetchFailedReason, errors.New("connection refused"))
+
+	status := getCatalogStatus(getCatalog())
+	if status.LastError == nil || status.LastError.Reason != indexFetchFailedReason {
+		t.Fatalf("last error == %#v, want reason %#q", status.LastError, indexFetchFailedReason)
+	}
+	if status.LastSyncTime != nil {
+		t.Fatalf("last sync time == %v, want nil", status.LastSyncTime)
+	}
+
+	err := r.setSyncSucceeded(ctx, getCatalog(), catalogStatus{Entries: 2, Created: 2, IndexGenerated: "2021-09-01T10:00:00Z"})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	synced := getCatalog()
+	status = getCatalogStatus(synced)
+	if status.LastError != nil {
+		t.Fatalf("last error == %#v, want nil", status.LastError)
+	}
+	if status.LastSyncTime == nil || status.Entries != 2 || status.Created != 2 {
+		t.Fatalf("status == %#v, want synced with 2 entries", status)
+	}
+
+	// An unchanged result does not update the status so it does not trigger
+	// another reconciliation.
+	err = r.setSyncSucceeded(ctx, synced, catalogStatus{Entries: 2, Created: 2, IndexGenerated: "2021-09-01T10:00:00Z"})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if getCatalog().Annotations[pkgannotation.CatalogStatus] != synced.Annotations[pkgannotation.CatalogStatus] {
+		t.Fatalf("status was updated, want unchanged")
+	}
+}
@@ -3,25 +3,15 @@ package appcatalogentry
 import (
 	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
 )
 
-type index struct {
-	Entries   map[string][]entry `json:"entries"`
-	Generated string             `json:"generated"`
-}
+// index is the index.yaml of a Helm repository. It is shared with the app
+// controller by the index cache so it must not be modified.
+type index = indexcache.Index
 
-type entry struct {
-	Annotations map[string]string `json:"annotations"`
-	AppVersion  string            `json:"appVersion"`
-	Created     metav1.Time       `json:"created"`
-	Description string            `json:"description"`
-	Home        string            `json:"home"`
-	Icon        string            `json:"icon"`
-	Keywords    []string          `json:"keywords"`
-	Name        string            `json:"name"`
-	Urls        []string          `json:"urls"`
-	Version     string            `json:"version"`
-}
+type entry = indexcache.Entry
 
 type appMetadata struct {
 	Annotations          map[string]string                         `json:"annotations"`
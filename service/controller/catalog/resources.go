@@ -10,16 +10,23 @@ import (
 
 	"github.com/giantswarm/app-operator/v5/service/controller/catalog/resource/appcatalogentry"
 	"github.com/giantswarm/app-operator/v5/service/controller/catalog/resource/appcatalogsync"
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
+	"github.com/giantswarm/app-operator/v5/service/internal/oci"
+	"github.com/giantswarm/app-operator/v5/service/internal/recorder"
 )
 
 type catalogResourcesConfig struct {
 	// Dependencies.
-	K8sClient k8sclient.Interface
-	Logger    micrologger.Logger
+	Event      recorder.Interface
+	IndexCache *indexcache.Resource
+	K8sClient  k8sclient.Interface
+	Logger     micrologger.Logger
 
 	// Settings.
-	MaxEntriesPerApp int
-	UniqueApp        bool
+	MaxEntriesPerApp    int
+	MetadataConcurrency int
+	MetadataRateLimit   float64
+	UniqueApp           bool
 }
 
 // newCatalogResources returns a configured Catalog controller ResourceSet.
@@ -31,14 +38,31 @@ func newCatalogResources(config catalogResourcesConfig) ([]resource.Interface, e
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
 
+	var ociClient *oci.Client
+	{
+		c := oci.Config{}
+
+		ociClient, err = oci.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var appCatalogEntryResource resource.Interface
 	{
 		c := appcatalogentry.Config{
-			K8sClient: config.K8sClient,
-			Logger:    config.Logger,
-
-			MaxEntriesPerApp: config.MaxEntriesPerApp,
-			UniqueApp:        config.UniqueApp,
+			Event:      config.Event,
+			IndexCache: config.IndexCache,
+			K8sClient:  config.K8sClient,
+			Logger:     config.Logger,
+			OCIClient:  ociClient,
+
+			MetadataConcurrency: config.MetadataConcurrency,
+			MetadataRateLimit:   config.MetadataRateLimit,
+			RetentionPolicy: appcatalogentry.RetentionPolicy{
+				MaxEntries: config.MaxEntriesPerApp,
+			},
+			UniqueApp: config.UniqueApp,
 		}
 
 		appCatalogEntryResource, err = appcatalogentry.New(c)
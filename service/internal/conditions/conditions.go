@@ -0,0 +1,222 @@
+// Package conditions writes the conditions of app CRs. The app CR type has no
+// conditions field so the status is read and updated with the dynamic client.
+// Updating the status with the typed client would remove the conditions. The
+// App CRD of apiextensions must have the conditions in its status schema.
+package conditions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/helmclient/v4/pkg/helmclient"
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/giantswarm/app-operator/v5/pkg/condition"
+	"github.com/giantswarm/app-operator/v5/service/internal/releasehistory"
+)
+
+var appResource = schema.GroupVersionResource{
+	Group:    v1alpha1.SchemeGroupVersion.Group,
+	Version:  v1alpha1.SchemeGroupVersion.Version,
+	Resource: "apps",
+}
+
+// Status is the status of an app CR including its conditions, the history of
+// its releases and the version constraint of the app CR with the chart
+// version it was resolved to.
+type Status struct {
+	v1alpha1.AppStatus `json:",inline"`
+	Conditions         []metav1.Condition       `json:"conditions,omitempty"`
+	ReleaseHistory     []releasehistory.Release `json:"releaseHistory,omitempty"`
+	ResolvedVersion    string                   `json:"resolvedVersion,omitempty"`
+	VersionConstraint  string                   `json:"versionConstraint,omitempty"`
+}
+
+// Deployed returns the Deployed condition for the release of the app.
+func Deployed(cr v1alpha1.App, release v1alpha1.AppStatusRelease) metav1.Condition {
+	c := metav1.Condition{
+		Type:               condition.Deployed,
+		ObservedGeneration: cr.GetGeneration(),
+		LastTransitionTime: metav1.Now(),
+		Message:            release.Reason,
+	}
+
+	switch release.Status {
+	case helmclient.StatusDeployed:
+		c.Status = metav1.ConditionTrue
+		c.Reason = condition.Reason(release.Status)
+	case "":
+		c.Status = metav1.ConditionUnknown
+		c.Reason = "ReleasePending"
+	default:
+		c.Status = metav1.ConditionFalse
+		c.Reason = condition.Reason(release.Status)
+	}
+
+	return c
+}
+
+// JoinReason prefixes the reason of the release with a message of
+// app-operator, e.g. about a rollback or dry run.
+func JoinReason(message, reason string) string {
+	if reason == "" {
+		return message
+	}
+
+	return fmt.Sprintf("%s: %s", message, reason)
+}
+
+// Merge returns the current conditions with the desired conditions set.
+// Conditions keep their last transition time while their status does not
+// change. Conditions not in desired are kept.
+func Merge(current, desired []metav1.Condition) []metav1.Condition {
+	merged := make([]metav1.Condition, 0, len(current)+len(desired))
+	for _, c := range current {
+		merged = append(merged, *c.DeepCopy())
+	}
+
+	for _, c := range desired {
+		meta.SetStatusCondition(&merged, c)
+	}
+
+	return merged
+}
+
+// Update sets the status of the app CR and merges the desired conditions into
+// its current conditions. A nil status keeps the current status. The app CR
+// is only updated when the status or the conditions changed. It returns true
+// if the app CR was updated. An error is returned if the API server pruned the
+// conditions because the App CRD does not have them in its status schema.
+func Update(ctx context.Context, client dynamic.Interface, cr v1alpha1.App, status *v1alpha1.AppStatus, desired []metav1.Condition) (bool, error) {
+	updated, err := update(ctx, client, cr, func(s *Status) {
+		s.Conditions = Merge(s.Conditions, desired)
+		if status != nil {
+			s.AppStatus = *status
+		}
+	})
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return updated, nil
+}
+
+// SetVersion sets the version constraint of the app CR and the chart version
+// it was resolved to in the status of the app CR. Empty values remove them.
+// It returns true if the app CR was updated.
+func SetVersion(ctx context.Context, client dynamic.Interface, cr v1alpha1.App, constraint, resolved string) (bool, error) {
+	updated, err := update(ctx, client, cr, func(s *Status) {
+		s.ResolvedVersion = resolved
+		s.VersionConstraint = constraint
+	})
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return updated, nil
+}
+
+// AddRelease records the release of the chart CR in the release history in
+// the status of the app CR. It returns true if the app CR was updated.
+func AddRelease(ctx context.Context, client dynamic.Interface, cr v1alpha1.App, chart v1alpha1.Chart) (bool, error) {
+	updated, err := update(ctx, client, cr, func(s *Status) {
+		s.ReleaseHistory = releasehistory.Add(s.ReleaseHistory, releasehistory.NewRelease(chart))
+	})
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return updated, nil
+}
+
+// update applies the change to the current status of the app CR and updates
+// the app CR if the status changed.
+func update(ctx context.Context, client dynamic.Interface, cr v1alpha1.App, change func(s *Status)) (bool, error) {
+	obj, err := client.Resource(appResource).Namespace(cr.Namespace).Get(ctx, cr.Name, metav1.GetOptions{})
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	current, err := getStatus(obj)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	// The change must not modify the conditions in place so the current
+	// status can be compared with the updated status.
+	updated := current
+	change(&updated)
+
+	if equality.Semantic.DeepEqual(current, updated) {
+		return false, nil
+	}
+
+	err = setStatus(obj, updated)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	obj, err = client.Resource(appResource).Namespace(cr.Namespace).UpdateStatus(ctx, obj, metav1.UpdateOptions{})
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	if len(updated.Conditions) > 0 {
+		_, ok, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+		if err != nil {
+			return false, microerror.Mask(err)
+		} else if !ok {
+			return false, microerror.Maskf(conditionsPrunedError, "conditions of app %#q in namespace %#q were pruned, the App CRD status schema has no conditions", cr.Name, cr.Namespace)
+		}
+	}
+
+	return true, nil
+}
+
+func getStatus(obj *unstructured.Unstructured) (Status, error) {
+	var status Status
+
+	m, ok, err := unstructured.NestedMap(obj.Object, "status")
+	if err != nil {
+		return Status{}, microerror.Mask(err)
+	} else if !ok {
+		return status, nil
+	}
+
+	bytes, err := json.Marshal(m)
+	if err != nil {
+		return Status{}, microerror.Mask(err)
+	}
+
+	err = json.Unmarshal(bytes, &status)
+	if err != nil {
+		return Status{}, microerror.Mask(err)
+	}
+
+	return status, nil
+}
+
+func setStatus(obj *unstructured.Unstructured, status Status) error {
+	bytes, err := json.Marshal(status)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var m map[string]interface{}
+	err = json.Unmarshal(bytes, &m)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	obj.Object["status"] = m
+
+	return nil
+}
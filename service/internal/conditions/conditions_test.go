@@ -0,0 +1,340 @@
+package conditions
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func Test_Merge(t *testing.T) {
+	before := metav1.NewTime(time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC))
+	now := metav1.NewTime(time.Date(2021, 9, 2, 10, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name               string
+		current            []metav1.Condition
+		desired            []metav1.Condition
+		expectedConditions []metav1.Condition
+	}{
+		{
+			name: "case 0: new condition is added",
+			desired: []metav1.Condition{
+				{Type: "Validated", Status: metav1.ConditionTrue, Reason: "Valid", LastTransitionTime: now},
+			},
+			expectedConditions: []metav1.Condition{
+				{Type: "Validated", Status: metav1.ConditionTrue, Reason: "Valid", LastTransitionTime: now},
+			},
+		},
+		{
+			name: "case 1: unchanged status keeps transition time",
+			current: []metav1.Condition{
+				{Type: "Validated", Status: metav1.ConditionTrue, Reason: "Valid", ObservedGeneration: 1, LastTransitionTime: before},
+			},
+			desired: []metav1.Condition{
+				{Type: "Validated", Status: metav1.ConditionTrue, Reason: "Valid", ObservedGeneration: 2, LastTransitionTime: now},
+			},
+			expectedConditions: []metav1.Condition{
+				{Type: "Validated", Status: metav1.ConditionTrue, Reason: "Valid", ObservedGeneration: 2, LastTransitionTime: before},
+			},
+		},
+		{
+			name: "case 2: changed status sets transition time and keeps other conditions",
+			current: []metav1.Condition{
+				{Type: "Deployed", Status: metav1.ConditionTrue, Reason: "Deployed", LastTransitionTime: before},
+				{Type: "Validated", Status: metav1.ConditionTrue, Reason: "Valid", LastTransitionTime: before},
+			},
+			desired: []metav1.Condition{
+				{Type: "Validated", Status: metav1.ConditionFalse, Reason: "ValidationFailed", Message: "invalid", LastTransitionTime: now},
+			},
+			expectedConditions: []metav1.Condition{
+				{Type: "Deployed", Status: metav1.ConditionTrue, Reason: "Deployed", LastTransitionTime: before},
+				{Type: "Validated", Status: metav1.ConditionFalse, Reason: "ValidationFailed", Message: "invalid", LastTransitionTime: now},
+			},
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			conditions := Merge(tc.current, tc.desired)
+			if !cmp.Equal(conditions, tc.expectedConditions) {
+				t.Fatalf("want matching conditions \n %s", cmp.Diff(conditions, tc.expectedConditions))
+			}
+		})
+	}
+}
+
+func Test_Update(t *testing.T) {
+	ctx := context.Background()
+
+	app := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "application.giantswarm.io/v1alpha1",
+			"kind":       "App",
+			"metadata": map[string]interface{}{
+				"name":      "prometheus",
+				"namespace": "default",
+			},
+			"status": map[string]interface{}{
+				"appVersion": "2.0.0",
+				"release": map[string]interface{}{
+					"status": "deployed",
+				},
+				"version": "1.0.0",
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"type":               "Deployed",
+						"status":             "True",
+						"reason":             "Deployed",
+						"message":            "",
+						"lastTransitionTime": "2021-09-01T10:00:00Z",
+					},
+				},
+			},
+		},
+	}
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), app)
+
+	cr := v1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "prometheus",
+			Namespace: "default",
+		},
+	}
+	validated := []metav1.Condition{
+		{Type: "Validated", Status: metav1.ConditionTrue, Reason: "Valid", LastTransitionTime: metav1.NewTime(time.Date(2021, 9, 2, 10, 0, 0, 0, time.UTC))},
+	}
+
+	updated, err := Update(ctx, client, cr, nil, validated)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if !updated {
+		t.Fatalf("updated == %t, want %t", updated, true)
+	}
+
+	obj, err := client.Resource(appResource).Namespace("default").Get(ctx, "prometheus", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	status, err := getStatus(obj)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if status.Version != "1.0.0" || status.Release.Status != "deployed" {
+		t.Fatalf("status == %#v, want current status kept", status.AppStatus)
+	}
+	if len(status.Conditions) != 2 {
+		t.Fatalf("conditions == %d, want %d", len(status.Conditions), 2)
+	}
+
+	updated, err = Update(ctx, client, cr, nil, validated)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if updated {
+		t.Fatalf("updated == %t, want %t", updated, false)
+	}
+}
+
+func Test_Update_prunedConditions(t *testing.T) {
+	ctx := context.Background()
+
+	app := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "application.giantswarm.io/v1alpha1",
+			"kind":       "App",
+			"metadata": map[string]interface{}{
+				"name":      "prometheus",
+				"namespace": "default",
+			},
+		},
+	}
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), app)
+	// Prune the conditions like the API server does for an App CRD without
+	// conditions in its status schema.
+	client.PrependReactor("update", "apps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := action.(k8stesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+		unstructured.RemoveNestedField(obj.Object, "status", "conditions")
+		return false, nil, nil
+	})
+
+	cr := v1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "prometheus",
+			Namespace: "default",
+		},
+	}
+	status := &v1alpha1.AppStatus{
+		Release: v1alpha1.AppStatusRelease{
+			Status: "deployed",
+		},
+		Version: "1.0.0",
+	}
+	validated := []metav1.Condition{
+		{Type: "Validated", Status: metav1.ConditionTrue, Reason: "Valid", LastTransitionTime: metav1.NewTime(time.Date(2021, 9, 2, 10, 0, 0, 0, time.UTC))},
+	}
+
+	_, err := Update(ctx, client, cr, status, validated)
+	if !IsConditionsPruned(err) {
+		t.Fatalf("error == %#v, want conditions pruned error", err)
+	}
+
+	obj, err := client.Resource(appResource).Namespace("default").Get(ctx, "prometheus", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	current, err := getStatus(obj)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if current.Version != "1.0.0" || current.Release.Status != "deployed" {
+		t.Fatalf("status == %#v, want status kept", current.AppStatus)
+	}
+}
+
+func Test_AddRelease(t *testing.T) {
+	ctx := context.Background()
+
+	app := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "application.giantswarm.io/v1alpha1",
+			"kind":       "App",
+			"metadata": map[string]interface{}{
+				"name":      "prometheus",
+				"namespace": "default",
+			},
+			"status": map[string]interface{}{
+				"version": "1.0.0",
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"type":               "Deployed",
+						"status":             "True",
+						"reason":             "Deployed",
+						"message":            "",
+						"lastTransitionTime": "2021-09-01T10:00:00Z",
+					},
+				},
+			},
+		},
+	}
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), app)
+
+	cr := v1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "prometheus",
+			Namespace: "default",
+		},
+	}
+	newChart := func(version string, revision int) v1alpha1.Chart {
+		return v1alpha1.Chart{
+			Status: v1alpha1.ChartStatus{
+				Release: v1alpha1.ChartStatusRelease{
+					Revision: &revision,
+					Status:   "deployed",
+				},
+				Version: version,
+			},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		chart           v1alpha1.Chart
+		expectedUpdated bool
+		expectedHistory []string
+	}{
+		{
+			name:            "case 0: first release is added",
+			chart:           newChart("1.0.0", 1),
+			expectedUpdated: true,
+			expectedHistory: []string{"1.0.0/deployed"},
+		},
+		{
+			name:            "case 1: same release is not added again",
+			chart:           newChart("1.0.0", 1),
+			expectedUpdated: false,
+			expectedHistory: []string{"1.0.0/deployed"},
+		},
+		{
+			name:            "case 2: upgrade supersedes the previous release",
+			chart:           newChart("1.1.0", 2),
+			expectedUpdated: true,
+			expectedHistory: []string{"1.1.0/deployed", "1.0.0/superseded"},
+		},
+	}
+
+	for i, tc := range tests {
+		t.Log(tc.name)
+
+		updated, err := AddRelease(ctx, client, cr, tc.chart)
+		if err != nil {
+			t.Fatalf("case %d: error == %#v, want nil", i, err)
+		}
+		if updated != tc.expectedUpdated {
+			t.Fatalf("case %d: updated == %t, want %t", i, updated, tc.expectedUpdated)
+		}
+
+		obj, err := client.Resource(appResource).Namespace("default").Get(ctx, "prometheus", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("case %d: error == %#v, want nil", i, err)
+		}
+		status, err := getStatus(obj)
+		if err != nil {
+			t.Fatalf("case %d: error == %#v, want nil", i, err)
+		}
+
+		var history []string
+		for _, r := range status.ReleaseHistory {
+			history = append(history, r.Version+"/"+r.Status)
+		}
+		if !cmp.Equal(history, tc.expectedHistory) {
+			t.Fatalf("case %d: history == %v, want %v", i, history, tc.expectedHistory)
+		}
+		if status.Version != "1.0.0" || len(status.Conditions) != 1 {
+			t.Fatalf("case %d: status == %#v, want current status kept", i, status)
+		}
+	}
+}
+
+func Test_JoinReason(t *testing.T) {
+	tests := []struct {
+		name           string
+		message        string
+		reason         string
+		expectedReason string
+	}{
+		{
+			name:           "case 0: message without reason",
+			message:        "rolled back to 1.0.0",
+			expectedReason: "rolled back to 1.0.0",
+		},
+		{
+			name:           "case 1: message prefixes reason",
+			message:        "rolled back to 1.0.0",
+			reason:         "upgrade failed",
+			expectedReason: "rolled back to 1.0.0: upgrade failed",
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			reason := JoinReason(tc.message, tc.reason)
+			if reason != tc.expectedReason {
+				t.Fatalf("reason == %#q, want %#q", reason, tc.expectedReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package conditions
+
+import "github.com/giantswarm/microerror"
+
+var conditionsPrunedError = &microerror.Error{
+	Kind: "conditionsPrunedError",
+}
+
+// IsConditionsPruned asserts conditionsPrunedError.
+func IsConditionsPruned(err error) bool {
+	return microerror.Cause(err) == conditionsPrunedError
+}
@@ -0,0 +1,41 @@
+package conditions
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// CRDName is the name of the App CRD.
+	CRDName = "apps.application.giantswarm.io"
+)
+
+// HasSchema returns true if all versions of the App CRD have the conditions
+// in their status schema. The App CRD is owned by apiextensions, app-operator
+// only checks it because the API server prunes conditions missing from the
+// schema.
+func HasSchema(ctx context.Context, client apiextensionsclient.Interface) (bool, error) {
+	crd, err := client.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, CRDName, metav1.GetOptions{})
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	for _, version := range crd.Spec.Versions {
+		if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+			return false, nil
+		}
+
+		status, ok := version.Schema.OpenAPIV3Schema.Properties["status"]
+		if !ok {
+			return false, nil
+		}
+		if _, ok := status.Properties["conditions"]; !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,73 @@
+package conditions
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_HasSchema(t *testing.T) {
+	newCRD := func(status map[string]apiextensionsv1.JSONSchemaProps) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: CRDName,
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+					{
+						Name: "v1alpha1",
+						Schema: &apiextensionsv1.CustomResourceValidation{
+							OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+								Properties: map[string]apiextensionsv1.JSONSchemaProps{
+									"status": {
+										Properties: status,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		crd            *apiextensionsv1.CustomResourceDefinition
+		expectedSchema bool
+	}{
+		{
+			name: "case 0: status schema without conditions",
+			crd: newCRD(map[string]apiextensionsv1.JSONSchemaProps{
+				"version": {Type: "string"},
+			}),
+			expectedSchema: false,
+		},
+		{
+			name: "case 1: status schema with conditions",
+			crd: newCRD(map[string]apiextensionsv1.JSONSchemaProps{
+				"conditions": {Type: "array"},
+				"version":    {Type: "string"},
+			}),
+			expectedSchema: true,
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			ok, err := HasSchema(context.Background(), fake.NewSimpleClientset(tc.crd))
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			if ok != tc.expectedSchema {
+				t.Fatalf("schema == %t, want %t", ok, tc.expectedSchema)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// Package dependency orders apps by the app CRs listed in their depends-on
+// annotation. Dependencies are app CRs in the same namespace.
+package dependency
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/app/v5/pkg/key"
+	"github.com/giantswarm/helmclient/v4/pkg/helmclient"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+)
+
+// Names returns the names of the app CRs the app depends on.
+func Names(cr v1alpha1.App) []string {
+	value := cr.GetAnnotations()[annotation.AppDependsOn]
+	if value == "" {
+		return nil
+	}
+
+	seen := map[string]bool{}
+
+	var names []string
+	for _, n := range strings.Split(value, ",") {
+		n = strings.TrimSpace(n)
+		if n == "" || seen[n] {
+			continue
+		}
+
+		seen[n] = true
+		names = append(names, n)
+	}
+
+	return names
+}
+
+// FindCycle returns the app CR names of a dependency cycle the app is part
+// of, starting and ending with the app. It returns nil if there is none.
+func FindCycle(cr v1alpha1.App, apps []v1alpha1.App) []string {
+	byName := map[string]v1alpha1.App{}
+	for _, app := range apps {
+		byName[app.Name] = app
+	}
+	byName[cr.Name] = cr
+
+	visited := map[string]bool{}
+
+	var visit func(name string, path []string) []string
+	visit = func(name string, path []string) []string {
+		app, ok := byName[name]
+		if !ok {
+			return nil
+		}
+
+		for _, dep := range Names(app) {
+			if dep == cr.Name {
+				return append(path, dep)
+			}
+			if visited[dep] {
+				continue
+			}
+			visited[dep] = true
+
+			cycle := visit(dep, append(path, dep))
+			if cycle != nil {
+				return cycle
+			}
+		}
+
+		return nil
+	}
+
+	return visit(cr.Name, []string{cr.Name})
+}
+
+// Waiting returns the names of the dependencies of the app that are missing
+// or not deployed.
+func Waiting(cr v1alpha1.App, apps []v1alpha1.App) []string {
+	byName := map[string]v1alpha1.App{}
+	for _, app := range apps {
+		byName[app.Name] = app
+	}
+
+	var waiting []string
+	for _, name := range Names(cr) {
+		app, ok := byName[name]
+		if !ok || key.IsDeleted(app) || app.Status.Release.Status != helmclient.StatusDeployed {
+			waiting = append(waiting, name)
+		}
+	}
+
+	return waiting
+}
+
+// Dependents returns the names of the app CRs depending on the app. They
+// must be deleted before the app.
+func Dependents(cr v1alpha1.App, apps []v1alpha1.App) []string {
+	var dependents []string
+	for _, app := range apps {
+		if app.Name == cr.Name {
+			continue
+		}
+
+		for _, name := range Names(app) {
+			if name == cr.Name {
+				dependents = append(dependents, app.Name)
+				break
+			}
+		}
+	}
+
+	sort.Strings(dependents)
+
+	return dependents
+}
@@ -0,0 +1,118 @@
+package dependency
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newApp(name, dependsOn, status string) v1alpha1.App {
+	app := v1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Status: v1alpha1.AppStatus{
+			Release: v1alpha1.AppStatusRelease{
+				Status: status,
+			},
+		},
+	}
+	if dependsOn != "" {
+		app.Annotations = map[string]string{
+			"app-operator.giantswarm.io/depends-on": dependsOn,
+		}
+	}
+
+	return app
+}
+
+func Test_Names(t *testing.T) {
+	names := Names(newApp("app", " cert-manager, ,kyverno,cert-manager", ""))
+	expected := []string{"cert-manager", "kyverno"}
+
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("want matching names \n %s", cmp.Diff(names, expected))
+	}
+}
+
+func Test_FindCycle(t *testing.T) {
+	tests := []struct {
+		name          string
+		cr            v1alpha1.App
+		apps          []v1alpha1.App
+		expectedCycle []string
+	}{
+		{
+			name: "case 0: no cycle",
+			cr:   newApp("a", "b,c", ""),
+			apps: []v1alpha1.App{
+				newApp("b", "c", ""),
+				newApp("c", "", ""),
+			},
+		},
+		{
+			name: "case 1: indirect cycle",
+			cr:   newApp("a", "b", ""),
+			apps: []v1alpha1.App{
+				newApp("b", "c", ""),
+				newApp("c", "a", ""),
+			},
+			expectedCycle: []string{"a", "b", "c", "a"},
+		},
+		{
+			name:          "case 2: self dependency",
+			cr:            newApp("a", "a", ""),
+			expectedCycle: []string{"a", "a"},
+		},
+		{
+			name: "case 3: cycle the app is not part of",
+			cr:   newApp("a", "b", ""),
+			apps: []v1alpha1.App{
+				newApp("b", "c", ""),
+				newApp("c", "b", ""),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cycle := FindCycle(tc.cr, tc.apps)
+			if !reflect.DeepEqual(cycle, tc.expectedCycle) {
+				t.Fatalf("want matching cycle \n %s", cmp.Diff(cycle, tc.expectedCycle))
+			}
+		})
+	}
+}
+
+func Test_Waiting(t *testing.T) {
+	apps := []v1alpha1.App{
+		newApp("cert-manager", "", "deployed"),
+		newApp("kyverno", "", "pending-upgrade"),
+	}
+
+	waiting := Waiting(newApp("app", "cert-manager,kyverno,missing", ""), apps)
+	expected := []string{"kyverno", "missing"}
+
+	if !reflect.DeepEqual(waiting, expected) {
+		t.Fatalf("want matching dependencies \n %s", cmp.Diff(waiting, expected))
+	}
+}
+
+func Test_Dependents(t *testing.T) {
+	apps := []v1alpha1.App{
+		newApp("cert-manager", "", "deployed"),
+		newApp("ingress", "cert-manager", "deployed"),
+		newApp("app", "kyverno,cert-manager", "deployed"),
+	}
+
+	dependents := Dependents(newApp("cert-manager", "", "deployed"), apps)
+	expected := []string{"app", "ingress"}
+
+	if !reflect.DeepEqual(dependents, expected) {
+		t.Fatalf("want matching dependents \n %s", cmp.Diff(dependents, expected))
+	}
+}
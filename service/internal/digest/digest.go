@@ -0,0 +1,91 @@
+// Package digest verifies chart tarballs against the digest from the catalog
+// index that is stored on appcatalogentry CRs or, if the entry does not
+// exist, against the digest from the cached index.
+package digest
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned"
+	"github.com/giantswarm/app/v5/pkg/key"
+	"github.com/giantswarm/microerror"
+	"github.com/spf13/afero"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
+	"github.com/giantswarm/app-operator/v5/service/internal/oci"
+)
+
+const (
+	prefix = "sha256:"
+)
+
+// Get returns the digest of the chart version from its appcatalogentry CR. If
+// the entry does not exist, e.g. because it was pruned by the retention policy
+// or the catalog is not synced yet, the digest is resolved from the index of
+// the Helm repository with the client so verification is not skipped. It
+// returns an empty string if no digest is known, i.e. the entry has no digest,
+// the index has no entry for the chart version or the catalog is stored in an
+// OCI registry, which has no index.
+func Get(ctx context.Context, g8sClient versioned.Interface, indexCache *indexcache.Resource, client *http.Client, catalog v1alpha1.Catalog, appName, version string) (string, error) {
+	name := key.AppCatalogEntryName(catalog.Name, appName, version)
+
+	entry, err := g8sClient.ApplicationV1alpha1().AppCatalogEntries(catalog.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		return entry.GetAnnotations()[annotation.ChartDigest], nil
+	} else if !apierrors.IsNotFound(err) {
+		return "", microerror.Mask(err)
+	}
+
+	if oci.IsOCIStorage(catalog) {
+		return "", nil
+	}
+
+	d, err := indexCache.Digest(ctx, client, key.CatalogStorageURL(catalog), appName, version)
+	if indexcache.IsNotFound(err) {
+		// The chart may still be pulled from the conventional tarball URL,
+		// it is just not pinned to a digest.
+		return "", nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return Normalize(d), nil
+}
+
+// Normalize returns the hex encoded SHA256 digest without algorithm prefix as
+// used in Helm repository indexes.
+func Normalize(d string) string {
+	return strings.ToLower(strings.TrimPrefix(d, prefix))
+}
+
+// Verify checks the SHA256 digest of the file matches the expected digest.
+func Verify(fs afero.Fs, path, expected string) error {
+	f, err := fs.Open(path)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	actual := fmt.Sprintf("%x", h.Sum(nil))
+	if actual != Normalize(expected) {
+		return microerror.Maskf(mismatchError, "expected digest %#q for %#q, got %#q", Normalize(expected), path, actual)
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/apiextensions/v3/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/spf13/afero"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
+)
+
+func Test_Get(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `entries:
+  kiam:
+  - digest: sha256:4A6F7B1E1A9F2E51B4B3D4A2C1E5F8A7B9C0D1E2F3A4B5C6D7E8F9A0B1C2D3E4
+    name: kiam
+    version: 2.0.0
+    urls:
+    - kiam-2.0.0.tgz
+`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		storageType     string
+		version         string
+		appCatalogEntry *v1alpha1.AppCatalogEntry
+		expectedDigest  string
+	}{
+		{
+			name:    "case 0: digest from appcatalogentry",
+			version: "2.0.0",
+			appCatalogEntry: &v1alpha1.AppCatalogEntry{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "giantswarm-kiam-2.0.0",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"application.giantswarm.io/chart-digest": "cc57fc1903e444cf6a726490b43b27ee9f87facc037f86872201847c565b45fb",
+					},
+				},
+			},
+			expectedDigest: "cc57fc1903e444cf6a726490b43b27ee9f87facc037f86872201847c565b45fb",
+		},
+		{
+			name:           "case 1: digest from index",
+			version:        "2.0.0",
+			expectedDigest: "4a6f7b1e1a9f2e51b4b3d4a2c1e5f8a7b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4",
+		},
+		{
+			name:           "case 2: chart version not found in index",
+			version:        "2.1.0",
+			expectedDigest: "",
+		},
+		{
+			name:           "case 3: oci catalog",
+			storageType:    "oci",
+			version:        "2.0.0",
+			expectedDigest: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g8sObjs := make([]runtime.Object, 0)
+			if tc.appCatalogEntry != nil {
+				g8sObjs = append(g8sObjs, tc.appCatalogEntry)
+			}
+
+			indexCache, err := indexcache.New(indexcache.Config{Logger: microloggertest.New()})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			catalog := v1alpha1.Catalog{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "giantswarm",
+					Namespace: "default",
+				},
+				Spec: v1alpha1.CatalogSpec{
+					Storage: v1alpha1.CatalogSpecStorage{
+						Type: tc.storageType,
+						URL:  server.URL,
+					},
+				},
+			}
+
+			d, err := Get(context.Background(), fake.NewSimpleClientset(g8sObjs...), indexCache, server.Client(), catalog, "kiam", tc.version)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			if d != tc.expectedDigest {
+				t.Fatalf("digest == %#q, want %#q", d, tc.expectedDigest)
+			}
+		})
+	}
+}
+
+func Test_Verify(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := afero.WriteFile(fs, "/tmp/chart.tgz", []byte("chart"), 0644)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	tests := []struct {
+		name         string
+		expected     string
+		errorMatcher func(error) bool
+	}{
+		{
+			name:     "case 0: matching digest",
+			expected: "cc57fc1903e444cf6a726490b43b27ee9f87facc037f86872201847c565b45fb",
+		},
+		{
+			name:     "case 1: matching digest with algorithm prefix",
+			expected: "sha256:CC57FC1903E444CF6A726490B43B27EE9F87FACC037F86872201847C565B45FB",
+		},
+		{
+			name:         "case 2: different digest",
+			expected:     "0000000000000000000000000000000000000000000000000000000000000000",
+			errorMatcher: IsMismatch,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Verify(fs, "/tmp/chart.tgz", tc.expected)
+			switch {
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case err != nil && !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package digest
+
+import "github.com/giantswarm/microerror"
+
+var mismatchError = &microerror.Error{
+	Kind: "mismatchError",
+}
+
+// IsMismatch asserts mismatchError.
+func IsMismatch(err error) bool {
+	return microerror.Cause(err) == mismatchError
+}
@@ -0,0 +1,211 @@
+// Package dryrun computes the changes of apps in dry run mode. The configmap,
+// secret and chart resources compute their desired state without applying
+// it and publish the redacted diff instead.
+package dryrun
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+)
+
+const (
+	// MaxDiffLength is the maximum length of the diff in the dry run
+	// annotation. Longer diffs are truncated.
+	MaxDiffLength = 32 * 1024
+	// MaxEventMessageLength is the maximum length of the message of dry run
+	// events. Kubernetes truncates longer messages.
+	MaxEventMessageLength = 1024
+
+	redacted        = "<redacted>"
+	redactedChanged = "<redacted, changed>"
+)
+
+// Change is the diff between the current and desired state of a resource of
+// an app.
+type Change struct {
+	Kind string
+	Name string
+	Diff string
+}
+
+// IsEnabled returns true if changes of the app must not be applied.
+func IsEnabled(cr v1alpha1.App) bool {
+	enabled, _ := strconv.ParseBool(cr.GetAnnotations()[annotation.AppDryRun])
+	return enabled
+}
+
+// ChartDiff returns the diff between the current and desired chart CR.
+func ChartDiff(current, desired *v1alpha1.Chart) string {
+	return cmp.Diff(current, desired)
+}
+
+// ConfigMapDiff returns the diff between the current and desired config map.
+func ConfigMapDiff(current, desired *corev1.ConfigMap) string {
+	return cmp.Diff(configMapFields(current), configMapFields(desired))
+}
+
+// SecretDiff returns the diff between the current and desired secret. The
+// values of the secret are redacted. Only the keys and whether their values
+// changed are shown.
+func SecretDiff(current, desired *corev1.Secret) string {
+	currentData := map[string]interface{}{}
+	desiredData := map[string]interface{}{}
+
+	if current != nil {
+		for k, v := range current.Data {
+			currentData[k] = redact(parse(v), nil)
+		}
+	}
+	if desired != nil {
+		for k, v := range desired.Data {
+			var c interface{}
+			if current != nil {
+				if cv, ok := current.Data[k]; ok {
+					c = parse(cv)
+				}
+			}
+			desiredData[k] = redact(parse(v), c)
+		}
+	}
+
+	return cmp.Diff(secretFields(current, currentData), secretFields(desired, desiredData))
+}
+
+// parse returns the secret value as YAML object if possible so changes are
+// shown per key.
+func parse(v []byte) interface{} {
+	var m map[string]interface{}
+	err := yaml.Unmarshal(v, &m)
+	if err != nil || m == nil {
+		return string(v)
+	}
+
+	return m
+}
+
+// redact replaces all values of v that are not maps. Values that differ from
+// the current value are marked as changed.
+func redact(v, current interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		if current != nil && !reflect.DeepEqual(v, current) {
+			return redactedChanged
+		}
+		return redacted
+	}
+
+	currentMap, _ := current.(map[string]interface{})
+
+	redactedMap := map[string]interface{}{}
+	for k, child := range m {
+		var c interface{}
+		if currentMap != nil {
+			c = currentMap[k]
+		}
+		redactedMap[k] = redact(child, c)
+	}
+
+	return redactedMap
+}
+
+// Format returns the changes as value of the dry run diff annotation. The
+// first line is the summary of the changes.
+func Format(changes []Change) string {
+	var b strings.Builder
+	b.WriteString(Summary(changes))
+	for _, c := range changes {
+		fmt.Fprintf(&b, "\n\n%s %s (-current +desired):\n%s", c.Kind, c.Name, c.Diff)
+	}
+
+	return truncate(b.String(), MaxDiffLength)
+}
+
+// EventMessage returns the changes as message of the dry run event.
+func EventMessage(changes []Change) string {
+	return truncate(Format(changes), MaxEventMessageLength)
+}
+
+// Reason returns the summary of the last dry run of the app CR or an empty
+// string if the app is not in dry run mode.
+func Reason(cr v1alpha1.App) string {
+	if !IsEnabled(cr) {
+		return ""
+	}
+
+	diff := cr.GetAnnotations()[annotation.AppDryRunDiff]
+	return strings.SplitN(diff, "\n", 2)[0]
+}
+
+// Summary describes the changes for the app status.
+func Summary(changes []Change) string {
+	if len(changes) == 0 {
+		return "dry run: no changes"
+	}
+
+	var kinds []string
+	for _, c := range changes {
+		kinds = append(kinds, fmt.Sprintf("%s %#q", c.Kind, c.Name))
+	}
+	sort.Strings(kinds)
+
+	return fmt.Sprintf("dry run: changes to %s not applied", strings.Join(kinds, ", "))
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+
+	suffix := "\n... truncated"
+	return s[:max-len(suffix)] + suffix
+}
+
+type objectFields struct {
+	Name        string
+	Namespace   string
+	Annotations map[string]string
+	Labels      map[string]string
+	Data        interface{}
+}
+
+func configMapFields(c *corev1.ConfigMap) objectFields {
+	if c == nil {
+		return objectFields{}
+	}
+
+	return objectFields{
+		Name:        c.Name,
+		Namespace:   c.Namespace,
+		Annotations: c.Annotations,
+		Labels:      c.Labels,
+		Data:        c.Data,
+	}
+}
+
+func secretFields(s *corev1.Secret, data map[string]interface{}) objectFields {
+	if s == nil {
+		return objectFields{}
+	}
+
+	f := objectFields{
+		Name:        s.Name,
+		Namespace:   s.Namespace,
+		Annotations: s.Annotations,
+		Labels:      s.Labels,
+	}
+	if len(data) > 0 {
+		f.Data = data
+	}
+
+	return f
+}
@@ -0,0 +1,62 @@
+package dryrun
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_SecretDiff(t *testing.T) {
+	current := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "prometheus-chart-secrets",
+			Namespace: "giantswarm",
+		},
+		Data: map[string][]byte{
+			"values": []byte("password: old-secret\nuser: admin\n"),
+		},
+	}
+	desired := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "prometheus-chart-secrets",
+			Namespace: "giantswarm",
+		},
+		Data: map[string][]byte{
+			"values": []byte("password: new-secret\nuser: admin\ntoken: abc\n"),
+		},
+	}
+
+	diff := SecretDiff(current, desired)
+
+	for _, s := range []string{"old-secret", "new-secret", "admin", "abc"} {
+		if strings.Contains(diff, s) {
+			t.Fatalf("diff contains secret value %#q \n %s", s, diff)
+		}
+	}
+	for _, s := range []string{"password", "token", redactedChanged} {
+		if !strings.Contains(diff, s) {
+			t.Fatalf("diff does not contain %#q \n %s", s, diff)
+		}
+	}
+}
+
+func Test_Format(t *testing.T) {
+	changes := []Change{
+		{Kind: "configmap", Name: "prometheus-chart-values", Diff: "-a\n+b"},
+		{Kind: "chart", Name: "prometheus", Diff: strings.Repeat("x", MaxDiffLength)},
+	}
+
+	diff := Format(changes)
+
+	if !strings.HasPrefix(diff, "dry run: changes to chart `prometheus`, configmap `prometheus-chart-values` not applied\n") {
+		t.Fatalf("diff does not start with summary \n %s", diff[:200])
+	}
+	if len(diff) != MaxDiffLength {
+		t.Fatalf("diff length == %d, want %d", len(diff), MaxDiffLength)
+	}
+	if len(EventMessage(changes)) != MaxEventMessageLength {
+		t.Fatalf("event message length == %d, want %d", len(EventMessage(changes)), MaxEventMessageLength)
+	}
+}
@@ -0,0 +1,142 @@
+package helmrepo
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/app/v5/pkg/key"
+	"github.com/giantswarm/microerror"
+	"github.com/spf13/afero"
+	"k8s.io/client-go/kubernetes"
+)
+
+// NewHTTPClient returns a HTTP client for the Helm repository at storageURL.
+// The credentials are optional. When set they are only sent to the host of
+// the repository so they are not leaked to hosts serving the tarballs.
+func NewHTTPClient(storageURL string, credentials *Credentials, timeout time.Duration) (*http.Client, error) {
+	if credentials == nil {
+		return &http.Client{Timeout: timeout}, nil
+	}
+
+	u, err := url.Parse(storageURL)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if len(credentials.CA) > 0 || len(credentials.Cert) > 0 {
+		tlsConfig := &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		}
+
+		if len(credentials.CA) > 0 {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(credentials.CA) {
+				return nil, microerror.Maskf(invalidConfigError, "failed to parse %#q", CAKey)
+			}
+			tlsConfig.RootCAs = pool
+		}
+
+		if len(credentials.Cert) > 0 {
+			cert, err := tls.X509KeyPair(credentials.Cert, credentials.Key)
+			if err != nil {
+				return nil, microerror.Maskf(invalidConfigError, "failed to parse client certificate: %s", err.Error())
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	c := &http.Client{
+		Timeout: timeout,
+		Transport: &authTransport{
+			base:        transport,
+			credentials: credentials,
+			host:        u.Host,
+		},
+	}
+
+	return c, nil
+}
+
+// NewCatalogHTTPClient returns a HTTP client for the Helm repository of the
+// catalog using the credentials from its auth secret if it references one.
+func NewCatalogHTTPClient(ctx context.Context, k8sClient kubernetes.Interface, catalog v1alpha1.Catalog, timeout time.Duration) (*http.Client, error) {
+	credentials, err := GetCredentials(ctx, k8sClient, catalog)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	client, err := NewHTTPClient(key.CatalogStorageURL(catalog), credentials, timeout)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return client, nil
+}
+
+// PullTarball downloads the chart tarball to a temporary file and returns its
+// path. The caller is responsible for removing the file.
+func PullTarball(ctx context.Context, client *http.Client, fs afero.Fs, tarballURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tarballURL, nil)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", microerror.Maskf(notFoundError, "tarball %#q", tarballURL)
+	} else if resp.StatusCode != http.StatusOK {
+		return "", microerror.Maskf(executionFailedError, "expected status code %d for %#q, got %d", http.StatusOK, tarballURL, resp.StatusCode)
+	}
+
+	f, err := afero.TempFile(fs, "", "chart-tarball-*.tgz")
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, resp.Body)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return f.Name(), nil
+}
+
+type authTransport struct {
+	base        http.RoundTripper
+	credentials *Credentials
+	host        string
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host {
+		return t.base.RoundTrip(req)
+	}
+
+	// Requests must not be modified by round trippers so we clone it.
+	req = req.Clone(req.Context())
+
+	if t.credentials.Token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.credentials.Token))
+	} else if t.credentials.Username != "" || t.credentials.Password != "" {
+		req.SetBasicAuth(t.credentials.Username, t.credentials.Password)
+	}
+
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,79 @@
+package helmrepo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func Test_NewHTTPClient(t *testing.T) {
+	var tarballAuth string
+
+	tarballs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tarballAuth = r.Header.Get("Authorization")
+		fmt.Fprint(w, "tarball")
+	}))
+	defer tarballs.Close()
+
+	repo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		fmt.Fprint(w, "tarball")
+	}))
+	defer repo.Close()
+
+	credentials := &Credentials{
+		Username: "user",
+		Password: "secret",
+	}
+
+	client, err := NewHTTPClient(repo.URL, credentials, 5*time.Second)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	ctx := context.Background()
+	fs := afero.NewMemMapFs()
+
+	path, err := PullTarball(ctx, client, fs, repo.URL+"/app-1.0.0.tgz")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if string(content) != "tarball" {
+		t.Fatalf("content == %#q, want %#q", content, "tarball")
+	}
+
+	// Credentials must only be sent to the repository host.
+	_, err = PullTarball(ctx, client, fs, tarballs.URL+"/app-1.0.0.tgz")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if tarballAuth != "" {
+		t.Fatalf("authorization header == %#q, want empty", tarballAuth)
+	}
+
+	// Requests without credentials are rejected by the repository.
+	client, err = NewHTTPClient(repo.URL, nil, 5*time.Second)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	_, err = PullTarball(ctx, client, fs, repo.URL+"/app-1.0.0.tgz")
+	if !IsExecutionFailed(err) {
+		t.Fatalf("error == %#v, want executionFailedError", err)
+	}
+}
@@ -0,0 +1,73 @@
+package helmrepo
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+)
+
+const (
+	// Keys of the catalog auth secret. They match the keys of the
+	// kubernetes.io/basic-auth and kubernetes.io/tls secret types.
+	CAKey       = "ca.crt"
+	CertKey     = "tls.crt"
+	KeyKey      = "tls.key"
+	PasswordKey = "password"
+	TokenKey    = "token"
+	UsernameKey = "username"
+)
+
+// Credentials are used to authenticate against a Helm repository.
+type Credentials struct {
+	Username string
+	Password string
+	Token    string
+
+	CA   []byte
+	Cert []byte
+	Key  []byte
+}
+
+// AuthSecretName returns the name of the secret with the repository
+// credentials of the catalog or an empty string if none is configured.
+func AuthSecretName(catalog v1alpha1.Catalog) string {
+	return catalog.GetAnnotations()[annotation.CatalogAuthSecret]
+}
+
+// GetCredentials returns the repository credentials of the catalog. It
+// returns nil when the catalog does not reference an auth secret.
+func GetCredentials(ctx context.Context, k8sClient kubernetes.Interface, catalog v1alpha1.Catalog) (*Credentials, error) {
+	name := AuthSecretName(catalog)
+	if name == "" {
+		return nil, nil
+	}
+
+	secret, err := k8sClient.CoreV1().Secrets(catalog.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, microerror.Maskf(notFoundError, "auth secret %#q in namespace %#q for catalog %#q", name, catalog.Namespace, catalog.Name)
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	c := &Credentials{
+		Username: string(secret.Data[UsernameKey]),
+		Password: string(secret.Data[PasswordKey]),
+		Token:    string(secret.Data[TokenKey]),
+
+		CA:   secret.Data[CAKey],
+		Cert: secret.Data[CertKey],
+		Key:  secret.Data[KeyKey],
+	}
+
+	if (len(c.Cert) == 0) != (len(c.Key) == 0) {
+		return nil, microerror.Maskf(invalidConfigError, "auth secret %#q in namespace %#q must contain both %#q and %#q", name, catalog.Namespace, CertKey, KeyKey)
+	}
+
+	return c, nil
+}
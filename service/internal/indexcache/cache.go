@@ -0,0 +1,353 @@
+// Package indexcache caches the indexes of Helm repositories by storage URL.
+// The catalog controller keeps them up to date and the app controller
+// resolves versions, tarball URLs and digests from them. Indexes are requested
+// conditionally using the ETag and Last-Modified validators of the cached
+// index.
+package indexcache
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/appcatalog"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	gocache "github.com/patrickmn/go-cache"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// expiration is longer than the resync period of the catalog controller
+	// so indexes of active catalogs are refreshed before they expire.
+	expiration = 1 * time.Hour
+
+	// refetchInterval is the minimum time between requests of an index
+	// without an entry for a chart version, so apps using a version missing
+	// from the index do not request it on every reconciliation.
+	refetchInterval = 1 * time.Minute
+)
+
+// Index is the index.yaml of a Helm repository. It is shared by all users of
+// the cache and must not be modified.
+type Index struct {
+	Entries   map[string][]Entry `json:"entries"`
+	Generated string             `json:"generated"`
+}
+
+// Entry is a chart version in the index. Its URLs may be relative to the
+// repository URL.
+type Entry struct {
+	Annotations map[string]string `json:"annotations"`
+	AppVersion  string            `json:"appVersion"`
+	Created     metav1.Time       `json:"created"`
+	Description string            `json:"description"`
+	Digest      string            `json:"digest"`
+	Home        string            `json:"home"`
+	Icon        string            `json:"icon"`
+	Keywords    []string          `json:"keywords"`
+	Name        string            `json:"name"`
+	URLs        []string          `json:"urls"`
+	Version     string            `json:"version"`
+}
+
+// cachedIndex is a parsed index with the validators returned by the server so
+// it can be requested conditionally.
+type cachedIndex struct {
+	// checked is when the index was last requested.
+	checked      time.Time
+	etag         string
+	index        Index
+	lastModified string
+}
+
+type Config struct {
+	// Dependencies.
+	Logger micrologger.Logger
+}
+
+type Resource struct {
+	// Dependencies.
+	cache  *gocache.Cache
+	logger micrologger.Logger
+}
+
+// New creates a new configured index cache.
+func New(config Config) (*Resource, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	r := &Resource{
+		cache:  gocache.New(expiration, expiration/2),
+		logger: config.Logger,
+	}
+
+	return r, nil
+}
+
+// Set stores the index of the Helm repository with the storage URL. It has no
+// validators so it is downloaded again on the first missing entry.
+func (r *Resource) Set(storageURL string, i Index) {
+	r.cache.SetDefault(storageURL, cachedIndex{
+		index: i,
+	})
+}
+
+// Revalidate requests the index of the Helm repository and returns it with a
+// validator that changes when the index changes. The validator is empty if
+// the server returned no ETag or Last-Modified header. A cached index is
+// requested conditionally and only downloaded and parsed again if it changed.
+// It returns true if the index was downloaded.
+func (r *Resource) Revalidate(ctx context.Context, client *http.Client, storageURL string) (Index, string, bool, error) {
+	i, modified, err := r.fetchIndex(ctx, client, storageURL)
+	if err != nil {
+		return Index{}, "", false, microerror.Mask(err)
+	}
+
+	return i.index, i.validator(), modified, nil
+}
+
+// Digest returns the digest of the chart version from its entry in the index
+// of the Helm repository. It is empty if the entry has no digest. The index is
+// fetched with the client if it is not cached or the cached index has no entry
+// for the chart version. A not found error is returned if the index has no
+// entry for the chart version.
+func (r *Resource) Digest(ctx context.Context, client *http.Client, storageURL, name, version string) (string, error) {
+	e, ok, err := r.getEntry(ctx, client, storageURL, name, version)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	if !ok {
+		return "", microerror.Maskf(notFoundError, "chart %#q version %#q not found in index of %#q", name, version, storageURL)
+	}
+
+	return e.Digest, nil
+}
+
+// TarballURL returns the URL of the chart tarball. It is resolved from the
+// urls field of the entry in the index of the Helm repository. The index is
+// fetched with the client if it is not cached or the cached index has no
+// entry for the chart version, e.g. because it was just released. The
+// conventional <storageURL>/<name>-<version>.tgz URL is only used if the
+// index has no entry for the chart version.
+func (r *Resource) TarballURL(ctx context.Context, client *http.Client, storageURL, name, version string) (string, error) {
+	e, ok, err := r.getEntry(ctx, client, storageURL, name, version)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	if ok {
+		tarballURL, err := ResolveURL(storageURL, e.URLs[0])
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		return tarballURL, nil
+	}
+
+	r.logger.Debugf(ctx, "did not find chart %#q version %#q in index of %#q, using conventional tarball URL", name, version, storageURL)
+
+	tarballURL, err := appcatalog.NewTarballURL(storageURL, name, version)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return tarballURL, nil
+}
+
+// Versions returns the versions of the chart in the index of the Helm
+// repository. The index is fetched with the client if it is not cached.
+func (r *Resource) Versions(ctx context.Context, client *http.Client, storageURL, name string) ([]string, error) {
+	i, _, err := r.getIndex(ctx, client, storageURL)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var versions []string
+	for _, e := range i.index.Entries[name] {
+		versions = append(versions, e.Version)
+	}
+
+	return versions, nil
+}
+
+// getEntry returns the entry of the chart version and true if it is in the
+// index. A cached index without the entry is requested again, e.g. because
+// the chart version was just released, but at most once per refetch interval.
+func (r *Resource) getEntry(ctx context.Context, client *http.Client, storageURL, name, version string) (Entry, bool, error) {
+	i, cached, err := r.getIndex(ctx, client, storageURL)
+	if err != nil {
+		return Entry{}, false, microerror.Mask(err)
+	}
+
+	e, ok := i.index.entry(name, version)
+	if !ok && cached {
+		if time.Since(i.checked) < refetchInterval {
+			r.logger.Debugf(ctx, "did not find chart %#q version %#q in index of %#q checked at %s", name, version, storageURL, i.checked.Format(time.RFC3339))
+			return Entry{}, false, nil
+		}
+
+		r.logger.Debugf(ctx, "did not find chart %#q version %#q in cached index of %#q", name, version, storageURL)
+
+		i, _, err = r.fetchIndex(ctx, client, storageURL)
+		if err != nil {
+			return Entry{}, false, microerror.Mask(err)
+		}
+
+		e, ok = i.index.entry(name, version)
+	}
+
+	return e, ok, nil
+}
+
+// getIndex returns the index of the Helm repository and true if it was
+// cached.
+func (r *Resource) getIndex(ctx context.Context, client *http.Client, storageURL string) (cachedIndex, bool, error) {
+	i, ok, err := r.getCached(storageURL)
+	if err != nil {
+		return cachedIndex{}, false, microerror.Mask(err)
+	}
+	if ok {
+		return i, true, nil
+	}
+
+	i, _, err = r.fetchIndex(ctx, client, storageURL)
+	if err != nil {
+		return cachedIndex{}, false, microerror.Mask(err)
+	}
+
+	return i, false, nil
+}
+
+func (r *Resource) getCached(storageURL string) (cachedIndex, bool, error) {
+	v, ok := r.cache.Get(storageURL)
+	if !ok {
+		return cachedIndex{}, false, nil
+	}
+
+	i, ok := v.(cachedIndex)
+	if !ok {
+		return cachedIndex{}, false, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", cachedIndex{}, v)
+	}
+
+	return i, true, nil
+}
+
+// fetchIndex requests the index of the Helm repository and caches it. A
+// cached index is requested conditionally. It returns true if the index was
+// downloaded.
+func (r *Resource) fetchIndex(ctx context.Context, client *http.Client, storageURL string) (cachedIndex, bool, error) {
+	indexURL := fmt.Sprintf("%s/index.yaml", strings.TrimRight(storageURL, "/"))
+
+	r.logger.Debugf(ctx, "getting index.yaml from %#q", indexURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, indexURL, nil)
+	if err != nil {
+		return cachedIndex{}, false, microerror.Mask(err)
+	}
+
+	cached, ok, err := r.getCached(storageURL)
+	if err != nil {
+		return cachedIndex{}, false, microerror.Mask(err)
+	}
+	if ok {
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	// We use https in catalog URLs so we can disable the linter in this case.
+	resp, err := client.Do(req) // #nosec
+	if err != nil {
+		return cachedIndex{}, false, microerror.Mask(err)
+	}
+	defer resp.Body.Close()
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		r.logger.Debugf(ctx, "index.yaml from %#q is not modified", indexURL)
+
+		cached.checked = time.Now()
+		r.cache.SetDefault(storageURL, cached)
+
+		return cached, false, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return cachedIndex{}, false, microerror.Maskf(executionFailedError, "expected status code %d for %#q, got %d", http.StatusOK, indexURL, resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return cachedIndex{}, false, microerror.Mask(err)
+	}
+
+	var i Index
+
+	err = yaml.Unmarshal(body, &i)
+	if err != nil {
+		return cachedIndex{}, false, microerror.Mask(err)
+	}
+
+	cached = cachedIndex{
+		checked:      time.Now(),
+		etag:         resp.Header.Get("ETag"),
+		index:        i,
+		lastModified: resp.Header.Get("Last-Modified"),
+	}
+	r.cache.SetDefault(storageURL, cached)
+
+	r.logger.Debugf(ctx, "got index.yaml from %#q", indexURL)
+
+	return cached, true, nil
+}
+
+func (i cachedIndex) validator() string {
+	if i.etag == "" && i.lastModified == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s/%s", i.etag, i.lastModified)
+}
+
+// entry returns the entry of the chart version if it has URLs.
+func (i Index) entry(name, version string) (Entry, bool) {
+	for _, e := range i.Entries[name] {
+		if e.Version == version && len(e.URLs) > 0 {
+			return e, true
+		}
+	}
+
+	return Entry{}, false
+}
+
+// ResolveURL resolves a chart URL from the index against the repository URL
+// like Helm does. Absolute URLs, e.g. on a CDN host, are returned unchanged.
+func ResolveURL(storageURL, chartURL string) (string, error) {
+	ref, err := url.Parse(chartURL)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	if ref.IsAbs() {
+		return chartURL, nil
+	}
+
+	// The repository URL is a directory so relative URLs must be resolved
+	// below it and not next to it.
+	base, err := url.Parse(strings.TrimRight(storageURL, "/") + "/")
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return base.ResolveReference(ref).String(), nil
+}
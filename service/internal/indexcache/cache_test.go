@@ -0,0 +1,329 @@
+package indexcache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+)
+
+func Test_ResolveURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		storageURL  string
+		chartURL    string
+		expectedURL string
+	}{
+		{
+			name:        "case 0: relative URL",
+			storageURL:  "https://giantswarm.github.io/app-catalog/",
+			chartURL:    "prometheus-1.0.0.tgz",
+			expectedURL: "https://giantswarm.github.io/app-catalog/prometheus-1.0.0.tgz",
+		},
+		{
+			name:        "case 1: relative URL in subdirectory without trailing slash",
+			storageURL:  "https://giantswarm.github.io/app-catalog",
+			chartURL:    "charts/prometheus-1.0.0.tgz",
+			expectedURL: "https://giantswarm.github.io/app-catalog/charts/prometheus-1.0.0.tgz",
+		},
+		{
+			name:        "case 2: absolute URL on CDN host",
+			storageURL:  "https://giantswarm.github.io/app-catalog/",
+			chartURL:    "https://cdn.example.com/charts/prometheus-1.0.0.tgz",
+			expectedURL: "https://cdn.example.com/charts/prometheus-1.0.0.tgz",
+		},
+		{
+			name:        "case 3: host relative URL",
+			storageURL:  "https://giantswarm.github.io/app-catalog/",
+			chartURL:    "/releases/prometheus-1.0.0.tgz",
+			expectedURL: "https://giantswarm.github.io/releases/prometheus-1.0.0.tgz",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			u, err := ResolveURL(tc.storageURL, tc.chartURL)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			if u != tc.expectedURL {
+				t.Fatalf("url == %#q, want %#q", u, tc.expectedURL)
+			}
+		})
+	}
+}
+
+func Test_Resource_TarballURL(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `entries:
+  prometheus:
+  - name: prometheus
+    version: 1.0.0
+    urls:
+    - https://cdn.example.com/prometheus-1.0.0.tgz
+  kiam:
+  - name: kiam
+    version: 2.0.0
+    urls:
+    - charts/kiam-2.0.0.tgz
+`)
+	}))
+	defer server.Close()
+
+	r, err := New(Config{Logger: microloggertest.New()})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	tests := []struct {
+		name        string
+		chartName   string
+		version     string
+		expectedURL string
+	}{
+		{
+			name:        "case 0: absolute URL",
+			chartName:   "prometheus",
+			version:     "1.0.0",
+			expectedURL: "https://cdn.example.com/prometheus-1.0.0.tgz",
+		},
+		{
+			name:        "case 1: relative URL",
+			chartName:   "kiam",
+			version:     "2.0.0",
+			expectedURL: server.URL + "/charts/kiam-2.0.0.tgz",
+		},
+		{
+			name:        "case 2: missing entry falls back to convention",
+			chartName:   "kiam",
+			version:     "3.0.0",
+			expectedURL: server.URL + "/kiam-3.0.0.tgz",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			u, err := r.TarballURL(context.Background(), server.Client(), server.URL, tc.chartName, tc.version)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			if u != tc.expectedURL {
+				t.Fatalf("url == %#q, want %#q", u, tc.expectedURL)
+			}
+		})
+	}
+
+	versions, err := r.Versions(context.Background(), server.Client(), server.URL, "kiam")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if len(versions) != 1 || versions[0] != "2.0.0" {
+		t.Fatalf("versions == %v, want [2.0.0]", versions)
+	}
+
+	// The index is not requested again for the missing entry within the
+	// refetch interval.
+	if requests != 1 {
+		t.Fatalf("requests == %d, want 1", requests)
+	}
+}
+
+func Test_Resource_TarballURL_refetch(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `entries:
+  kiam:
+  - name: kiam
+    version: 2.0.0
+    urls:
+    - charts/kiam-2.0.0.tgz
+  - name: kiam
+    version: 2.1.0
+    urls:
+    - charts/kiam-2.1.0.tgz
+`)
+	}))
+	defer server.Close()
+
+	r, err := New(Config{Logger: microloggertest.New()})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	// The cached index was fetched before version 2.1.0 was released.
+	r.Set(server.URL, Index{
+		Entries: map[string][]Entry{
+			"kiam": {
+				{Name: "kiam", Version: "2.0.0", URLs: []string{"charts/kiam-2.0.0.tgz"}},
+			},
+		},
+	})
+
+	for i := 0; i < 2; i++ {
+		u, err := r.TarballURL(context.Background(), server.Client(), server.URL, "kiam", "2.1.0")
+		if err != nil {
+			t.Fatalf("error == %#v, want nil", err)
+		}
+
+		expectedURL := server.URL + "/charts/kiam-2.1.0.tgz"
+		if u != expectedURL {
+			t.Fatalf("url == %#q, want %#q", u, expectedURL)
+		}
+	}
+
+	// The refetched index is cached and a version that is still missing does
+	// not request it again.
+	u, err := r.TarballURL(context.Background(), server.Client(), server.URL, "kiam", "2.2.0")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if u != server.URL+"/kiam-2.2.0.tgz" {
+		t.Fatalf("url == %#q, want %#q", u, server.URL+"/kiam-2.2.0.tgz")
+	}
+	if requests != 1 {
+		t.Fatalf("requests == %d, want 1", requests)
+	}
+}
+
+func Test_Resource_Revalidate(t *testing.T) {
+	var downloads, requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		downloads++
+
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, "entries:\n  prometheus:\n  - name: prometheus\n    version: 1.0.0\n    urls:\n    - prometheus-1.0.0.tgz\ngenerated: \"2021-09-01T10:00:00Z\"\n")
+	}))
+	defer server.Close()
+
+	r, err := New(Config{Logger: microloggertest.New()})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		index, validator, modified, err := r.Revalidate(ctx, server.Client(), server.URL)
+		if err != nil {
+			t.Fatalf("error == %#v, want nil", err)
+		}
+		if len(index.Entries["prometheus"]) != 1 {
+			t.Fatalf("entries == %d, want 1", len(index.Entries["prometheus"]))
+		}
+		if validator == "" {
+			t.Fatalf("validator == %#q, want non-empty", validator)
+		}
+		if modified != (i == 0) {
+			t.Fatalf("modified == %t, want %t", modified, i == 0)
+		}
+	}
+
+	if downloads != 1 {
+		t.Fatalf("downloads == %d, want 1", downloads)
+	}
+
+	// The revalidated index is used by the app controller without requesting
+	// it again, also for a missing version within the refetch interval.
+	_, err = r.TarballURL(ctx, server.Client(), server.URL, "prometheus", "1.0.0")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	_, err = r.Digest(ctx, server.Client(), server.URL, "prometheus", "1.1.0")
+	if !IsNotFound(err) {
+		t.Fatalf("error == %#v, want not found error", err)
+	}
+	if requests != 2 {
+		t.Fatalf("requests == %d, want 2", requests)
+	}
+
+	// After the refetch interval the missing version requests the index
+	// again conditionally.
+	cached, _, err := r.getCached(server.URL)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	cached.checked = time.Now().Add(-refetchInterval)
+	r.cache.SetDefault(server.URL, cached)
+
+	_, err = r.Digest(ctx, server.Client(), server.URL, "prometheus", "1.1.0")
+	if !IsNotFound(err) {
+		t.Fatalf("error == %#v, want not found error", err)
+	}
+	if requests != 3 || downloads != 1 {
+		t.Fatalf("requests == %d and downloads == %d, want 3 and 1", requests, downloads)
+	}
+}
+
+func Test_Resource_Digest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `entries:
+  kiam:
+  - digest: 4a6f7b1e1a9f2e51b4b3d4a2c1e5f8a7b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4
+    name: kiam
+    version: 2.0.0
+    urls:
+    - charts/kiam-2.0.0.tgz
+`)
+	}))
+	defer server.Close()
+
+	r, err := New(Config{Logger: microloggertest.New()})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	tests := []struct {
+		name           string
+		version        string
+		expectedDigest string
+		errorMatcher   func(error) bool
+	}{
+		{
+			name:           "case 0: digest of chart version",
+			version:        "2.0.0",
+			expectedDigest: "4a6f7b1e1a9f2e51b4b3d4a2c1e5f8a7b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4",
+		},
+		{
+			name:         "case 1: chart version not found",
+			version:      "2.1.0",
+			errorMatcher: IsNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := r.Digest(context.Background(), server.Client(), server.URL, "kiam", tc.version)
+			switch {
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case err != nil && !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if d != tc.expectedDigest {
+				t.Fatalf("digest == %#q, want %#q", d, tc.expectedDigest)
+			}
+		})
+	}
+}
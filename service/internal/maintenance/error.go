@@ -0,0 +1,30 @@
+package maintenance
+
+import "github.com/giantswarm/microerror"
+
+var invalidWindowError = &microerror.Error{
+	Kind: "invalidWindowError",
+}
+
+// IsInvalidWindow asserts invalidWindowError.
+func IsInvalidWindow(err error) bool {
+	return microerror.Cause(err) == invalidWindowError
+}
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var wrongTypeError = &microerror.Error{
+	Kind: "wrongTypeError",
+}
+
+// IsWrongType asserts wrongTypeError.
+func IsWrongType(err error) bool {
+	return microerror.Cause(err) == wrongTypeError
+}
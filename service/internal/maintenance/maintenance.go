@@ -0,0 +1,175 @@
+// Package maintenance holds back chart changes of apps outside their
+// maintenance window. Windows are configured on the app CR or its namespace.
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	// The time zones of windows must be loaded in the alpine image, which has
+	// no zoneinfo files.
+	_ "time/tzdata"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/microerror"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/robfig/cron/v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+)
+
+// Window is a recurring maintenance window. It opens at each activation of
+// its schedule and stays open for its duration.
+type Window struct {
+	// Schedule is a standard 5 field cron schedule, e.g. `0 22 * * 1-5`.
+	Schedule string `json:"schedule"`
+	// Duration is how long the window stays open, e.g. 4h.
+	Duration string `json:"duration"`
+	// TimeZone is the IANA time zone of the schedule. It defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+	// ExemptConfigChanges applies changes that do not change the version of
+	// the chart outside the window.
+	ExemptConfigChanges bool `json:"exemptConfigChanges,omitempty"`
+
+	duration time.Duration
+	location *time.Location
+	schedule cron.Schedule
+}
+
+const (
+	// namespaceExpiration is how long the maintenance window of a namespace
+	// is cached so the namespace is not requested on every reconciliation.
+	namespaceExpiration = 1 * time.Minute
+)
+
+type Config struct {
+	// Dependencies.
+	K8sClient kubernetes.Interface
+}
+
+// Resource gets the maintenance windows of app CRs. The windows of namespaces
+// are cached.
+type Resource struct {
+	// Dependencies.
+	cache     *gocache.Cache
+	k8sClient kubernetes.Interface
+}
+
+// namespaceWindow is the cached maintenance window annotation of a namespace.
+type namespaceWindow struct {
+	ok    bool
+	value string
+}
+
+// New creates a new configured maintenance window resource.
+func New(config Config) (*Resource, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+
+	r := &Resource{
+		cache:     gocache.New(namespaceExpiration, namespaceExpiration*2),
+		k8sClient: config.K8sClient,
+	}
+
+	return r, nil
+}
+
+// Get returns the maintenance window of the app CR or else of its namespace.
+// It returns nil if neither has a maintenance window.
+func (r *Resource) Get(ctx context.Context, cr v1alpha1.App) (*Window, error) {
+	value, ok := cr.GetAnnotations()[annotation.AppMaintenanceWindow]
+	if !ok {
+		ns, err := r.getNamespaceWindow(ctx, cr.GetNamespace())
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		if !ns.ok {
+			return nil, nil
+		}
+
+		value = ns.value
+	}
+
+	w, err := Parse(value)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return w, nil
+}
+
+func (r *Resource) getNamespaceWindow(ctx context.Context, name string) (namespaceWindow, error) {
+	if v, ok := r.cache.Get(name); ok {
+		w, ok := v.(namespaceWindow)
+		if !ok {
+			return namespaceWindow{}, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", namespaceWindow{}, v)
+		}
+
+		return w, nil
+	}
+
+	var w namespaceWindow
+
+	ns, err := r.k8sClient.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// no-op
+	} else if err != nil {
+		return namespaceWindow{}, microerror.Mask(err)
+	} else {
+		w.value, w.ok = ns.GetAnnotations()[annotation.AppMaintenanceWindow]
+	}
+
+	r.cache.SetDefault(name, w)
+
+	return w, nil
+}
+
+// Parse parses the JSON value of the maintenance window annotation.
+func Parse(value string) (*Window, error) {
+	var w Window
+
+	err := json.Unmarshal([]byte(value), &w)
+	if err != nil {
+		return nil, microerror.Maskf(invalidWindowError, "failed to parse annotation %#q, logs: %s", annotation.AppMaintenanceWindow, err.Error())
+	}
+
+	w.schedule, err = cron.ParseStandard(w.Schedule)
+	if err != nil {
+		return nil, microerror.Maskf(invalidWindowError, "invalid schedule %#q, logs: %s", w.Schedule, err.Error())
+	}
+
+	w.duration, err = time.ParseDuration(w.Duration)
+	if err != nil {
+		return nil, microerror.Maskf(invalidWindowError, "invalid duration %#q, logs: %s", w.Duration, err.Error())
+	}
+	if w.duration <= 0 {
+		return nil, microerror.Maskf(invalidWindowError, "duration %#q must be positive", w.Duration)
+	}
+
+	w.location = time.UTC
+	if w.TimeZone != "" {
+		w.location, err = time.LoadLocation(w.TimeZone)
+		if err != nil {
+			return nil, microerror.Maskf(invalidWindowError, "invalid time zone %#q, logs: %s", w.TimeZone, err.Error())
+		}
+	}
+
+	return &w, nil
+}
+
+// IsOpen returns true if the window is open at the given time.
+func (w *Window) IsOpen(now time.Time) bool {
+	return !w.NextOpen(now).After(now)
+}
+
+// NextOpen returns the time the window opens next. It returns the opening
+// time of the current window if the window is open.
+func (w *Window) NextOpen(now time.Time) time.Time {
+	// The first activation after now - duration is either within the
+	// current window or the next one.
+	return w.schedule.Next(now.Add(-w.duration).In(w.location))
+}
@@ -0,0 +1,204 @@
+package maintenance
+
+import (
+	"context"
+	"go/build"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgofake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+)
+
+func Test_Window(t *testing.T) {
+	tests := []struct {
+		name             string
+		value            string
+		now              string
+		expectedOpen     bool
+		expectedNextOpen string
+		errorMatcher     func(error) bool
+	}{
+		{
+			name:             "case 0: inside window",
+			value:            `{"schedule": "0 22 * * 1-5", "duration": "4h"}`,
+			now:              "2021-09-01T23:30:00Z",
+			expectedOpen:     true,
+			expectedNextOpen: "2021-09-01T22:00:00Z",
+		},
+		{
+			name:             "case 1: window spans midnight",
+			value:            `{"schedule": "0 22 * * 1-5", "duration": "4h"}`,
+			now:              "2021-09-02T01:59:00Z",
+			expectedOpen:     true,
+			expectedNextOpen: "2021-09-01T22:00:00Z",
+		},
+		{
+			name:             "case 2: outside window",
+			value:            `{"schedule": "0 22 * * 1-5", "duration": "4h"}`,
+			now:              "2021-09-02T02:00:00Z",
+			expectedOpen:     false,
+			expectedNextOpen: "2021-09-02T22:00:00Z",
+		},
+		{
+			name:             "case 3: outside window on the weekend",
+			value:            `{"schedule": "0 22 * * 1-5", "duration": "4h"}`,
+			now:              "2021-09-04T23:00:00Z",
+			expectedOpen:     false,
+			expectedNextOpen: "2021-09-06T22:00:00Z",
+		},
+		{
+			name:             "case 4: time zone",
+			value:            `{"schedule": "0 22 * * *", "duration": "1h", "timeZone": "Europe/Berlin"}`,
+			now:              "2021-09-01T20:30:00Z",
+			expectedOpen:     true,
+			expectedNextOpen: "2021-09-01T20:00:00Z",
+		},
+		{
+			name:         "case 5: invalid schedule",
+			value:        `{"schedule": "every night", "duration": "1h"}`,
+			errorMatcher: IsInvalidWindow,
+		},
+		{
+			name:         "case 6: invalid time zone",
+			value:        `{"schedule": "0 22 * * *", "duration": "1h", "timeZone": "Mars/Olympus"}`,
+			errorMatcher: IsInvalidWindow,
+		},
+		{
+			name:         "case 7: missing duration",
+			value:        `{"schedule": "0 22 * * *"}`,
+			errorMatcher: IsInvalidWindow,
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			w, err := Parse(tc.value)
+			switch {
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case err != nil && !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.errorMatcher != nil {
+				return
+			}
+
+			now, err := time.Parse(time.RFC3339, tc.now)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			if w.IsOpen(now) != tc.expectedOpen {
+				t.Fatalf("open == %t, want %t", w.IsOpen(now), tc.expectedOpen)
+			}
+
+			nextOpen := w.NextOpen(now).UTC().Format(time.RFC3339)
+			if nextOpen != tc.expectedNextOpen {
+				t.Fatalf("next open == %#q, want %#q", nextOpen, tc.expectedNextOpen)
+			}
+		})
+	}
+}
+
+func Test_Get(t *testing.T) {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				annotation.AppMaintenanceWindow: `{"schedule": "0 1 * * *", "duration": "2h"}`,
+			},
+			Name: "5xchu",
+		},
+	}
+	k8sClient := clientgofake.NewSimpleClientset(ns)
+
+	app := v1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "prometheus",
+			Namespace: "5xchu",
+		},
+	}
+
+	r, err := New(Config{K8sClient: k8sClient})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	w, err := r.Get(context.Background(), app)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if w == nil || w.Schedule != "0 1 * * *" {
+		t.Fatalf("window == %#v, want namespace window", w)
+	}
+
+	app.Annotations = map[string]string{
+		annotation.AppMaintenanceWindow: `{"schedule": "0 3 * * *", "duration": "1h", "exemptConfigChanges": true}`,
+	}
+
+	w, err = r.Get(context.Background(), app)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if w == nil || w.Schedule != "0 3 * * *" || !w.ExemptConfigChanges {
+		t.Fatalf("window == %#v, want app window", w)
+	}
+
+	app.Namespace = "default"
+	app.Annotations = nil
+
+	w, err = r.Get(context.Background(), app)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if w != nil {
+		t.Fatalf("window == %#v, want nil", w)
+	}
+
+	// The windows of namespaces are cached.
+	app.Namespace = "5xchu"
+	for i := 0; i < 2; i++ {
+		_, err = r.Get(context.Background(), app)
+		if err != nil {
+			t.Fatalf("error == %#v, want nil", err)
+		}
+	}
+
+	var gets int
+	for _, action := range k8sClient.Actions() {
+		if action.GetVerb() == "get" && action.GetResource().Resource == "namespaces" {
+			gets++
+		}
+	}
+	if gets != 2 {
+		t.Fatalf("gets == %d, want %d", gets, 2)
+	}
+}
+
+func Test_tzdata(t *testing.T) {
+	// The image has no zoneinfo files so time zones can only be loaded from
+	// the embedded time zone database. Loading a time zone in this test would
+	// pass with the zoneinfo files of the build host.
+	p, err := build.ImportDir(".", 0)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	for _, i := range p.Imports {
+		if i == "time/tzdata" {
+			return
+		}
+	}
+
+	t.Fatalf("package %#q does not import %#q", p.ImportPath, "time/tzdata")
+}
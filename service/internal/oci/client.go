@@ -0,0 +1,563 @@
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/microerror"
+	gocache "github.com/patrickmn/go-cache"
+
+	"github.com/giantswarm/app-operator/v5/service/internal/helmrepo"
+)
+
+const (
+	// StorageType is the catalog storage type for charts stored in an OCI
+	// registry.
+	StorageType = "oci"
+
+	// Scheme is the URL scheme used for OCI catalog storage URLs and chart
+	// references, e.g. oci://ghcr.io/giantswarm/charts.
+	Scheme = "oci://"
+
+	chartConfigMediaType  = "application/vnd.cncf.helm.config.v1+json"
+	createdAnnotation     = "org.opencontainers.image.created"
+	digestHeader          = "Docker-Content-Digest"
+	manifestMediaType     = "application/vnd.oci.image.manifest.v1+json"
+	pageSize              = 1000
+	defaultRequestTimeout = 30 * time.Second
+
+	// chartExpiration is long because the manifest and config blob of a
+	// digest do not change.
+	chartExpiration = 24 * time.Hour
+	// defaultTokenExpiration is used for tokens without expires_in as
+	// defined by the distribution token spec.
+	defaultTokenExpiration = 60 * time.Second
+	// tokenExpirationMargin is subtracted from the expiration of tokens so
+	// they are not used when they are about to expire.
+	tokenExpirationMargin = 10 * time.Second
+)
+
+var (
+	challengeParamRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+	linkNextRegexp       = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+)
+
+// Config represents the configuration used to create a new OCI client.
+type Config struct {
+	// HTTPClient is optional. A client with a default timeout is used when it
+	// is not set.
+	HTTPClient *http.Client
+}
+
+// Client lists and inspects Helm charts stored in an OCI registry using the
+// distribution API. Bearer tokens are reused until they expire and charts are
+// cached by manifest digest so unchanged chart versions only cost a HEAD
+// request.
+type Client struct {
+	charts     *gocache.Cache
+	httpClient *http.Client
+	tokens     *gocache.Cache
+}
+
+// Chart holds the information of a single chart version in the registry.
+type Chart struct {
+	Created  time.Time
+	Digest   string
+	Metadata ChartMetadata
+}
+
+// New creates a new configured OCI client.
+func New(config Config) (*Client, error) {
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{
+			Timeout: defaultRequestTimeout,
+		}
+	}
+
+	c := &Client{
+		charts:     gocache.New(chartExpiration, chartExpiration/2),
+		httpClient: config.HTTPClient,
+		tokens:     gocache.New(defaultTokenExpiration, defaultTokenExpiration),
+	}
+
+	return c, nil
+}
+
+// IsOCIStorage returns true if the catalog stores its charts in an OCI
+// registry.
+func IsOCIStorage(catalog v1alpha1.Catalog) bool {
+	return catalog.Spec.Storage.Type == StorageType || strings.HasPrefix(catalog.Spec.Storage.URL, Scheme)
+}
+
+// ChartReference returns the OCI reference of a chart version, e.g.
+// oci://ghcr.io/giantswarm/charts/prometheus:1.0.0.
+func ChartReference(storageURL, name, version string) (string, error) {
+	host, path, err := parseStorageURL(storageURL)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	if name == "" || version == "" {
+		return "", microerror.Maskf(invalidURLError, "chart name and version must not be empty")
+	}
+
+	return fmt.Sprintf("%s%s/%s:%s", Scheme, host, repository(path, name), toTag(version)), nil
+}
+
+// ListCharts returns the names of the chart repositories below the path of
+// the storage URL. Registries like GHCR, ECR and Docker Hub do not support
+// listing repositories, a catalogNotSupportedError is returned for them.
+func (c *Client) ListCharts(ctx context.Context, credentials *helmrepo.Credentials, storageURL string) ([]string, error) {
+	host, path, err := parseStorageURL(storageURL)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	prefix := ""
+	if path != "" {
+		prefix = path + "/"
+	}
+
+	var names []string
+
+	next := fmt.Sprintf("https://%s/v2/_catalog?n=%d", host, pageSize)
+	for next != "" {
+		var res catalogResponse
+
+		next, err = c.getJSON(ctx, credentials, "", next, "", &res)
+		if IsNotFound(err) || IsUnauthorized(err) {
+			return nil, microerror.Maskf(catalogNotSupportedError, "registry %#q does not support listing repositories: %s", host, err.Error())
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for _, repo := range res.Repositories {
+			if !strings.HasPrefix(repo, prefix) {
+				continue
+			}
+
+			name := strings.TrimPrefix(repo, prefix)
+			if name == "" || strings.Contains(name, "/") {
+				// Nested repositories are not charts of this catalog.
+				continue
+			}
+
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// ListVersions returns the chart versions published for the chart. Tags are
+// converted back to semver since Helm replaces + with _ when pushing.
+func (c *Client) ListVersions(ctx context.Context, credentials *helmrepo.Credentials, storageURL, name string) ([]string, error) {
+	host, path, err := parseStorageURL(storageURL)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	repo := repository(path, name)
+
+	var versions []string
+
+	next := fmt.Sprintf("https://%s/v2/%s/tags/list?n=%d", host, repo, pageSize)
+	for next != "" {
+		var res tagsResponse
+
+		next, err = c.getJSON(ctx, credentials, repo, next, "", &res)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for _, tag := range res.Tags {
+			versions = append(versions, strings.Replace(tag, "_", "+", -1))
+		}
+	}
+
+	return versions, nil
+}
+
+// GetChart fetches the manifest and the chart metadata stored in the config
+// blob of a chart version. The digest of the tag is resolved with a HEAD
+// request first so charts of known digests are not fetched again.
+func (c *Client) GetChart(ctx context.Context, credentials *helmrepo.Credentials, storageURL, name, version string) (Chart, error) {
+	host, path, err := parseStorageURL(storageURL)
+	if err != nil {
+		return Chart{}, microerror.Mask(err)
+	}
+
+	repo := repository(path, name)
+	u := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, repo, toTag(version))
+
+	manifestDigest, err := c.headDigest(ctx, credentials, repo, u)
+	if err != nil {
+		return Chart{}, microerror.Mask(err)
+	}
+	if manifestDigest != "" {
+		if v, ok := c.charts.Get(chartKey(host, repo, manifestDigest)); ok {
+			return v.(Chart), nil
+		}
+	}
+
+	var m manifest
+	{
+		resp, err := c.get(ctx, credentials, repo, http.MethodGet, u, manifestMediaType)
+		if err != nil {
+			return Chart{}, microerror.Mask(err)
+		}
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return Chart{}, microerror.Mask(err)
+		}
+
+		err = json.Unmarshal(body, &m)
+		if err != nil {
+			return Chart{}, microerror.Mask(err)
+		}
+
+		// The digest of the manifest is the digest of its content if the
+		// registry does not return it.
+		manifestDigest = resp.Header.Get(digestHeader)
+		if manifestDigest == "" {
+			manifestDigest = fmt.Sprintf("sha256:%x", sha256.Sum256(body))
+		}
+	}
+
+	if m.Config.MediaType != chartConfigMediaType {
+		return Chart{}, microerror.Maskf(notFoundError, "%s/%s:%s is not a helm chart, config media type is %#q", host, repo, version, m.Config.MediaType)
+	}
+
+	var metadata ChartMetadata
+	{
+		u := fmt.Sprintf("https://%s/v2/%s/blobs/%s", host, repo, m.Config.Digest)
+
+		_, err = c.getJSON(ctx, credentials, repo, u, chartConfigMediaType, &metadata)
+		if err != nil {
+			return Chart{}, microerror.Mask(err)
+		}
+	}
+
+	chart := Chart{
+		Metadata: metadata,
+	}
+
+	if created, ok := m.Annotations[createdAnnotation]; ok {
+		t, err := time.Parse(time.RFC3339, created)
+		if err == nil {
+			chart.Created = t
+		}
+	}
+
+	for _, l := range m.Layers {
+		chart.Digest = l.Digest
+		break
+	}
+
+	c.charts.SetDefault(chartKey(host, repo, manifestDigest), chart)
+
+	return chart, nil
+}
+
+// headDigest returns the digest of the manifest from a HEAD request. It
+// returns an empty string if the registry does not return the digest.
+func (c *Client) headDigest(ctx context.Context, credentials *helmrepo.Credentials, repo, u string) (string, error) {
+	resp, err := c.get(ctx, credentials, repo, http.MethodHead, u, manifestMediaType)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	resp.Body.Close()
+
+	return resp.Header.Get(digestHeader), nil
+}
+
+// getJSON decodes the JSON response of the URL into v. It returns the URL of
+// the next page when the registry paginates the response.
+func (c *Client) getJSON(ctx context.Context, credentials *helmrepo.Credentials, repo, u, accept string, v interface{}) (string, error) {
+	resp, err := c.get(ctx, credentials, repo, http.MethodGet, u, accept)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	err = json.Unmarshal(body, v)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	var next string
+	{
+		matches := linkNextRegexp.FindStringSubmatch(resp.Header.Get("Link"))
+		if len(matches) == 2 {
+			nextURL, err := resp.Request.URL.Parse(matches[1])
+			if err != nil {
+				return "", microerror.Mask(err)
+			}
+
+			next = nextURL.String()
+		}
+	}
+
+	return next, nil
+}
+
+// get performs the request with the cached token of the repository. When the
+// registry challenges the request it is retried once with a new token or,
+// for registries using basic auth, with the credentials.
+func (c *Client) get(ctx context.Context, credentials *helmrepo.Credentials, repo, method, u, accept string) (*http.Response, error) {
+	if credentials != nil && (len(credentials.CA) > 0 || len(credentials.Cert) > 0) {
+		return nil, microerror.Maskf(invalidConfigError, "TLS credentials are not supported for OCI registries")
+	}
+
+	k, err := tokenKey(credentials, repo, u)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	auth := bearerAuth(credentials)
+	if auth == "" {
+		if v, ok := c.tokens.Get(k); ok {
+			auth = v.(string)
+		}
+	}
+
+	resp, err := c.do(ctx, method, u, accept, auth)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && bearerAuth(credentials) == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+
+		c.tokens.Delete(k)
+
+		auth, err = c.authenticate(ctx, credentials, k, challenge)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		resp, err = c.do(ctx, method, u, accept, auth)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, microerror.Maskf(notFoundError, "%#q", u)
+	} else if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		resp.Body.Close()
+		return nil, microerror.Maskf(unauthorizedError, "got status code %d for %#q", resp.StatusCode, u)
+	} else if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, microerror.Maskf(executionFailedError, "expected status code %d for %#q, got %d", http.StatusOK, u, resp.StatusCode)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) do(ctx context.Context, method, u, accept, auth string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return resp, nil
+}
+
+// authenticate returns the Authorization header for the challenge of the
+// registry. Bearer tokens are requested from the realm of the challenge with
+// the credentials, or anonymously without, and cached with the key until
+// they expire. Basic auth is cached until the registry challenges it.
+func (c *Client) authenticate(ctx context.Context, credentials *helmrepo.Credentials, k, challenge string) (string, error) {
+	if strings.HasPrefix(strings.ToLower(challenge), "basic ") {
+		if !hasBasicAuth(credentials) {
+			return "", microerror.Maskf(unauthorizedError, "registry requires basic auth credentials")
+		}
+
+		auth := fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(credentials.Username+":"+credentials.Password)))
+		c.tokens.Set(k, auth, gocache.NoExpiration)
+
+		return auth, nil
+	}
+
+	token, expiration, err := c.getToken(ctx, credentials, challenge)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	auth := fmt.Sprintf("Bearer %s", token)
+	c.tokens.Set(k, auth, expiration)
+
+	return auth, nil
+}
+
+// getToken requests a bearer token from the realm announced in the
+// WWW-Authenticate challenge of the registry. It returns the token and how
+// long it may be cached.
+func (c *Client) getToken(ctx context.Context, credentials *helmrepo.Credentials, challenge string) (string, time.Duration, error) {
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return "", 0, microerror.Maskf(executionFailedError, "unsupported authentication challenge %#q", challenge)
+	}
+
+	params := map[string]string{}
+	for _, m := range challengeParamRegexp.FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2]
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", 0, microerror.Maskf(executionFailedError, "invalid realm in authentication challenge %#q", challenge)
+	}
+
+	q := realm.Query()
+	for _, k := range []string{"service", "scope"} {
+		if params[k] != "" {
+			q.Set(k, params[k])
+		}
+	}
+	realm.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", 0, microerror.Mask(err)
+	}
+	if hasBasicAuth(credentials) {
+		req.SetBasicAuth(credentials.Username, credentials.Password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", 0, microerror.Mask(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return "", 0, microerror.Maskf(unauthorizedError, "got status code %d for token request", resp.StatusCode)
+	} else if resp.StatusCode != http.StatusOK {
+		return "", 0, microerror.Maskf(executionFailedError, "expected status code %d for token request, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", 0, microerror.Mask(err)
+	}
+
+	var t tokenResponse
+	err = json.Unmarshal(body, &t)
+	if err != nil {
+		return "", 0, microerror.Mask(err)
+	}
+
+	expiration := defaultTokenExpiration
+	if t.ExpiresIn > 0 {
+		expiration = time.Duration(t.ExpiresIn) * time.Second
+	}
+	if expiration > tokenExpirationMargin {
+		expiration -= tokenExpirationMargin
+	}
+
+	if t.Token != "" {
+		return t.Token, expiration, nil
+	}
+
+	return t.AccessToken, expiration, nil
+}
+
+// parseStorageURL splits an oci:// storage URL into the registry host and
+// the repository path.
+func parseStorageURL(storageURL string) (string, string, error) {
+	if !strings.HasPrefix(storageURL, Scheme) {
+		return "", "", microerror.Maskf(invalidURLError, "expected %#q prefix for storage URL %#q", Scheme, storageURL)
+	}
+
+	trimmed := strings.Trim(strings.TrimPrefix(storageURL, Scheme), "/")
+	parts := strings.SplitN(trimmed, "/", 2)
+	if parts[0] == "" {
+		return "", "", microerror.Maskf(invalidURLError, "storage URL %#q has no registry host", storageURL)
+	}
+
+	var path string
+	if len(parts) == 2 {
+		path = parts[1]
+	}
+
+	return parts[0], path, nil
+}
+
+// bearerAuth returns the Authorization header for a token of the
+// credentials. The token is sent as is and not exchanged.
+func bearerAuth(credentials *helmrepo.Credentials) string {
+	if credentials == nil || credentials.Token == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("Bearer %s", credentials.Token)
+}
+
+func chartKey(host, repo, manifestDigest string) string {
+	return fmt.Sprintf("%s/%s@%s", host, repo, manifestDigest)
+}
+
+func hasBasicAuth(credentials *helmrepo.Credentials) bool {
+	return credentials != nil && (credentials.Username != "" || credentials.Password != "")
+}
+
+func repository(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s/%s", path, name)
+}
+
+// toTag converts a chart version to an OCI tag. Tags may not contain + so
+// Helm replaces it with _.
+func toTag(version string) string {
+	return strings.Replace(version, "+", "_", -1)
+}
+
+// tokenKey identifies the token for requests to the repository. Tokens are
+// scoped to a repository, or the registry catalog for an empty repository,
+// and are not shared between users.
+func tokenKey(credentials *helmrepo.Credentials, repo, u string) (string, error) {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	var username string
+	if credentials != nil {
+		username = credentials.Username
+	}
+
+	return fmt.Sprintf("%s/%s/%s", parsed.Host, repo, username), nil
+}
@@ -0,0 +1,244 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/giantswarm/app-operator/v5/service/internal/helmrepo"
+)
+
+// requests counts the requests of the registry stand-in.
+type requests struct {
+	catalog   int
+	manifests int
+	tokens    int
+}
+
+// newRegistry returns an in-process stand-in for an OCI registry that
+// requires a bearer token like most public registries. With a username the
+// token is only issued for matching basic auth credentials, otherwise
+// anonymously. Without catalog support listing repositories is forbidden like
+// on GHCR.
+func newRegistry(username string, catalog bool, r *requests) *httptest.Server {
+	mux := http.NewServeMux()
+
+	var server *httptest.Server
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, req *http.Request) {
+		r.tokens++
+		if username != "" {
+			u, _, ok := req.BasicAuth()
+			if !ok || u != username {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		fmt.Fprint(w, `{"token": "valid", "expires_in": 300}`)
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer valid" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:charts:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case req.URL.Path == "/v2/_catalog" && !catalog:
+			r.catalog++
+			w.WriteHeader(http.StatusForbidden)
+		case req.URL.Path == "/v2/_catalog" && req.URL.Query().Get("last") == "":
+			r.catalog++
+			w.Header().Set("Link", `</v2/_catalog?n=1000&last=giantswarm/charts/kiam>; rel="next"`)
+			fmt.Fprint(w, `{"repositories": ["giantswarm/charts/kiam", "giantswarm/other/app"]}`)
+		case req.URL.Path == "/v2/_catalog":
+			r.catalog++
+			fmt.Fprint(w, `{"repositories": ["giantswarm/charts/prometheus", "giantswarm/charts/nested/app"]}`)
+		case req.URL.Path == "/v2/giantswarm/charts/prometheus/tags/list":
+			fmt.Fprint(w, `{"name": "giantswarm/charts/prometheus", "tags": ["1.0.0", "1.1.0_build.1", "latest"]}`)
+		case req.URL.Path == "/v2/giantswarm/charts/prometheus/manifests/1.1.0_build.1":
+			w.Header().Set("Docker-Content-Digest", "sha256:manifest")
+			if req.Method == http.MethodHead {
+				return
+			}
+			r.manifests++
+			fmt.Fprint(w, `{
+				"annotations": {"org.opencontainers.image.created": "2021-09-01T10:00:00Z"},
+				"config": {"mediaType": "application/vnd.cncf.helm.config.v1+json", "digest": "sha256:config"},
+				"layers": [{"mediaType": "application/vnd.cncf.helm.chart.content.v1.tar+gzip", "digest": "sha256:chart"}]
+			}`)
+		case req.URL.Path == "/v2/giantswarm/charts/prometheus/blobs/sha256:config":
+			fmt.Fprint(w, `{"name": "prometheus", "version": "1.1.0+build.1", "appVersion": "2.30.0", "description": "Prometheus", "keywords": ["monitoring"]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	server = httptest.NewTLSServer(mux)
+
+	return server
+}
+
+func Test_Client(t *testing.T) {
+	var r requests
+
+	server := newRegistry("", true, &r)
+	defer server.Close()
+
+	c, err := New(Config{HTTPClient: server.Client()})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	storageURL := fmt.Sprintf("oci://%s/giantswarm/charts", strings.TrimPrefix(server.URL, "https://"))
+	ctx := context.Background()
+
+	names, err := c.ListCharts(ctx, nil, storageURL)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if !reflect.DeepEqual(names, []string{"kiam", "prometheus"}) {
+		t.Fatalf("want matching charts \n %s", cmp.Diff(names, []string{"kiam", "prometheus"}))
+	}
+
+	versions, err := c.ListVersions(ctx, nil, storageURL, "prometheus")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if !reflect.DeepEqual(versions, []string{"1.0.0", "1.1.0+build.1", "latest"}) {
+		t.Fatalf("want matching versions \n %s", cmp.Diff(versions, []string{"1.0.0", "1.1.0+build.1", "latest"}))
+	}
+
+	expectedChart := Chart{
+		Created: time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC),
+		Digest:  "sha256:chart",
+		Metadata: ChartMetadata{
+			AppVersion:  "2.30.0",
+			Description: "Prometheus",
+			Keywords:    []string{"monitoring"},
+			Name:        "prometheus",
+			Version:     "1.1.0+build.1",
+		},
+	}
+
+	// The chart is fetched once and then served from the cache by the
+	// digest of the manifest.
+	for i := 0; i < 2; i++ {
+		chart, err := c.GetChart(ctx, nil, storageURL, "prometheus", "1.1.0+build.1")
+		if err != nil {
+			t.Fatalf("error == %#v, want nil", err)
+		}
+		if !reflect.DeepEqual(chart, expectedChart) {
+			t.Fatalf("want matching chart \n %s", cmp.Diff(chart, expectedChart))
+		}
+	}
+	if r.manifests != 1 {
+		t.Fatalf("manifest requests == %d, want %d", r.manifests, 1)
+	}
+
+	_, err = c.GetChart(ctx, nil, storageURL, "prometheus", "1.0.0")
+	if !IsNotFound(err) {
+		t.Fatalf("error == %#v, want notFoundError", err)
+	}
+
+	// One token for the catalog and one for the chart repository.
+	if r.tokens != 2 {
+		t.Fatalf("token requests == %d, want %d", r.tokens, 2)
+	}
+}
+
+func Test_Client_credentials(t *testing.T) {
+	var r requests
+
+	server := newRegistry("giantswarm", false, &r)
+	defer server.Close()
+
+	c, err := New(Config{HTTPClient: server.Client()})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	storageURL := fmt.Sprintf("oci://%s/giantswarm/charts", strings.TrimPrefix(server.URL, "https://"))
+	ctx := context.Background()
+
+	_, err = c.ListVersions(ctx, nil, storageURL, "prometheus")
+	if !IsUnauthorized(err) {
+		t.Fatalf("error == %#v, want unauthorizedError", err)
+	}
+
+	credentials := &helmrepo.Credentials{
+		Username: "giantswarm",
+		Password: "secret",
+	}
+
+	_, err = c.ListCharts(ctx, credentials, storageURL)
+	if !IsCatalogNotSupported(err) {
+		t.Fatalf("error == %#v, want catalogNotSupportedError", err)
+	}
+
+	versions, err := c.ListVersions(ctx, credentials, storageURL, "prometheus")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if !reflect.DeepEqual(versions, []string{"1.0.0", "1.1.0+build.1", "latest"}) {
+		t.Fatalf("want matching versions \n %s", cmp.Diff(versions, []string{"1.0.0", "1.1.0+build.1", "latest"}))
+	}
+}
+
+func Test_ChartReference(t *testing.T) {
+	tests := []struct {
+		name         string
+		storageURL   string
+		chartName    string
+		version      string
+		expectedRef  string
+		errorMatcher func(error) bool
+	}{
+		{
+			name:        "case 0: registry with path",
+			storageURL:  "oci://ghcr.io/giantswarm/charts/",
+			chartName:   "prometheus",
+			version:     "1.0.0",
+			expectedRef: "oci://ghcr.io/giantswarm/charts/prometheus:1.0.0",
+		},
+		{
+			name:        "case 1: registry without path and build metadata",
+			storageURL:  "oci://localhost:5000",
+			chartName:   "prometheus",
+			version:     "1.0.0+abc",
+			expectedRef: "oci://localhost:5000/prometheus:1.0.0_abc",
+		},
+		{
+			name:         "case 2: not an OCI URL",
+			storageURL:   "https://giantswarm.github.io/app-catalog/",
+			chartName:    "prometheus",
+			version:      "1.0.0",
+			errorMatcher: IsInvalidURL,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ref, err := ChartReference(tc.storageURL, tc.chartName, tc.version)
+			switch {
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case err != nil && !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if ref != tc.expectedRef {
+				t.Fatalf("ref == %#q, want %#q", ref, tc.expectedRef)
+			}
+		})
+	}
+}
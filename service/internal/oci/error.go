@@ -0,0 +1,57 @@
+package oci
+
+import "github.com/giantswarm/microerror"
+
+var catalogNotSupportedError = &microerror.Error{
+	Kind: "catalogNotSupportedError",
+}
+
+// IsCatalogNotSupported asserts catalogNotSupportedError.
+func IsCatalogNotSupported(err error) bool {
+	return microerror.Cause(err) == catalogNotSupportedError
+}
+
+var executionFailedError = &microerror.Error{
+	Kind: "executionFailedError",
+}
+
+// IsExecutionFailed asserts executionFailedError.
+func IsExecutionFailed(err error) bool {
+	return microerror.Cause(err) == executionFailedError
+}
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var invalidURLError = &microerror.Error{
+	Kind: "invalidURLError",
+}
+
+// IsInvalidURL asserts invalidURLError.
+func IsInvalidURL(err error) bool {
+	return microerror.Cause(err) == invalidURLError
+}
+
+var notFoundError = &microerror.Error{
+	Kind: "notFoundError",
+}
+
+// IsNotFound asserts notFoundError.
+func IsNotFound(err error) bool {
+	return microerror.Cause(err) == notFoundError
+}
+
+var unauthorizedError = &microerror.Error{
+	Kind: "unauthorizedError",
+}
+
+// IsUnauthorized asserts unauthorizedError.
+func IsUnauthorized(err error) bool {
+	return microerror.Cause(err) == unauthorizedError
+}
@@ -0,0 +1,42 @@
+package oci
+
+// ChartMetadata is the Chart.yaml content Helm stores as the config blob of
+// an OCI chart artifact.
+type ChartMetadata struct {
+	Annotations map[string]string `json:"annotations"`
+	APIVersion  string            `json:"apiVersion"`
+	AppVersion  string            `json:"appVersion"`
+	Description string            `json:"description"`
+	Home        string            `json:"home"`
+	Icon        string            `json:"icon"`
+	Keywords    []string          `json:"keywords"`
+	Name        string            `json:"name"`
+	Version     string            `json:"version"`
+}
+
+type catalogResponse struct {
+	Repositories []string `json:"repositories"`
+}
+
+type descriptor struct {
+	Digest    string `json:"digest"`
+	MediaType string `json:"mediaType"`
+	Size      int64  `json:"size"`
+}
+
+type manifest struct {
+	Annotations map[string]string `json:"annotations"`
+	Config      descriptor        `json:"config"`
+	Layers      []descriptor      `json:"layers"`
+}
+
+type tagsResponse struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	Token       string `json:"token"`
+}
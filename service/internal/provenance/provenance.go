@@ -0,0 +1,156 @@
+// Package provenance verifies Helm provenance files of charts against a
+// keyring configured for the catalog.
+package provenance
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"path"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/clearsign"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+	"github.com/giantswarm/app-operator/v5/service/internal/digest"
+)
+
+const (
+	// KeyringKey is the key of the keyring in the secret referenced by the
+	// catalog. The keyring may be binary or ASCII armored.
+	KeyringKey = "keyring"
+)
+
+// sums is the second part of the message block in a provenance file.
+type sums struct {
+	Files map[string]string `json:"files"`
+}
+
+// KeyringSecretName returns the name of the secret with the keyring of the
+// catalog or an empty string if provenance verification is not required.
+func KeyringSecretName(catalog v1alpha1.Catalog) string {
+	return catalog.GetAnnotations()[annotation.CatalogProvenanceKeyringSecret]
+}
+
+// GetKeyring returns the keyring for verifying charts of the catalog. It
+// returns nil if the catalog does not require provenance verification.
+func GetKeyring(ctx context.Context, k8sClient kubernetes.Interface, catalog v1alpha1.Catalog) (openpgp.EntityList, error) {
+	name := KeyringSecretName(catalog)
+	if name == "" {
+		return nil, nil
+	}
+
+	secret, err := k8sClient.CoreV1().Secrets(catalog.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, microerror.Maskf(notFoundError, "keyring secret %#q in namespace %#q for catalog %#q", name, catalog.Namespace, catalog.Name)
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	data, ok := secret.Data[KeyringKey]
+	if !ok || len(data) == 0 {
+		return nil, microerror.Maskf(notFoundError, "key %#q in keyring secret %#q in namespace %#q", KeyringKey, name, catalog.Namespace)
+	}
+
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	if err != nil {
+		keyring, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+		if err != nil {
+			return nil, microerror.Maskf(verificationFailedError, "failed to read keyring from secret %#q: %s", name, err.Error())
+		}
+	}
+
+	return keyring, nil
+}
+
+// Pull downloads the provenance file of the chart tarball. By convention it
+// is stored next to the tarball with a .prov extension.
+func Pull(ctx context.Context, client *http.Client, tarballURL string) ([]byte, error) {
+	provURL := tarballURL + ".prov"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, provURL, nil)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	// We use https in catalog URLs so we can disable the linter in this case.
+	resp, err := client.Do(req) // #nosec
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, microerror.Maskf(verificationFailedError, "provenance file %#q not found", provURL)
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, microerror.Maskf(executionFailedError, "expected status code %d for %#q, got %d", http.StatusOK, provURL, resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return body, nil
+}
+
+// Verify checks the provenance file is signed by a key in the keyring and
+// returns the signed digest of the chart tarball in the format used by
+// digest.Normalize.
+func Verify(keyring openpgp.EntityList, prov []byte, tarballURL string) (string, error) {
+	block, _ := clearsign.Decode(prov)
+	if block == nil {
+		return "", microerror.Maskf(verificationFailedError, "signature block not found")
+	}
+
+	_, err := openpgp.CheckDetachedSignature(keyring, bytes.NewReader(block.Bytes), block.ArmoredSignature.Body)
+	if err != nil {
+		return "", microerror.Maskf(verificationFailedError, "invalid signature: %s", err.Error())
+	}
+
+	// The message block has the chart metadata and the file digests
+	// separated by a YAML document end marker.
+	parts := bytes.Split(block.Plaintext, []byte("\n...\n"))
+	if len(parts) < 2 {
+		return "", microerror.Maskf(verificationFailedError, "message block must have at least two parts")
+	}
+
+	var s sums
+
+	err = yaml.Unmarshal(parts[1], &s)
+	if err != nil {
+		return "", microerror.Maskf(verificationFailedError, "failed to parse file digests: %s", err.Error())
+	}
+
+	name := path.Base(tarballURL)
+
+	d, ok := s.Files[name]
+	if !ok {
+		return "", microerror.Maskf(verificationFailedError, "provenance does not contain a digest for %#q", name)
+	}
+
+	return digest.Normalize(d), nil
+}
+
+// VerifyChart pulls the provenance file of the chart tarball and verifies it
+// against the keyring. It returns the signed digest of the tarball.
+func VerifyChart(ctx context.Context, client *http.Client, keyring openpgp.EntityList, tarballURL string) (string, error) {
+	prov, err := Pull(ctx, client, tarballURL)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	d, err := Verify(keyring, prov, tarballURL)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return d, nil
+}
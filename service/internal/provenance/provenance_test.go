@@ -0,0 +1,77 @@
+package provenance
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+func Test_Verify(t *testing.T) {
+	pub, err := ioutil.ReadFile("testdata/helm-test-key.pub")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	keyring, err := openpgp.ReadKeyRing(bytes.NewReader(pub))
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	prov, err := ioutil.ReadFile("testdata/hashtest-1.2.3.tgz.prov")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	tests := []struct {
+		name           string
+		prov           []byte
+		tarballURL     string
+		expectedDigest string
+		errorMatcher   func(error) bool
+	}{
+		{
+			name:           "case 0: valid provenance",
+			prov:           prov,
+			tarballURL:     "https://giantswarm.github.io/app-catalog/hashtest-1.2.3.tgz",
+			expectedDigest: "c6841b3a895f1444a6738b5d04564a57e860ce42f8519c3be807fb6d9bee7888",
+		},
+		{
+			name:         "case 1: provenance for another tarball",
+			prov:         prov,
+			tarballURL:   "https://giantswarm.github.io/app-catalog/hashtest-1.2.4.tgz",
+			errorMatcher: IsVerificationFailed,
+		},
+		{
+			name:         "case 2: tampered provenance",
+			prov:         bytes.Replace(prov, []byte("c6841b3a"), []byte("00000000"), 1),
+			tarballURL:   "https://giantswarm.github.io/app-catalog/hashtest-1.2.3.tgz",
+			errorMatcher: IsVerificationFailed,
+		},
+		{
+			name:         "case 3: unsigned provenance",
+			prov:         []byte("files:\n  hashtest-1.2.3.tgz: sha256:c6841b3a\n"),
+			tarballURL:   "https://giantswarm.github.io/app-catalog/hashtest-1.2.3.tgz",
+			errorMatcher: IsVerificationFailed,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := Verify(keyring, tc.prov, tc.tarballURL)
+			switch {
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case err != nil && !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if d != tc.expectedDigest {
+				t.Fatalf("digest == %#q, want %#q", d, tc.expectedDigest)
+			}
+		})
+	}
+}
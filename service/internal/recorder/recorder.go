@@ -43,6 +43,12 @@ func (r *K8sEventsRecorder) Emit(ctx context.Context, obj pkgruntime.Object, rea
 	r.Eventf(obj, corev1.EventTypeNormal, reason, upper(message), args...)
 }
 
+// EmitWarning writes warning events for failures that are not returned as
+// errors to operatorkit, e.g. when they are also reported in a status.
+func (r *K8sEventsRecorder) EmitWarning(ctx context.Context, obj pkgruntime.Object, reason, message string, args ...interface{}) {
+	r.Eventf(obj, corev1.EventTypeWarning, reason, upper(message), args...)
+}
+
 // upper is a helper function to uppercase first letter of the event message
 func upper(in string) string {
 	out := []rune(in)
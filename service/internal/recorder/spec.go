@@ -9,4 +9,6 @@ import (
 type Interface interface {
 	// Emit is used to create Kubernetes events.
 	Emit(ctx context.Context, obj pkgruntime.Object, reason, message string, args ...interface{})
+	// EmitWarning is used to create Kubernetes warning events.
+	EmitWarning(ctx context.Context, obj pkgruntime.Object, reason, message string, args ...interface{})
 }
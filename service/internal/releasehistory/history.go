@@ -0,0 +1,120 @@
+// Package releasehistory keeps a bounded history of the releases of an app
+// because the app CR status only has the current release. The history is
+// stored in the status of the app CR by the conditions package.
+package releasehistory
+
+import (
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/helmclient/v4/pkg/helmclient"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// MaxReleases is the number of releases kept in the history including
+	// the current release.
+	MaxReleases = 10
+)
+
+// Release is a release of the chart of an app with its final status.
+type Release struct {
+	AppVersion   string       `json:"appVersion,omitempty"`
+	LastDeployed *metav1.Time `json:"lastDeployed,omitempty"`
+	Reason       string       `json:"reason,omitempty"`
+	Revision     *int         `json:"revision,omitempty"`
+	Status       string       `json:"status,omitempty"`
+	Values       Values       `json:"values,omitempty"`
+	Version      string       `json:"version"`
+}
+
+// Values are the resource versions of the values config map and secret in
+// the chart namespace used for the release.
+type Values struct {
+	ConfigMapResourceVersion string `json:"configMapResourceVersion,omitempty"`
+	SecretResourceVersion    string `json:"secretResourceVersion,omitempty"`
+}
+
+// NewRelease returns the release in the status of the chart CR. The chart
+// status has no values so they are taken from the chart CR spec. They are
+// only accurate when a release is first seen, so Add keeps the values
+// recorded for a release when its status changes.
+func NewRelease(chart v1alpha1.Chart) Release {
+	r := Release{
+		AppVersion: chart.Status.AppVersion,
+		Reason:     chart.Status.Reason,
+		Status:     chart.Status.Release.Status,
+		Values: Values{
+			ConfigMapResourceVersion: chart.Spec.Config.ConfigMap.ResourceVersion,
+			SecretResourceVersion:    chart.Spec.Config.Secret.ResourceVersion,
+		},
+		Version: chart.Status.Version,
+	}
+	if chart.Status.Release.LastDeployed != nil && !chart.Status.Release.LastDeployed.IsZero() {
+		r.LastDeployed = chart.Status.Release.LastDeployed.DeepCopy()
+	}
+	if chart.Status.Release.Revision != nil {
+		revision := *chart.Status.Release.Revision
+		r.Revision = &revision
+	}
+
+	return r
+}
+
+// Add records the current release in the history. A release of another
+// version or revision, or a new deployment, starts a new entry. Otherwise the
+// status of the current entry is updated. Once the current release is
+// deployed the previous deployed or pending releases are finalized as
+// superseded like Helm does. A chart without a release is not recorded. The
+// history is bounded to MaxReleases.
+func Add(history []Release, current Release) []Release {
+	if current.Version == "" {
+		return history
+	}
+
+	var updated []Release
+	if len(history) > 0 && isSameRelease(history[0], current) {
+		head := current
+		head.Values = history[0].Values
+		if head.LastDeployed == nil {
+			head.LastDeployed = history[0].LastDeployed
+		}
+		if head.Revision == nil {
+			head.Revision = history[0].Revision
+		}
+
+		updated = append([]Release{head}, history[1:]...)
+	} else {
+		updated = append([]Release{current}, history...)
+		if len(updated) > MaxReleases {
+			updated = updated[:MaxReleases]
+		}
+	}
+
+	if updated[0].Status == helmclient.StatusDeployed {
+		for i := 1; i < len(updated); i++ {
+			if updated[i].Status == helmclient.StatusDeployed || isPending(updated[i].Status) {
+				updated[i].Status = helmclient.StatusSuperseded
+				updated[i].Reason = ""
+			}
+		}
+	}
+
+	return updated
+}
+
+func isPending(status string) bool {
+	return status == helmclient.StatusPendingInstall || status == helmclient.StatusPendingUpgrade || status == helmclient.StatusPendingRollback
+}
+
+func isSameRelease(a, b Release) bool {
+	if a.Version != b.Version {
+		return false
+	}
+	if a.Revision != nil && b.Revision != nil && *a.Revision != *b.Revision {
+		return false
+	}
+	if a.LastDeployed != nil && b.LastDeployed != nil && !a.LastDeployed.Equal(b.LastDeployed) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,184 @@
+package releasehistory
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/to"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_Add(t *testing.T) {
+	deployed := metav1.NewTime(time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC))
+	redeployed := metav1.NewTime(time.Date(2021, 9, 2, 10, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name            string
+		history         []Release
+		current         Release
+		expectedHistory []Release
+	}{
+		{
+			name:    "case 0: first release",
+			current: Release{Status: "pending-install", Version: "1.0.0"},
+			expectedHistory: []Release{
+				{Status: "pending-install", Version: "1.0.0"},
+			},
+		},
+		{
+			name: "case 1: status of current release is updated",
+			history: []Release{
+				{Status: "pending-install", Version: "1.0.0"},
+			},
+			current: Release{LastDeployed: &deployed, Status: "deployed", Version: "1.0.0"},
+			expectedHistory: []Release{
+				{LastDeployed: &deployed, Status: "deployed", Version: "1.0.0"},
+			},
+		},
+		{
+			name: "case 2: new version starts a new release",
+			history: []Release{
+				{LastDeployed: &deployed, Status: "deployed", Version: "1.0.0"},
+			},
+			current: Release{Status: "pending-upgrade", Version: "1.1.0"},
+			expectedHistory: []Release{
+				{Status: "pending-upgrade", Version: "1.1.0"},
+				{LastDeployed: &deployed, Status: "deployed", Version: "1.0.0"},
+			},
+		},
+		{
+			name: "case 3: new values start a new release",
+			history: []Release{
+				{LastDeployed: &deployed, Status: "deployed", Values: Values{ConfigMapResourceVersion: "1"}, Version: "1.0.0"},
+			},
+			current: Release{LastDeployed: &redeployed, Status: "failed", Reason: "timeout", Values: Values{ConfigMapResourceVersion: "2"}, Version: "1.0.0"},
+			expectedHistory: []Release{
+				{LastDeployed: &redeployed, Status: "failed", Reason: "timeout", Values: Values{ConfigMapResourceVersion: "2"}, Version: "1.0.0"},
+				{LastDeployed: &deployed, Status: "deployed", Values: Values{ConfigMapResourceVersion: "1"}, Version: "1.0.0"},
+			},
+		},
+		{
+			name: "case 4: new deployment starts a new release",
+			history: []Release{
+				{LastDeployed: &deployed, Status: "deployed", Version: "1.0.0"},
+			},
+			current: Release{LastDeployed: &redeployed, Status: "deployed", Version: "1.0.0"},
+			expectedHistory: []Release{
+				{LastDeployed: &redeployed, Status: "deployed", Version: "1.0.0"},
+				{LastDeployed: &deployed, Status: "superseded", Version: "1.0.0"},
+			},
+		},
+		{
+			name: "case 5: chart without release is not recorded",
+			history: []Release{
+				{LastDeployed: &deployed, Status: "deployed", Version: "1.0.0"},
+			},
+			current: Release{Values: Values{ConfigMapResourceVersion: "2"}},
+			expectedHistory: []Release{
+				{LastDeployed: &deployed, Status: "deployed", Version: "1.0.0"},
+			},
+		},
+		{
+			name: "case 6: values of current release are kept",
+			history: []Release{
+				{Revision: to.IntP(1), Status: "deployed", Values: Values{ConfigMapResourceVersion: "1"}, Version: "1.0.0"},
+			},
+			current: Release{Revision: to.IntP(1), Status: "deployed", Values: Values{ConfigMapResourceVersion: "2"}, Version: "1.0.0"},
+			expectedHistory: []Release{
+				{Revision: to.IntP(1), Status: "deployed", Values: Values{ConfigMapResourceVersion: "1"}, Version: "1.0.0"},
+			},
+		},
+		{
+			name: "case 7: new revision starts a new release and finalizes previous release",
+			history: []Release{
+				{Revision: to.IntP(2), Status: "pending-upgrade", Version: "1.1.0"},
+				{Revision: to.IntP(1), Status: "deployed", Version: "1.0.0"},
+			},
+			current: Release{Revision: to.IntP(3), Status: "deployed", Version: "1.1.0"},
+			expectedHistory: []Release{
+				{Revision: to.IntP(3), Status: "deployed", Version: "1.1.0"},
+				{Revision: to.IntP(2), Status: "superseded", Version: "1.1.0"},
+				{Revision: to.IntP(1), Status: "superseded", Version: "1.0.0"},
+			},
+		},
+		{
+			name: "case 8: failed release keeps previous release deployed",
+			history: []Release{
+				{Revision: to.IntP(1), Status: "deployed", Version: "1.0.0"},
+			},
+			current: Release{Reason: "timeout", Revision: to.IntP(2), Status: "failed", Version: "1.1.0"},
+			expectedHistory: []Release{
+				{Reason: "timeout", Revision: to.IntP(2), Status: "failed", Version: "1.1.0"},
+				{Revision: to.IntP(1), Status: "deployed", Version: "1.0.0"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := Add(tc.history, tc.current)
+
+			if !reflect.DeepEqual(result, tc.expectedHistory) {
+				t.Fatalf("want matching history \n %s", cmp.Diff(result, tc.expectedHistory))
+			}
+		})
+	}
+}
+
+func Test_Add_bounded(t *testing.T) {
+	var history []Release
+	for i := 0; i < MaxReleases+5; i++ {
+		history = Add(history, Release{Version: fmt.Sprintf("1.%d.0", i)})
+	}
+
+	if len(history) != MaxReleases {
+		t.Fatalf("history length == %d, want %d", len(history), MaxReleases)
+	}
+	if history[0].Version != fmt.Sprintf("1.%d.0", MaxReleases+4) {
+		t.Fatalf("current version == %#q, want %#q", history[0].Version, fmt.Sprintf("1.%d.0", MaxReleases+4))
+	}
+}
+
+func Test_NewRelease(t *testing.T) {
+	deployed := metav1.NewTime(time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC))
+
+	// The chart CR spec has the next version while the status still has the
+	// deployed release.
+	chart := v1alpha1.Chart{
+		Spec: v1alpha1.ChartSpec{
+			Config: v1alpha1.ChartSpecConfig{
+				ConfigMap: v1alpha1.ChartSpecConfigConfigMap{
+					ResourceVersion: "2",
+				},
+			},
+			Version: "1.1.0",
+		},
+		Status: v1alpha1.ChartStatus{
+			AppVersion: "2.0.0",
+			Release: v1alpha1.ChartStatusRelease{
+				LastDeployed: &deployed,
+				Revision:     to.IntP(1),
+				Status:       "deployed",
+			},
+			Version: "1.0.0",
+		},
+	}
+
+	expected := Release{
+		AppVersion:   "2.0.0",
+		LastDeployed: &deployed,
+		Revision:     to.IntP(1),
+		Status:       "deployed",
+		Values:       Values{ConfigMapResourceVersion: "2"},
+		Version:      "1.0.0",
+	}
+
+	release := NewRelease(chart)
+	if !reflect.DeepEqual(release, expected) {
+		t.Fatalf("want matching release \n %s", cmp.Diff(release, expected))
+	}
+}
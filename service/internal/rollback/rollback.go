@@ -0,0 +1,169 @@
+// Package rollback keeps track of the last chart of an app that reached
+// deployed so app-operator can revert the chart CR to it when an upgrade
+// fails.
+package rollback
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+)
+
+const (
+	// DefaultTimeout is the time a new release has to become deployed if the
+	// app does not set a timeout.
+	DefaultTimeout = 10 * time.Minute
+)
+
+// Release is the part of the chart CR spec that is reverted by a rollback.
+type Release struct {
+	Config v1alpha1.ChartSpecConfig `json:"config"`
+	Digest string                   `json:"digest,omitempty"`
+	// Source is the values config the snapshots referenced by Config were
+	// copied from. It is empty for releases without snapshots.
+	Source     v1alpha1.ChartSpecConfig `json:"source,omitempty"`
+	TarballURL string                   `json:"tarballURL"`
+	Version    string                   `json:"version"`
+}
+
+// State is stored in the rollback state annotation of the app CR.
+type State struct {
+	// LastDeployed is the last release that reached deployed. Its values
+	// reference snapshots of the values at that time.
+	LastDeployed *Release `json:"lastDeployed,omitempty"`
+	// Pending is the release of the chart CR that was not yet seen
+	// deployed.
+	Pending *Release `json:"pending,omitempty"`
+	// PendingSince is the time the pending release was first seen. The
+	// pending release is only deployed when chart-operator deployed it after
+	// this time.
+	PendingSince *metav1.Time `json:"pendingSince,omitempty"`
+	// RolledBack is the failed release the app was rolled back from. The
+	// chart CR keeps the last deployed release while the app CR still
+	// results in this release.
+	RolledBack *Release `json:"rolledBack,omitempty"`
+}
+
+// IsEnabled returns true if the app opted in to automatic rollbacks.
+func IsEnabled(cr v1alpha1.App) bool {
+	enabled, _ := strconv.ParseBool(cr.GetAnnotations()[annotation.AppRollback])
+	return enabled
+}
+
+// Timeout returns the time a new release has to become deployed before it is
+// rolled back.
+func Timeout(cr v1alpha1.App) (time.Duration, error) {
+	value := cr.GetAnnotations()[annotation.AppRollbackTimeout]
+	if value == "" {
+		return DefaultTimeout, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, microerror.Maskf(invalidConfigError, "annotation %#q must be a duration: %s", annotation.AppRollbackTimeout, err.Error())
+	}
+	if d <= 0 {
+		return 0, microerror.Maskf(invalidConfigError, "annotation %#q must be positive", annotation.AppRollbackTimeout)
+	}
+
+	return d, nil
+}
+
+// GetState returns the rollback state stored in the app CR.
+func GetState(cr v1alpha1.App) (State, error) {
+	var s State
+
+	value := cr.GetAnnotations()[annotation.AppRollbackState]
+	if value == "" {
+		return s, nil
+	}
+
+	err := json.Unmarshal([]byte(value), &s)
+	if err != nil {
+		return State{}, microerror.Mask(err)
+	}
+
+	return s, nil
+}
+
+// Marshal returns the state as annotation value.
+func (s State) Marshal() (string, error) {
+	bytes, err := json.Marshal(s)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return string(bytes), nil
+}
+
+// Message describes the rollback for the app status and events.
+func (s State) Message() string {
+	if s.RolledBack == nil || s.LastDeployed == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("rolled back from version %#q to version %#q", s.RolledBack.Version, s.LastDeployed.Version)
+}
+
+// NewRelease returns the release of the chart CR.
+func NewRelease(chart v1alpha1.Chart) Release {
+	return Release{
+		Config:     chart.Spec.Config,
+		Digest:     chart.GetAnnotations()[annotation.ChartOperatorChartDigest],
+		TarballURL: chart.Spec.TarballURL,
+		Version:    chart.Spec.Version,
+	}
+}
+
+// Apply sets the release in the chart CR.
+func (r Release) Apply(chart *v1alpha1.Chart) {
+	chart.Spec.Config = r.Config
+	chart.Spec.TarballURL = r.TarballURL
+	chart.Spec.Version = r.Version
+
+	if r.Digest == "" {
+		delete(chart.Annotations, annotation.ChartOperatorChartDigest)
+		return
+	}
+
+	if chart.Annotations == nil {
+		chart.Annotations = map[string]string{}
+	}
+	chart.Annotations[annotation.ChartOperatorChartDigest] = r.Digest
+}
+
+// Equal returns true if both releases deploy the same chart and values. The
+// values of a release with snapshots match both the snapshots and the values
+// they were copied from.
+func (r Release) Equal(o Release) bool {
+	if r.Digest != o.Digest || r.TarballURL != o.TarballURL || r.Version != o.Version {
+		return false
+	}
+
+	for _, a := range r.configs() {
+		for _, b := range o.configs() {
+			if reflect.DeepEqual(a, b) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (r Release) configs() []v1alpha1.ChartSpecConfig {
+	configs := []v1alpha1.ChartSpecConfig{r.Config}
+	if !reflect.DeepEqual(r.Source, v1alpha1.ChartSpecConfig{}) {
+		configs = append(configs, r.Source)
+	}
+
+	return configs
+}
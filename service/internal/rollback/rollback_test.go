@@ -0,0 +1,129 @@
+package rollback
+
+import (
+	"testing"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_Release(t *testing.T) {
+	current := v1alpha1.Chart{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				"chart-operator.giantswarm.io/chart-digest": "abc",
+			},
+		},
+		Spec: v1alpha1.ChartSpec{
+			Config: v1alpha1.ChartSpecConfig{
+				ConfigMap: v1alpha1.ChartSpecConfigConfigMap{
+					Name:            "prometheus-chart-values",
+					Namespace:       "giantswarm",
+					ResourceVersion: "2",
+				},
+			},
+			TarballURL: "https://giantswarm.github.io/app-catalog/prometheus-2.0.0.tgz",
+			Version:    "2.0.0",
+		},
+	}
+
+	lastDeployed := Release{
+		Config: v1alpha1.ChartSpecConfig{
+			ConfigMap: v1alpha1.ChartSpecConfigConfigMap{
+				Name:            "prometheus-chart-values-rollback",
+				Namespace:       "giantswarm",
+				ResourceVersion: "1",
+			},
+		},
+		TarballURL: "https://giantswarm.github.io/app-catalog/prometheus-1.0.0.tgz",
+		Version:    "1.0.0",
+	}
+
+	failed := NewRelease(current)
+	if failed.Equal(lastDeployed) {
+		t.Fatalf("releases are equal, want different")
+	}
+
+	snapshot := Release{
+		Config: v1alpha1.ChartSpecConfig{
+			ConfigMap: v1alpha1.ChartSpecConfigConfigMap{
+				Name:            "prometheus-chart-values-rollback",
+				Namespace:       "giantswarm",
+				ResourceVersion: "3",
+			},
+		},
+		Digest:     "abc",
+		Source:     current.Spec.Config,
+		TarballURL: current.Spec.TarballURL,
+		Version:    current.Spec.Version,
+	}
+	if !failed.Equal(snapshot) {
+		t.Fatalf("release == %#v, want equal to snapshot of its values %#v", failed, snapshot)
+	}
+
+	lastDeployed.Apply(&current)
+
+	if !NewRelease(current).Equal(lastDeployed) {
+		t.Fatalf("release == %#v, want %#v", NewRelease(current), lastDeployed)
+	}
+	if _, ok := current.Annotations["chart-operator.giantswarm.io/chart-digest"]; ok {
+		t.Fatalf("digest annotation is set, want removed")
+	}
+
+	s := State{
+		LastDeployed: &lastDeployed,
+		RolledBack:   &failed,
+	}
+	if s.Message() != "rolled back from version `2.0.0` to version `1.0.0`" {
+		t.Fatalf("message == %#q", s.Message())
+	}
+}
+
+func Test_Timeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "case 0: default timeout",
+			expected: "10m0s",
+		},
+		{
+			name:     "case 1: custom timeout",
+			value:    "30m",
+			expected: "30m0s",
+		},
+		{
+			name:        "case 2: invalid timeout",
+			value:       "soon",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cr := v1alpha1.App{}
+			if tc.value != "" {
+				cr.Annotations = map[string]string{
+					"app-operator.giantswarm.io/rollback-timeout": tc.value,
+				}
+			}
+
+			d, err := Timeout(cr)
+			switch {
+			case err != nil && !tc.expectError:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.expectError:
+				t.Fatalf("error == nil, want non-nil")
+			case err != nil && !IsInvalidConfig(err):
+				t.Fatalf("error == %#v, want invalidConfigError", err)
+			}
+
+			if err == nil && d.String() != tc.expected {
+				t.Fatalf("timeout == %s, want %s", d, tc.expected)
+			}
+		})
+	}
+}
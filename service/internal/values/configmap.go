@@ -0,0 +1,91 @@
+package values
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/app/v5/pkg/key"
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConfigMapLayers returns the configured catalog, default, cluster, user and
+// extra config map layers of the app in merge order.
+func (v *Values) ConfigMapLayers(ctx context.Context, app v1alpha1.App, catalog v1alpha1.Catalog) ([]Layer, error) {
+	refs := []layerRef{
+		{
+			layer:     CatalogLayer,
+			name:      key.CatalogConfigMapName(catalog),
+			namespace: key.CatalogConfigMapNamespace(catalog),
+			priority:  CatalogPriority,
+		},
+		{
+			layer:     ClusterLayer,
+			name:      key.AppConfigMapName(app),
+			namespace: key.AppConfigMapNamespace(app),
+			priority:  ClusterPriority,
+		},
+		{
+			layer:     UserLayer,
+			name:      key.UserConfigMapName(app),
+			namespace: key.UserConfigMapNamespace(app),
+			priority:  UserPriority,
+		},
+	}
+
+	defaultConfigs, err := v.DefaultConfigMaps(ctx, app)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	refs = withDefaultConfigs(refs, defaultConfigs)
+
+	extraConfigs, err := ExtraConfigs(app)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	refs = withExtraConfigs(refs, extraConfigs, configMapKind)
+
+	var layers []Layer
+
+	for _, ref := range refs {
+		if ref.name == "" {
+			continue
+		}
+
+		rawData, err := v.getConfigMap(ctx, ref.name, ref.namespace)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		data, err := extractData(configMapKind, ref.layer, rawData)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		layers = append(layers, Layer{
+			Name:   ref.layer,
+			Kind:   configMapKind,
+			Source: fmt.Sprintf("%s/%s", ref.namespace, ref.name),
+			Data:   data,
+		})
+	}
+
+	return layers, nil
+}
+
+func (v *Values) getConfigMap(ctx context.Context, configMapName, configMapNamespace string) (map[string]string, error) {
+	v.logger.Debugf(ctx, "looking for configmap %#q in namespace %#q", configMapName, configMapNamespace)
+
+	configMap, err := v.k8sClient.CoreV1().ConfigMaps(configMapNamespace).Get(ctx, configMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, microerror.Maskf(notFoundError, "configmap %#q in namespace %#q not found", configMapName, configMapNamespace)
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	v.logger.Debugf(ctx, "found configmap %#q in namespace %#q", configMapName, configMapNamespace)
+
+	return configMap.Data, nil
+}
@@ -0,0 +1,131 @@
+package values
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/app/v5/pkg/key"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/app-operator/v5/pkg/label"
+)
+
+// DefaultConfig is a config map or secret with default values for the apps
+// in its namespace.
+type DefaultConfig struct {
+	// Layer is organization or namespace.
+	Layer     string
+	Name      string
+	Namespace string
+}
+
+// DefaultConfigMaps returns the default values config maps of the
+// organization and namespace of the app in merge order.
+func (v *Values) DefaultConfigMaps(ctx context.Context, app v1alpha1.App) ([]DefaultConfig, error) {
+	return v.defaultConfigs(ctx, app, configMapKind)
+}
+
+// DefaultSecrets returns the default values secrets of the organization and
+// namespace of the app in merge order.
+func (v *Values) DefaultSecrets(ctx context.Context, app v1alpha1.App) ([]DefaultConfig, error) {
+	return v.defaultConfigs(ctx, app, secretKind)
+}
+
+// OrganizationNamespace returns the namespace of the organization of the app
+// or an empty string if the app has no organization label.
+func OrganizationNamespace(app v1alpha1.App) string {
+	if key.OrganizationID(app) == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("org-%s", key.OrganizationID(app))
+}
+
+func (v *Values) defaultConfigs(ctx context.Context, app v1alpha1.App, kind string) ([]DefaultConfig, error) {
+	namespaces := []struct {
+		layer     string
+		namespace string
+	}{
+		{
+			layer:     OrganizationLayer,
+			namespace: OrganizationNamespace(app),
+		},
+		{
+			layer:     NamespaceLayer,
+			namespace: app.GetNamespace(),
+		},
+	}
+
+	lo := metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=true", label.DefaultValues),
+	}
+
+	var defaultConfigs []DefaultConfig
+
+	for _, n := range namespaces {
+		if n.namespace == "" {
+			continue
+		}
+		// Apps in the organization namespace get its defaults only once.
+		if n.layer == OrganizationLayer && n.namespace == app.GetNamespace() {
+			continue
+		}
+
+		var names []string
+
+		if kind == configMapKind {
+			list, err := v.k8sClient.CoreV1().ConfigMaps(n.namespace).List(ctx, lo)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+
+			for _, item := range list.Items {
+				names = append(names, item.Name)
+			}
+		} else {
+			list, err := v.k8sClient.CoreV1().Secrets(n.namespace).List(ctx, lo)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+
+			for _, item := range list.Items {
+				names = append(names, item.Name)
+			}
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			defaultConfigs = append(defaultConfigs, DefaultConfig{
+				Layer:     n.layer,
+				Name:      name,
+				Namespace: n.namespace,
+			})
+		}
+	}
+
+	return defaultConfigs, nil
+}
+
+// withDefaultConfigs adds the default configs to the refs of the built-in
+// layers.
+func withDefaultConfigs(refs []layerRef, defaultConfigs []DefaultConfig) []layerRef {
+	for _, d := range defaultConfigs {
+		priority := NamespacePriority
+		if d.Layer == OrganizationLayer {
+			priority = OrganizationPriority
+		}
+
+		refs = append(refs, layerRef{
+			layer:     d.Layer,
+			name:      d.Name,
+			namespace: d.Namespace,
+			priority:  priority,
+		})
+	}
+
+	return refs
+}
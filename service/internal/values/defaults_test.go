@@ -0,0 +1,120 @@
+package values
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	k8smetadatalabel "github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgofake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/app-operator/v5/pkg/label"
+)
+
+func Test_ConfigMapLayers_defaults(t *testing.T) {
+	configMap := func(namespace, name string, values string, isDefault bool) runtime.Object {
+		cm := &corev1.ConfigMap{
+			Data: map[string]string{
+				"values": values,
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+		}
+		if isDefault {
+			cm.Labels = map[string]string{
+				label.DefaultValues: "true",
+			}
+		}
+
+		return cm
+	}
+
+	app := v1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				k8smetadatalabel.Organization: "acme",
+			},
+			Name:      "prometheus",
+			Namespace: "5xchu",
+		},
+		Spec: v1alpha1.AppSpec{
+			Config: v1alpha1.AppSpecConfig{
+				ConfigMap: v1alpha1.AppSpecConfigConfigMap{
+					Name:      "5xchu-cluster-values",
+					Namespace: "5xchu",
+				},
+			},
+		},
+	}
+	catalog := v1alpha1.Catalog{
+		Spec: v1alpha1.CatalogSpec{
+			Config: &v1alpha1.CatalogSpecConfig{
+				ConfigMap: &v1alpha1.CatalogSpecConfigConfigMap{
+					Name:      "catalog-values",
+					Namespace: "giantswarm",
+				},
+			},
+		},
+	}
+
+	objs := []runtime.Object{
+		configMap("giantswarm", "catalog-values", "replicas: 1\nregistry: catalog\n", false),
+		configMap("org-acme", "acme-defaults", "registry: acme\nproxy: acme\n", true),
+		configMap("org-acme", "not-defaults", "registry: other\n", false),
+		configMap("5xchu", "b-defaults", "proxy: 5xchu\n", true),
+		configMap("5xchu", "a-defaults", "proxy: first\n", true),
+		configMap("5xchu", "5xchu-cluster-values", "replicas: 2\n", false),
+	}
+
+	c := Config{
+		K8sClient: clientgofake.NewSimpleClientset(objs...),
+		Logger:    microloggertest.New(),
+	}
+	v, err := New(c)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	layers, err := v.ConfigMapLayers(context.Background(), app, catalog)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	var result []string
+	for _, l := range layers {
+		result = append(result, l.String())
+	}
+
+	expected := []string{
+		"catalog configmap giantswarm/catalog-values",
+		"organization configmap org-acme/acme-defaults",
+		"namespace configmap 5xchu/a-defaults",
+		"namespace configmap 5xchu/b-defaults",
+		"cluster configmap 5xchu/5xchu-cluster-values",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("want matching layers \n %s", cmp.Diff(result, expected))
+	}
+
+	merged, err := Merge(layers)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	expectedValues := map[string]interface{}{
+		"proxy":    "5xchu",
+		"registry": "acme",
+		"replicas": float64(2),
+	}
+	if !reflect.DeepEqual(merged, expectedValues) {
+		t.Fatalf("want matching values \n %s", cmp.Diff(merged, expectedValues))
+	}
+}
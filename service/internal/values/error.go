@@ -0,0 +1,39 @@
+package values
+
+import "github.com/giantswarm/microerror"
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var notFoundError = &microerror.Error{
+	Kind: "notFoundError",
+}
+
+// IsNotFound asserts notFoundError.
+func IsNotFound(err error) bool {
+	return microerror.Cause(err) == notFoundError
+}
+
+var parsingError = &microerror.Error{
+	Kind: "parsingError",
+}
+
+// IsParsingError asserts parsingError.
+func IsParsingError(err error) bool {
+	return microerror.Cause(err) == parsingError
+}
+
+var templateError = &microerror.Error{
+	Kind: "templateError",
+}
+
+// IsTemplateError asserts templateError.
+func IsTemplateError(err error) bool {
+	return microerror.Cause(err) == templateError
+}
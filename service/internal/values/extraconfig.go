@@ -0,0 +1,111 @@
+package values
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+)
+
+// Priorities of the built-in layers. Extra configs are merged between the
+// built-in layers by their priority. Extra configs with the priority of a
+// built-in layer are merged after it.
+const (
+	CatalogPriority      = 0
+	OrganizationPriority = 10
+	NamespacePriority    = 20
+	ClusterPriority      = 50
+	UserPriority         = 100
+
+	// DefaultExtraConfigPriority merges extra configs without a priority
+	// between the catalog and cluster layers.
+	DefaultExtraConfigPriority = 25
+	// MaxExtraConfigPriority is the highest priority of extra configs.
+	MaxExtraConfigPriority = 150
+)
+
+// ExtraConfig is a config map or secret with values of an app in addition to
+// the catalog, cluster and user values.
+type ExtraConfig struct {
+	// Kind is configMap or secret.
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Priority orders the extra config relative to the built-in layers. It
+	// must be between 1 and MaxExtraConfigPriority.
+	Priority int `json:"priority,omitempty"`
+}
+
+type layerRef struct {
+	layer     string
+	name      string
+	namespace string
+	priority  int
+}
+
+// ExtraConfigs returns the extra configs of the app in the order of the
+// annotation. The namespace defaults to the namespace of the app and the
+// priority to DefaultExtraConfigPriority.
+func ExtraConfigs(app v1alpha1.App) ([]ExtraConfig, error) {
+	value, ok := app.GetAnnotations()[annotation.AppExtraConfigs]
+	if !ok {
+		return nil, nil
+	}
+
+	var extraConfigs []ExtraConfig
+
+	err := json.Unmarshal([]byte(value), &extraConfigs)
+	if err != nil {
+		return nil, microerror.Maskf(parsingError, "failed to parse annotation %#q, logs: %s", annotation.AppExtraConfigs, err.Error())
+	}
+
+	for i, e := range extraConfigs {
+		if !strings.EqualFold(e.Kind, configMapKind) && !strings.EqualFold(e.Kind, secretKind) {
+			return nil, microerror.Maskf(parsingError, "extra config %d in annotation %#q has kind %#q, want `configMap` or `secret`", i, annotation.AppExtraConfigs, e.Kind)
+		}
+		if e.Name == "" {
+			return nil, microerror.Maskf(parsingError, "extra config %d in annotation %#q has no name", i, annotation.AppExtraConfigs)
+		}
+		if e.Priority < 0 || e.Priority > MaxExtraConfigPriority {
+			return nil, microerror.Maskf(parsingError, "extra config %d in annotation %#q has priority %d, want between 1 and %d", i, annotation.AppExtraConfigs, e.Priority, MaxExtraConfigPriority)
+		}
+
+		if e.Namespace == "" {
+			extraConfigs[i].Namespace = app.GetNamespace()
+		}
+		if e.Priority == 0 {
+			extraConfigs[i].Priority = DefaultExtraConfigPriority
+		}
+	}
+
+	return extraConfigs, nil
+}
+
+// withExtraConfigs adds the extra configs of the kind to the refs of the
+// built-in layers and returns them in merge order.
+func withExtraConfigs(refs []layerRef, extraConfigs []ExtraConfig, kind string) []layerRef {
+	for _, e := range extraConfigs {
+		if !strings.EqualFold(e.Kind, kind) {
+			continue
+		}
+
+		refs = append(refs, layerRef{
+			layer:     ExtraLayer,
+			name:      e.Name,
+			namespace: e.Namespace,
+			priority:  e.Priority,
+		})
+	}
+
+	// The built-in layers come first so they are merged before extra
+	// configs with the same priority.
+	sort.SliceStable(refs, func(i, j int) bool {
+		return refs[i].priority < refs[j].priority
+	})
+
+	return refs
+}
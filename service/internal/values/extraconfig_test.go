@@ -0,0 +1,177 @@
+package values
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgofake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+)
+
+func Test_ExtraConfigs(t *testing.T) {
+	tests := []struct {
+		name                 string
+		annotations          map[string]string
+		expectedExtraConfigs []ExtraConfig
+		errorMatcher         func(error) bool
+	}{
+		{
+			name:                 "case 0: no annotation",
+			expectedExtraConfigs: nil,
+		},
+		{
+			name: "case 1: defaults are set",
+			annotations: map[string]string{
+				annotation.AppExtraConfigs: `[{"kind": "configMap", "name": "ingress-values"}, {"kind": "secret", "name": "tls-values", "namespace": "giantswarm", "priority": 120}]`,
+			},
+			expectedExtraConfigs: []ExtraConfig{
+				{
+					Kind:      "configMap",
+					Name:      "ingress-values",
+					Namespace: "default",
+					Priority:  DefaultExtraConfigPriority,
+				},
+				{
+					Kind:      "secret",
+					Name:      "tls-values",
+					Namespace: "giantswarm",
+					Priority:  120,
+				},
+			},
+		},
+		{
+			name: "case 2: unknown kind",
+			annotations: map[string]string{
+				annotation.AppExtraConfigs: `[{"kind": "pod", "name": "ingress-values"}]`,
+			},
+			errorMatcher: IsParsingError,
+		},
+		{
+			name: "case 3: priority out of range",
+			annotations: map[string]string{
+				annotation.AppExtraConfigs: `[{"kind": "configMap", "name": "ingress-values", "priority": 151}]`,
+			},
+			errorMatcher: IsParsingError,
+		},
+		{
+			name: "case 4: invalid JSON",
+			annotations: map[string]string{
+				annotation.AppExtraConfigs: `{"kind": "configMap"}`,
+			},
+			errorMatcher: IsParsingError,
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			app := v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tc.annotations,
+					Name:        "prometheus",
+					Namespace:   "default",
+				},
+			}
+
+			result, err := ExtraConfigs(app)
+			switch {
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case err != nil && !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if !reflect.DeepEqual(result, tc.expectedExtraConfigs) {
+				t.Fatalf("want matching extra configs \n %s", cmp.Diff(result, tc.expectedExtraConfigs))
+			}
+		})
+	}
+}
+
+func Test_ConfigMapLayers_extraConfigs(t *testing.T) {
+	configMap := func(name string) runtime.Object {
+		return &corev1.ConfigMap{
+			Data: map[string]string{
+				"values": "source: " + name + "\n",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+		}
+	}
+
+	app := v1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				annotation.AppExtraConfigs: `[{"kind": "configMap", "name": "last", "priority": 150}, {"kind": "configMap", "name": "after-cluster", "priority": 50}, {"kind": "secret", "name": "secret"}, {"kind": "configMap", "name": "before-cluster"}]`,
+			},
+			Name:      "prometheus",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AppSpec{
+			Config: v1alpha1.AppSpecConfig{
+				ConfigMap: v1alpha1.AppSpecConfigConfigMap{
+					Name:      "cluster",
+					Namespace: "default",
+				},
+			},
+			UserConfig: v1alpha1.AppSpecUserConfig{
+				ConfigMap: v1alpha1.AppSpecUserConfigConfigMap{
+					Name:      "user",
+					Namespace: "default",
+				},
+			},
+		},
+	}
+
+	c := Config{
+		K8sClient: clientgofake.NewSimpleClientset(configMap("last"), configMap("after-cluster"), configMap("before-cluster"), configMap("cluster"), configMap("user")),
+		Logger:    microloggertest.New(),
+	}
+	v, err := New(c)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	layers, err := v.ConfigMapLayers(context.Background(), app, v1alpha1.Catalog{})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	var result []string
+	for _, l := range layers {
+		result = append(result, l.String())
+	}
+
+	expected := []string{
+		"extra configmap default/before-cluster",
+		"cluster configmap default/cluster",
+		"extra configmap default/after-cluster",
+		"user configmap default/user",
+		"extra configmap default/last",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("want matching layers \n %s", cmp.Diff(result, expected))
+	}
+
+	merged, err := Merge(layers)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if merged["source"] != "last" {
+		t.Fatalf("source == %#v, want %#v", merged["source"], "last")
+	}
+}
@@ -0,0 +1,66 @@
+package values
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+)
+
+// Provenance returns the layer that set each value of the merged values of
+// the layers. Keys are the dotted paths of the values, e.g.
+// `ingress.enabled`. The values themselves are not returned so secret
+// values are never exposed.
+func Provenance(layers []Layer) (map[string]string, error) {
+	merged, err := Merge(layers)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	provenance := map[string]string{}
+
+	walk(merged, nil, func(path []string, value interface{}) {
+		// The value was set by the last layer that has it.
+		for i := len(layers) - 1; i >= 0; i-- {
+			v, ok := lookup(layers[i].Data, path)
+			if ok && reflect.DeepEqual(v, value) {
+				provenance[strings.Join(path, ".")] = layers[i].String()
+				return
+			}
+		}
+	})
+
+	return provenance, nil
+}
+
+// walk calls fn for each value that is not a non-empty map.
+func walk(v interface{}, path []string, fn func(path []string, value interface{})) {
+	m, ok := v.(map[string]interface{})
+	if !ok || (len(m) == 0 && len(path) > 0) {
+		fn(path, v)
+		return
+	}
+
+	for k, child := range m {
+		childPath := append(append([]string{}, path...), k)
+		walk(child, childPath, fn)
+	}
+}
+
+func lookup(data map[string]interface{}, path []string) (interface{}, bool) {
+	var v interface{} = data
+
+	for _, k := range path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		v, ok = m[k]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return v, true
+}
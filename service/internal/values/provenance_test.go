@@ -0,0 +1,64 @@
+package values
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Provenance(t *testing.T) {
+	layers := []Layer{
+		{
+			Name:   CatalogLayer,
+			Kind:   configMapKind,
+			Source: "giantswarm/default-catalog-values",
+			Data: map[string]interface{}{
+				"ingress": map[string]interface{}{
+					"enabled": true,
+					"host":    "example.com",
+				},
+				"replicas": 1,
+			},
+		},
+		{
+			Name:   ClusterLayer,
+			Kind:   configMapKind,
+			Source: "5xchu/prometheus-cluster-values",
+			Data: map[string]interface{}{
+				"ingress": map[string]interface{}{
+					"host": "5xchu.example.com",
+				},
+			},
+		},
+		{
+			Name:   UserLayer,
+			Kind:   configMapKind,
+			Source: "5xchu/prometheus-user-values",
+			Data: map[string]interface{}{
+				"replicas":  3,
+				"resources": map[string]interface{}{},
+			},
+		},
+	}
+
+	provenance, err := Provenance(layers)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	expected := map[string]string{
+		"ingress.enabled": "catalog configmap giantswarm/default-catalog-values",
+		"ingress.host":    "cluster configmap 5xchu/prometheus-cluster-values",
+		"replicas":        "user configmap 5xchu/prometheus-user-values",
+		"resources":       "user configmap 5xchu/prometheus-user-values",
+	}
+	if !reflect.DeepEqual(provenance, expected) {
+		t.Fatalf("want matching provenance \n %s", cmp.Diff(provenance, expected))
+	}
+
+	// Merging must not modify the layers.
+	if layers[0].Data["ingress"].(map[string]interface{})["host"] != "example.com" {
+		t.Fatalf("catalog layer was modified")
+	}
+}
@@ -0,0 +1,99 @@
+package values
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/app/v5/pkg/key"
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecretLayers returns the configured catalog, default, cluster, user and
+// extra secret layers and the value ref layers of the app in merge order.
+func (v *Values) SecretLayers(ctx context.Context, app v1alpha1.App, catalog v1alpha1.Catalog) ([]Layer, error) {
+	refs := []layerRef{
+		{
+			layer:     CatalogLayer,
+			name:      key.CatalogSecretName(catalog),
+			namespace: key.CatalogSecretNamespace(catalog),
+			priority:  CatalogPriority,
+		},
+		{
+			layer:     ClusterLayer,
+			name:      key.AppSecretName(app),
+			namespace: key.AppSecretNamespace(app),
+			priority:  ClusterPriority,
+		},
+		{
+			layer:     UserLayer,
+			name:      key.UserSecretName(app),
+			namespace: key.UserSecretNamespace(app),
+			priority:  UserPriority,
+		},
+	}
+
+	defaultConfigs, err := v.DefaultSecrets(ctx, app)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	refs = withDefaultConfigs(refs, defaultConfigs)
+
+	extraConfigs, err := ExtraConfigs(app)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	refs = withExtraConfigs(refs, extraConfigs, secretKind)
+
+	var layers []Layer
+
+	for _, ref := range refs {
+		if ref.name == "" {
+			continue
+		}
+
+		rawData, err := v.getSecret(ctx, ref.name, ref.namespace)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		data, err := extractData(secretKind, ref.layer, toStringMap(rawData))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		layers = append(layers, Layer{
+			Name:   ref.layer,
+			Kind:   secretKind,
+			Source: fmt.Sprintf("%s/%s", ref.namespace, ref.name),
+			Data:   data,
+		})
+	}
+
+	// Referenced values are set at explicit paths so they override all
+	// other values.
+	valueRefLayers, err := v.valueRefLayers(ctx, app)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	layers = append(layers, valueRefLayers...)
+
+	return layers, nil
+}
+
+func (v *Values) getSecret(ctx context.Context, secretName, secretNamespace string) (map[string][]byte, error) {
+	v.logger.Debugf(ctx, "looking for secret %#q in namespace %#q", secretName, secretNamespace)
+
+	secret, err := v.k8sClient.CoreV1().Secrets(secretNamespace).Get(ctx, secretName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, microerror.Maskf(notFoundError, "secret %#q in namespace %#q not found", secretName, secretNamespace)
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	v.logger.Debugf(ctx, "found secret %#q in namespace %#q", secretName, secretNamespace)
+
+	return secret.Data, nil
+}
@@ -0,0 +1,219 @@
+package values
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/app/v5/pkg/key"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+)
+
+// MaxRenderedLength is the maximum length of a rendered value.
+const MaxRenderedLength = 64 * 1024
+
+// TemplateData are the variables of values templates, e.g.
+// `{{ .ClusterID }}.{{ index .Labels "giantswarm.io/organization" }}`.
+type TemplateData struct {
+	ClusterID      string
+	Labels         map[string]string
+	OrganizationID string
+	Provider       string
+}
+
+// templateFuncs are the only functions of values templates besides the
+// builtins of text/template. None of them have side effects. The builtins
+// that build strings are replaced so their results are limited to
+// MaxRenderedLength too, otherwise pipelines could grow them exponentially.
+var templateFuncs = template.FuncMap{
+	"default": func(d interface{}, v interface{}) interface{} {
+		if v == nil || v == "" {
+			return d
+		}
+		return v
+	},
+	"hasPrefix": strings.HasPrefix,
+	"hasSuffix": strings.HasSuffix,
+	"lower":     strings.ToLower,
+	"print":     func(args ...interface{}) (string, error) { return limited(fmt.Sprint(args...)) },
+	"printf": func(format string, args ...interface{}) (string, error) {
+		return limited(fmt.Sprintf(format, args...))
+	},
+	"println":    func(args ...interface{}) (string, error) { return limited(fmt.Sprintln(args...)) },
+	"replace":    func(old, new, s string) (string, error) { return limited(strings.ReplaceAll(s, old, new)) },
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"upper":      strings.ToUpper,
+}
+
+// IsTemplatingEnabled returns true if the values of the app are rendered as
+// templates.
+func IsTemplatingEnabled(app v1alpha1.App) bool {
+	return app.GetAnnotations()[annotation.AppValuesTemplating] == "true"
+}
+
+// MergeAndRender merges the values of the layers and renders them when
+// templating is enabled for the app. Value ref layers are merged after
+// rendering so referenced values, e.g. passwords, are never parsed as
+// templates.
+func (v *Values) MergeAndRender(app v1alpha1.App, layers []Layer) (map[string]interface{}, error) {
+	var valueLayers, valueRefLayers []Layer
+	for _, l := range layers {
+		if l.Name == ValueRefLayer {
+			valueRefLayers = append(valueRefLayers, l)
+		} else {
+			valueLayers = append(valueLayers, l)
+		}
+	}
+
+	merged, err := Merge(valueLayers)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	rendered, err := v.Render(app, merged)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	if len(valueRefLayers) == 0 {
+		return rendered, nil
+	}
+
+	merged, err = Merge(append([]Layer{{Data: rendered}}, valueRefLayers...))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return merged, nil
+}
+
+// Render renders the string values of the merged values as templates when
+// templating is enabled for the app. Keys and other values are not changed.
+func (v *Values) Render(app v1alpha1.App, values map[string]interface{}) (map[string]interface{}, error) {
+	if !IsTemplatingEnabled(app) || values == nil {
+		return values, nil
+	}
+
+	data := TemplateData{
+		ClusterID:      key.ClusterID(app),
+		Labels:         app.GetLabels(),
+		OrganizationID: key.OrganizationID(app),
+		Provider:       v.provider,
+	}
+
+	rendered, err := render(values, data, nil)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return rendered.(map[string]interface{}), nil
+}
+
+func render(v interface{}, data TemplateData, path []string) (interface{}, error) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, child := range t {
+			r, err := render(child, data, append(append([]string{}, path...), k))
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+			m[k] = r
+		}
+		return m, nil
+	case []interface{}:
+		s := make([]interface{}, len(t))
+		for i, child := range t {
+			r, err := render(child, data, append(append([]string{}, path...), fmt.Sprintf("[%d]", i)))
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+			s[i] = r
+		}
+		return s, nil
+	case string:
+		if !strings.Contains(t, "{{") {
+			return t, nil
+		}
+		return renderString(t, data, strings.Join(path, "."))
+	default:
+		return v, nil
+	}
+}
+
+// renderString renders a single value. The errors do not include the errors
+// of text/template because they quote the template, which may contain
+// secrets.
+func renderString(s string, data TemplateData, path string) (string, error) {
+	tmpl, err := template.New(path).Option("missingkey=error").Funcs(templateFuncs).Parse(s)
+	if err != nil {
+		return "", microerror.Maskf(templateError, "failed to parse template of value %#q", path)
+	}
+
+	// Without loops and nested templates rendering is bounded by the length
+	// of the template.
+	if len(tmpl.Templates()) > 1 || !isSandboxed(tmpl.Tree.Root) {
+		return "", microerror.Maskf(templateError, "template of value %#q must not use range, template, define or block", path)
+	}
+
+	w := &limitedWriter{}
+	err = tmpl.Execute(w, data)
+	if err != nil {
+		return "", microerror.Maskf(templateError, "failed to render template of value %#q", path)
+	}
+
+	return w.String(), nil
+}
+
+// isSandboxed returns false if the template tree contains range or template
+// nodes.
+func isSandboxed(node parse.Node) bool {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return true
+		}
+		for _, child := range n.Nodes {
+			if !isSandboxed(child) {
+				return false
+			}
+		}
+		return true
+	case *parse.IfNode:
+		return isSandboxed(n.List) && isSandboxed(n.ElseList)
+	case *parse.WithNode:
+		return isSandboxed(n.List) && isSandboxed(n.ElseList)
+	case *parse.RangeNode, *parse.TemplateNode:
+		return false
+	default:
+		return true
+	}
+}
+
+func limited(s string) (string, error) {
+	if len(s) > MaxRenderedLength {
+		return "", microerror.Maskf(templateError, "rendered value is longer than %d bytes", MaxRenderedLength)
+	}
+
+	return s, nil
+}
+
+// limitedWriter fails once more than MaxRenderedLength bytes are written.
+type limitedWriter struct {
+	bytes.Buffer
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.Len()+len(p) > MaxRenderedLength {
+		return 0, microerror.Maskf(templateError, "rendered value is longer than %d bytes", MaxRenderedLength)
+	}
+
+	return w.Buffer.Write(p)
+}
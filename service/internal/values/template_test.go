@@ -0,0 +1,240 @@
+package values
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgofake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+)
+
+func Test_Render(t *testing.T) {
+	tests := []struct {
+		name           string
+		annotations    map[string]string
+		values         map[string]interface{}
+		expectedValues map[string]interface{}
+		errorMatcher   func(error) bool
+	}{
+		{
+			name: "case 0: templating is disabled",
+			values: map[string]interface{}{
+				"host": "{{ .ClusterID }}.example.com",
+			},
+			expectedValues: map[string]interface{}{
+				"host": "{{ .ClusterID }}.example.com",
+			},
+		},
+		{
+			name: "case 1: values are rendered",
+			annotations: map[string]string{
+				annotation.AppValuesTemplating: "true",
+			},
+			values: map[string]interface{}{
+				"ingress": map[string]interface{}{
+					"hosts": []interface{}{
+						"{{ .ClusterID }}.{{ .Provider }}.example.com",
+						"static.example.com",
+					},
+				},
+				"organization": `{{ upper .OrganizationID }}`,
+				"region":       `{{ index .Labels "region" | default "eu-west-1" }}`,
+				"replicas":     3,
+			},
+			expectedValues: map[string]interface{}{
+				"ingress": map[string]interface{}{
+					"hosts": []interface{}{
+						"5xchu.aws.example.com",
+						"static.example.com",
+					},
+				},
+				"organization": "ACME",
+				"region":       "eu-west-1",
+				"replicas":     3,
+			},
+		},
+		{
+			name: "case 2: unknown variable",
+			annotations: map[string]string{
+				annotation.AppValuesTemplating: "true",
+			},
+			values: map[string]interface{}{
+				"domain": "{{ .BaseDomain }}",
+			},
+			errorMatcher: IsTemplateError,
+		},
+		{
+			name: "case 3: unknown function",
+			annotations: map[string]string{
+				annotation.AppValuesTemplating: "true",
+			},
+			values: map[string]interface{}{
+				"env": `{{ env "HOME" }}`,
+			},
+			errorMatcher: IsTemplateError,
+		},
+		{
+			name: "case 4: rendered value is too long",
+			annotations: map[string]string{
+				annotation.AppValuesTemplating: "true",
+			},
+			values: map[string]interface{}{
+				"long": `{{ "` + strings.Repeat("a", 1024) + `" | printf "%[1]s%[1]s%[1]s%[1]s%[1]s%[1]s%[1]s%[1]s" | printf "%[1]s%[1]s%[1]s%[1]s%[1]s%[1]s%[1]s%[1]s" | printf "%[1]s%[1]s" }}`,
+			},
+			errorMatcher: IsTemplateError,
+		},
+		{
+			name: "case 5: range is rejected",
+			annotations: map[string]string{
+				annotation.AppValuesTemplating: "true",
+			},
+			values: map[string]interface{}{
+				"loop": `{{ range 2000000000 }}{{ end }}`,
+			},
+			errorMatcher: IsTemplateError,
+		},
+		{
+			name: "case 6: nested templates are rejected",
+			annotations: map[string]string{
+				annotation.AppValuesTemplating: "true",
+			},
+			values: map[string]interface{}{
+				"nested": `{{ define "a" }}{{ template "a" }}{{ end }}{{ if true }}{{ template "a" }}{{ end }}`,
+			},
+			errorMatcher: IsTemplateError,
+		},
+		{
+			name: "case 7: growing pipeline is rejected",
+			annotations: map[string]string{
+				annotation.AppValuesTemplating: "true",
+			},
+			values: map[string]interface{}{
+				"long": `{{ "aaaaaaaa" ` + strings.Repeat(`| printf "%[1]s%[1]s%[1]s%[1]s%[1]s%[1]s%[1]s%[1]s" `, 12) + `| len }}`,
+			},
+			errorMatcher: IsTemplateError,
+		},
+	}
+
+	c := Config{
+		K8sClient: clientgofake.NewSimpleClientset(),
+		Logger:    microloggertest.New(),
+
+		Provider: "aws",
+	}
+	v, err := New(c)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			app := v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tc.annotations,
+					Labels: map[string]string{
+						"giantswarm.io/cluster":      "5xchu",
+						"giantswarm.io/organization": "acme",
+					},
+					Name:      "prometheus",
+					Namespace: "5xchu",
+				},
+			}
+
+			result, err := v.Render(app, tc.values)
+			switch {
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case err != nil && !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.errorMatcher != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(result, tc.expectedValues) {
+				t.Fatalf("want matching values \n %s", cmp.Diff(result, tc.expectedValues))
+			}
+		})
+	}
+}
+
+func Test_renderString_error(t *testing.T) {
+	_, err := renderString(`{{ hunter2 }}`, TemplateData{}, "password")
+	if !IsTemplateError(err) {
+		t.Fatalf("error == %#v, want template error", err)
+	}
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Fatalf("error == %#q, want template not included", err.Error())
+	}
+}
+
+func Test_MergeAndRender(t *testing.T) {
+	c := Config{
+		K8sClient: clientgofake.NewSimpleClientset(),
+		Logger:    microloggertest.New(),
+
+		Provider: "aws",
+	}
+	v, err := New(c)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	app := v1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				annotation.AppValuesTemplating: "true",
+			},
+			Name:      "prometheus",
+			Namespace: "5xchu",
+		},
+	}
+	layers := []Layer{
+		{
+			Name: UserLayer,
+			Kind: secretKind,
+			Data: map[string]interface{}{
+				"database": map[string]interface{}{
+					"host":     "db.{{ .Provider }}.example.com",
+					"password": "{{ .Provider }}",
+				},
+			},
+		},
+		{
+			Name: ValueRefLayer,
+			Kind: secretKind,
+			Data: map[string]interface{}{
+				"database": map[string]interface{}{
+					"password": "{{ hunter2 }}",
+				},
+			},
+		},
+	}
+
+	result, err := v.MergeAndRender(app, layers)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	expectedValues := map[string]interface{}{
+		"database": map[string]interface{}{
+			"host":     "db.aws.example.com",
+			"password": "{{ hunter2 }}",
+		},
+	}
+	if !reflect.DeepEqual(result, expectedValues) {
+		t.Fatalf("want matching values \n %s", cmp.Diff(result, expectedValues))
+	}
+}
@@ -0,0 +1,145 @@
+package values
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+)
+
+// ValueRef injects the value of a single key of a config map or secret into
+// the values of an app.
+type ValueRef struct {
+	// Kind is configMap or secret. It defaults to secret.
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Key is the key of the config map or secret data.
+	Key string `json:"key"`
+	// Path is the dotted values path the value is set at, e.g.
+	// database.password.
+	Path string `json:"path"`
+}
+
+// ValueRefs returns the value refs of the app in the order of the
+// annotation. The kind defaults to secret and the namespace to the namespace
+// of the app.
+func ValueRefs(app v1alpha1.App) ([]ValueRef, error) {
+	value, ok := app.GetAnnotations()[annotation.AppValueRefs]
+	if !ok {
+		return nil, nil
+	}
+
+	var valueRefs []ValueRef
+
+	err := json.Unmarshal([]byte(value), &valueRefs)
+	if err != nil {
+		return nil, microerror.Maskf(parsingError, "failed to parse annotation %#q, logs: %s", annotation.AppValueRefs, err.Error())
+	}
+
+	for i, r := range valueRefs {
+		if r.Kind != "" && !strings.EqualFold(r.Kind, configMapKind) && !strings.EqualFold(r.Kind, secretKind) {
+			return nil, microerror.Maskf(parsingError, "value ref %d in annotation %#q has kind %#q, want `configMap` or `secret`", i, annotation.AppValueRefs, r.Kind)
+		}
+		if r.Name == "" || r.Key == "" {
+			return nil, microerror.Maskf(parsingError, "value ref %d in annotation %#q must have a name and key", i, annotation.AppValueRefs)
+		}
+		for _, p := range strings.Split(r.Path, ".") {
+			if p == "" {
+				return nil, microerror.Maskf(parsingError, "value ref %d in annotation %#q has invalid path %#q", i, annotation.AppValueRefs, r.Path)
+			}
+		}
+
+		if r.Kind == "" {
+			valueRefs[i].Kind = secretKind
+		}
+		if r.Namespace == "" {
+			valueRefs[i].Namespace = app.GetNamespace()
+		}
+	}
+
+	return valueRefs, nil
+}
+
+// valueRefLayers returns a layer per value ref of the app with the
+// referenced value set at its path.
+func (v *Values) valueRefLayers(ctx context.Context, app v1alpha1.App) ([]Layer, error) {
+	valueRefs, err := ValueRefs(app)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var layers []Layer
+
+	for _, r := range valueRefs {
+		value, err := v.getValueRef(ctx, r)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		data := map[string]interface{}{}
+		{
+			m := data
+			path := strings.Split(r.Path, ".")
+			for _, p := range path[:len(path)-1] {
+				child := map[string]interface{}{}
+				m[p] = child
+				m = child
+			}
+			m[path[len(path)-1]] = value
+		}
+
+		kind := secretKind
+		if strings.EqualFold(r.Kind, configMapKind) {
+			kind = configMapKind
+		}
+
+		layers = append(layers, Layer{
+			Name:   ValueRefLayer,
+			Kind:   kind,
+			Source: fmt.Sprintf("%s/%s", r.Namespace, r.Name),
+			Data:   data,
+		})
+	}
+
+	return layers, nil
+}
+
+func (v *Values) getValueRef(ctx context.Context, r ValueRef) (string, error) {
+	if strings.EqualFold(r.Kind, configMapKind) {
+		configMap, err := v.k8sClient.CoreV1().ConfigMaps(r.Namespace).Get(ctx, r.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return "", microerror.Maskf(notFoundError, "configmap %#q in namespace %#q not found", r.Name, r.Namespace)
+		} else if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		value, ok := configMap.Data[r.Key]
+		if !ok {
+			return "", microerror.Maskf(notFoundError, "key %#q not found in configmap %#q in namespace %#q", r.Key, r.Name, r.Namespace)
+		}
+
+		return value, nil
+	}
+
+	secret, err := v.k8sClient.CoreV1().Secrets(r.Namespace).Get(ctx, r.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return "", microerror.Maskf(notFoundError, "secret %#q in namespace %#q not found", r.Name, r.Namespace)
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	value, ok := secret.Data[r.Key]
+	if !ok {
+		return "", microerror.Maskf(notFoundError, "key %#q not found in secret %#q in namespace %#q", r.Key, r.Name, r.Namespace)
+	}
+
+	return string(value), nil
+}
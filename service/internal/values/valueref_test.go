@@ -0,0 +1,148 @@
+package values
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgofake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/app-operator/v5/pkg/annotation"
+)
+
+func Test_SecretLayers_valueRefs(t *testing.T) {
+	tests := []struct {
+		name           string
+		valueRefs      string
+		expectedValues map[string]interface{}
+		expectedLayers []string
+		errorMatcher   func(error) bool
+	}{
+		{
+			name:      "case 0: values are injected at their paths",
+			valueRefs: `[{"name": "db-credentials", "key": "password", "path": "database.password"}, {"kind": "configMap", "name": "db-config", "namespace": "giantswarm", "key": "host", "path": "database.host"}]`,
+			expectedValues: map[string]interface{}{
+				"database": map[string]interface{}{
+					"host":     "db.giantswarm.svc",
+					"password": "secret",
+					"user":     "prometheus",
+				},
+			},
+			expectedLayers: []string{
+				"user secret default/prometheus-user-secrets",
+				"ref secret default/db-credentials",
+				"ref configmap giantswarm/db-config",
+			},
+		},
+		{
+			name:         "case 1: missing key",
+			valueRefs:    `[{"name": "db-credentials", "key": "token", "path": "database.token"}]`,
+			errorMatcher: IsNotFound,
+		},
+		{
+			name:         "case 2: invalid path",
+			valueRefs:    `[{"name": "db-credentials", "key": "password", "path": "database..password"}]`,
+			errorMatcher: IsParsingError,
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			app := v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						annotation.AppValueRefs: tc.valueRefs,
+					},
+					Name:      "prometheus",
+					Namespace: "default",
+				},
+				Spec: v1alpha1.AppSpec{
+					UserConfig: v1alpha1.AppSpecUserConfig{
+						Secret: v1alpha1.AppSpecUserConfigSecret{
+							Name:      "prometheus-user-secrets",
+							Namespace: "default",
+						},
+					},
+				},
+			}
+
+			objs := []runtime.Object{
+				&corev1.Secret{
+					Data: map[string][]byte{
+						"values": []byte("database:\n  password: changeme\n  user: prometheus\n"),
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "prometheus-user-secrets",
+						Namespace: "default",
+					},
+				},
+				&corev1.Secret{
+					Data: map[string][]byte{
+						"password": []byte("secret"),
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "db-credentials",
+						Namespace: "default",
+					},
+				},
+				&corev1.ConfigMap{
+					Data: map[string]string{
+						"host": "db.giantswarm.svc",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "db-config",
+						Namespace: "giantswarm",
+					},
+				},
+			}
+
+			c := Config{
+				K8sClient: clientgofake.NewSimpleClientset(objs...),
+				Logger:    microloggertest.New(),
+			}
+			v, err := New(c)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			layers, err := v.SecretLayers(context.Background(), app, v1alpha1.Catalog{})
+			switch {
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case err != nil && !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.errorMatcher != nil {
+				return
+			}
+
+			var result []string
+			for _, l := range layers {
+				result = append(result, l.String())
+			}
+			if !reflect.DeepEqual(result, tc.expectedLayers) {
+				t.Fatalf("want matching layers \n %s", cmp.Diff(result, tc.expectedLayers))
+			}
+
+			merged, err := Merge(layers)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			if !reflect.DeepEqual(merged, tc.expectedValues) {
+				t.Fatalf("want matching values \n %s", cmp.Diff(merged, tc.expectedValues))
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+// Package values merges the catalog, default, cluster, user and extra values
+// of apps. The values are merged in layers so the layer that set each key can
+// be reported.
+package values
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/imdario/mergo"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	configMapKind = "configmap"
+	secretKind    = "secret"
+)
+
+const (
+	// CatalogLayer are the values of the catalog CR.
+	CatalogLayer = "catalog"
+	// OrganizationLayer are the default values in the organization namespace
+	// of the app CR.
+	OrganizationLayer = "organization"
+	// NamespaceLayer are the default values in the namespace of the app CR.
+	NamespaceLayer = "namespace"
+	// ClusterLayer are the values in the spec.config of the app CR.
+	ClusterLayer = "cluster"
+	// UserLayer are the values in the spec.userConfig of the app CR.
+	UserLayer = "user"
+	// ExtraLayer are the values of the extra configs of the app CR.
+	ExtraLayer = "extra"
+	// ValueRefLayer is a single value referenced by the app CR.
+	ValueRefLayer = "ref"
+)
+
+// Config represents the configuration used to create a new values service.
+type Config struct {
+	// Dependencies.
+	K8sClient kubernetes.Interface
+	Logger    micrologger.Logger
+
+	// Settings.
+	Provider string
+}
+
+// Values implements the values service.
+type Values struct {
+	// Dependencies.
+	k8sClient kubernetes.Interface
+	logger    micrologger.Logger
+
+	// Settings.
+	provider string
+}
+
+// Layer is a config map or secret with values of an app. Layers are merged
+// in order and later layers override earlier ones.
+type Layer struct {
+	// Name is the name of the layer, e.g. user.
+	Name string
+	// Kind is configmap or secret.
+	Kind string
+	// Source is the namespace and name of the config map or secret.
+	Source string
+	// Data are the parsed values.
+	Data map[string]interface{}
+}
+
+// String describes the layer without its values, e.g.
+// `user configmap default/prometheus-user-values`.
+func (l Layer) String() string {
+	return fmt.Sprintf("%s %s %s", l.Name, l.Kind, l.Source)
+}
+
+// New creates a new configured values service.
+func New(config Config) (*Values, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	r := &Values{
+		// Dependencies.
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+
+		// Settings.
+		provider: config.Provider,
+	}
+
+	return r, nil
+}
+
+// MergeAll merges both configmap and secret values to produce a single set of
+// values that can be passed to Helm. The values are rendered when templating
+// is enabled for the app.
+func (v *Values) MergeAll(ctx context.Context, app v1alpha1.App, catalog v1alpha1.Catalog) (map[string]interface{}, error) {
+	configMapLayers, err := v.ConfigMapLayers(ctx, app, catalog)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	secretLayers, err := v.SecretLayers(ctx, app, catalog)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	merged, err := v.MergeAndRender(app, append(configMapLayers, secretLayers...))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return merged, nil
+}
+
+// Merge merges the values of the layers in order. It returns nil if no layer
+// has values. The values of the layers are not modified.
+func Merge(layers []Layer) (map[string]interface{}, error) {
+	var merged map[string]interface{}
+
+	for _, l := range layers {
+		data, _ := deepCopy(l.Data).(map[string]interface{})
+
+		err := mergo.Merge(&merged, data, mergo.WithOverride)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return merged, nil
+}
+
+// deepCopy copies the maps and slices of parsed values so merging them does
+// not modify the layers.
+func deepCopy(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		if t == nil {
+			return t
+		}
+		c := make(map[string]interface{}, len(t))
+		for k, child := range t {
+			c[k] = deepCopy(child)
+		}
+		return c
+	case []interface{}:
+		if t == nil {
+			return t
+		}
+		c := make([]interface{}, len(t))
+		for i, child := range t {
+			c[i] = deepCopy(child)
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+func extractData(resourceType, name string, data map[string]string) (map[string]interface{}, error) {
+	var err error
+	var rawMapData map[string]interface{}
+
+	if data == nil {
+		return rawMapData, nil
+	}
+
+	if len(data) != 1 {
+		return nil, microerror.Maskf(parsingError, "expected %#q %s has only one key but got %d", name, resourceType, len(data))
+	}
+
+	var rawData []byte
+	for _, v := range data {
+		rawData = []byte(v)
+	}
+
+	err = yaml.Unmarshal(rawData, &rawMapData)
+	if err != nil {
+		return nil, microerror.Maskf(parsingError, "failed to parse %#q %s, logs: %s", name, resourceType, err.Error())
+	}
+
+	return rawMapData, nil
+}
+
+// toStringMap converts from a byte slice map to a string map.
+func toStringMap(input map[string][]byte) map[string]string {
+	if input == nil {
+		return nil
+	}
+
+	result := map[string]string{}
+
+	for k, v := range input {
+		result[k] = string(v)
+	}
+
+	return result
+}
@@ -0,0 +1,132 @@
+// Package valuesschema validates the merged values of apps against the
+// values.schema.json file of their chart before the chart CR is updated.
+package valuesschema
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/spf13/afero"
+
+	"github.com/giantswarm/app-operator/v5/service/internal/helmrepo"
+)
+
+const (
+	// SchemaFileName is the name of the JSON schema file in the chart.
+	SchemaFileName = "values.schema.json"
+
+	// expiration is long because the tarball of a chart version does not
+	// change.
+	expiration = 24 * time.Hour
+)
+
+type Config struct {
+	// Dependencies.
+	FileSystem afero.Fs
+	Logger     micrologger.Logger
+}
+
+// Cache keeps the schema of chart tarballs by tarball URL so tarballs are
+// only pulled once.
+type Cache struct {
+	// Dependencies.
+	cache  *gocache.Cache
+	fs     afero.Fs
+	logger micrologger.Logger
+}
+
+// New creates a new configured schema cache.
+func New(config Config) (*Cache, error) {
+	if config.FileSystem == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.FileSystem must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	c := &Cache{
+		cache:  gocache.New(expiration, expiration/2),
+		fs:     config.FileSystem,
+		logger: config.Logger,
+	}
+
+	return c, nil
+}
+
+// Get returns the values schema of the chart tarball. The tarball is pulled
+// with the client if the schema is not cached. It returns nil if the chart
+// has no schema.
+func (c *Cache) Get(ctx context.Context, client *http.Client, tarballURL string) ([]byte, error) {
+	if v, ok := c.cache.Get(tarballURL); ok {
+		return v.([]byte), nil
+	}
+
+	c.logger.Debugf(ctx, "pulling chart tarball %#q to get its values schema", tarballURL)
+
+	tarballPath, err := helmrepo.PullTarball(ctx, client, c.fs, tarballURL)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	defer func() {
+		_ = c.fs.Remove(tarballPath)
+	}()
+
+	f, err := c.fs.Open(tarballPath)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	defer f.Close()
+
+	schema, err := extractSchema(f)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	c.cache.SetDefault(tarballURL, schema)
+
+	return schema, nil
+}
+
+// extractSchema returns the values schema of the chart in the tarball. Only
+// the schema of the chart itself is used, not the ones of its subcharts.
+func extractSchema(r io.Reader) ([]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, nil
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		// Files of the chart are in a directory named after the chart.
+		dir, file := path.Split(path.Clean(header.Name))
+		if file != SchemaFileName || strings.Count(dir, "/") != 1 {
+			continue
+		}
+
+		schema, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return schema, nil
+	}
+}
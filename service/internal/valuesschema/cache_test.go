@@ -0,0 +1,63 @@
+package valuesschema
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/spf13/afero"
+)
+
+func Test_Cache_Get(t *testing.T) {
+	ctx := context.Background()
+
+	tarball := newTarball(t, map[string]string{
+		"prometheus/Chart.yaml":         "name: prometheus",
+		"prometheus/values.schema.json": testSchema,
+	}, []string{
+		"prometheus/Chart.yaml",
+		"prometheus/values.schema.json",
+	}).Bytes()
+
+	var pulls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pulls++
+		_, _ = w.Write(tarball)
+	}))
+	defer server.Close()
+
+	fs := afero.NewMemMapFs()
+	c, err := New(Config{
+		FileSystem: fs,
+		Logger:     microloggertest.New(),
+	})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	tarballURL := server.URL + "/prometheus-1.0.0.tgz"
+
+	for i := 0; i < 2; i++ {
+		schema, err := c.Get(ctx, server.Client(), tarballURL)
+		if err != nil {
+			t.Fatalf("error == %#v, want nil", err)
+		}
+		if string(schema) != testSchema {
+			t.Fatalf("schema == %#q, want %#q", schema, testSchema)
+		}
+	}
+
+	if pulls != 1 {
+		t.Fatalf("pulls == %d, want %d", pulls, 1)
+	}
+
+	files, err := afero.ReadDir(fs, afero.GetTempDir(fs, ""))
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if len(files) != 0 {
+		t.Fatalf("files == %d, want %d", len(files), 0)
+	}
+}
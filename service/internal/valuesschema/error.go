@@ -0,0 +1,21 @@
+package valuesschema
+
+import "github.com/giantswarm/microerror"
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var invalidSchemaError = &microerror.Error{
+	Kind: "invalidSchemaError",
+}
+
+// IsInvalidSchema asserts invalidSchemaError.
+func IsInvalidSchema(err error) bool {
+	return microerror.Cause(err) == invalidSchemaError
+}
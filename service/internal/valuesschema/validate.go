@@ -0,0 +1,40 @@
+package valuesschema
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/giantswarm/microerror"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Validate validates the values against the JSON schema. It returns the
+// violations with the JSON path of the invalid value, e.g.
+// `$.ingress.replicas: Invalid type. Expected: integer, given: string`.
+func Validate(schema []byte, values map[string]interface{}) ([]string, error) {
+	if values == nil {
+		// Helm validates empty values as an empty object.
+		values = map[string]interface{}{}
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewGoLoader(values))
+	if err != nil {
+		return nil, microerror.Maskf(invalidSchemaError, "%s", err.Error())
+	}
+
+	var violations []string
+	for _, e := range result.Errors() {
+		violations = append(violations, fmt.Sprintf("%s: %s", jsonPath(e.Field()), e.Description()))
+	}
+	sort.Strings(violations)
+
+	return violations, nil
+}
+
+func jsonPath(field string) string {
+	if field == "" || field == gojsonschema.STRING_CONTEXT_ROOT {
+		return "$"
+	}
+
+	return "$." + field
+}
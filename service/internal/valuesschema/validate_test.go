@@ -0,0 +1,124 @@
+package valuesschema
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testSchema = `{
+	"$schema": "http://json-schema.org/draft-07/schema#",
+	"type": "object",
+	"required": ["image"],
+	"properties": {
+		"image": {
+			"type": "object",
+			"properties": {
+				"tag": {"type": "string"}
+			}
+		},
+		"replicas": {"type": "integer", "minimum": 1}
+	}
+}`
+
+func Test_Validate(t *testing.T) {
+	tests := []struct {
+		name               string
+		values             map[string]interface{}
+		expectedViolations []string
+	}{
+		{
+			name: "case 0: valid values",
+			values: map[string]interface{}{
+				"image":    map[string]interface{}{"tag": "1.0.0"},
+				"replicas": 2,
+			},
+		},
+		{
+			name: "case 1: invalid values",
+			values: map[string]interface{}{
+				"image":    map[string]interface{}{"tag": 1},
+				"replicas": 0,
+			},
+			expectedViolations: []string{
+				"$.image.tag: Invalid type. Expected: string, given: integer",
+				"$.replicas: Must be greater than or equal to 1",
+			},
+		},
+		{
+			name: "case 2: missing required value",
+			expectedViolations: []string{
+				"$: image is required",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			violations, err := Validate([]byte(testSchema), tc.values)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			if !reflect.DeepEqual(violations, tc.expectedViolations) {
+				t.Fatalf("want matching violations \n %s", cmp.Diff(violations, tc.expectedViolations))
+			}
+		})
+	}
+
+	_, err := Validate([]byte("{"), nil)
+	if !IsInvalidSchema(err) {
+		t.Fatalf("error == %#v, want invalidSchemaError", err)
+	}
+}
+
+func Test_extractSchema(t *testing.T) {
+	files := map[string]string{
+		"prometheus/Chart.yaml":                           "name: prometheus",
+		"prometheus/charts/sub/values.schema.json":        `{"type": "string"}`,
+		"prometheus/values.schema.json":                   testSchema,
+		"prometheus/templates/values.schema.json":         `{"type": "array"}`,
+		"prometheus/charts/sub/templates/deployment.yaml": "",
+	}
+
+	buf := newTarball(t, files, []string{
+		"prometheus/Chart.yaml",
+		"prometheus/charts/sub/values.schema.json",
+		"prometheus/templates/values.schema.json",
+		"prometheus/charts/sub/templates/deployment.yaml",
+		"prometheus/values.schema.json",
+	})
+
+	schema, err := extractSchema(buf)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if string(schema) != testSchema {
+		t.Fatalf("schema == %#q, want %#q", schema, testSchema)
+	}
+}
+
+// newTarball returns a gzipped tarball with the files in the given order.
+func newTarball(t *testing.T, files map[string]string, names []string) *bytes.Buffer {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range names {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(files[name]))})
+		if err != nil {
+			t.Fatalf("error == %#v, want nil", err)
+		}
+		_, err = tw.Write([]byte(files[name]))
+		if err != nil {
+			t.Fatalf("error == %#v, want nil", err)
+		}
+	}
+	tw.Close()
+	gz.Close()
+
+	return &buf
+}
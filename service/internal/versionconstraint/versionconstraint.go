@@ -0,0 +1,60 @@
+// Package versionconstraint resolves semver constraints in the version of app
+// CRs, e.g. ~1.4 or >=2.0 <3, to chart versions.
+package versionconstraint
+
+import (
+	"github.com/Masterminds/semver/v3"
+	"github.com/giantswarm/microerror"
+)
+
+// IsConstraint returns true if the version of the app CR is a semver
+// constraint. Versions that can be parsed as a semver version are fixed
+// versions so existing app CRs keep their behaviour.
+func IsConstraint(version string) bool {
+	if version == "" {
+		return false
+	}
+
+	_, err := semver.NewVersion(version)
+	if err == nil {
+		return false
+	}
+
+	_, err = semver.NewConstraint(version)
+	return err == nil
+}
+
+// Resolve returns the highest of the versions matching the constraint.
+// Prerelease versions only match constraints with a prerelease.
+func Resolve(constraint string, versions []string) (string, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	var resolved *semver.Version
+	var resolvedVersion string
+
+	for _, v := range versions {
+		sv, err := semver.NewVersion(v)
+		if err != nil {
+			// Versions that are not semver can not match a constraint.
+			continue
+		}
+
+		if !c.Check(sv) {
+			continue
+		}
+
+		if resolved == nil || sv.GreaterThan(resolved) {
+			resolved = sv
+			resolvedVersion = v
+		}
+	}
+
+	if resolved == nil {
+		return "", microerror.Maskf(notFoundError, "no version matches constraint %#q", constraint)
+	}
+
+	return resolvedVersion, nil
+}
@@ -0,0 +1,98 @@
+package versionconstraint
+
+import (
+	"testing"
+)
+
+func Test_IsConstraint(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		expected bool
+	}{
+		{
+			name:     "case 0: fixed version",
+			version:  "1.4.2",
+			expected: false,
+		},
+		{
+			name:     "case 1: fixed prerelease version",
+			version:  "1.0.0-c4b2a6f",
+			expected: false,
+		},
+		{
+			name:     "case 2: tilde constraint",
+			version:  "~1.4",
+			expected: true,
+		},
+		{
+			name:     "case 3: range constraint",
+			version:  ">=2.0 <3",
+			expected: true,
+		},
+		{
+			name:     "case 4: empty version",
+			version:  "",
+			expected: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := IsConstraint(tc.version)
+			if result != tc.expected {
+				t.Fatalf("IsConstraint(%#q) == %t, want %t", tc.version, result, tc.expected)
+			}
+		})
+	}
+}
+
+func Test_Resolve(t *testing.T) {
+	versions := []string{"1.3.9", "1.4.0", "1.4.3", "1.5.0", "2.0.0", "2.1.0-beta.1", "2.0.1", "3.0.0", "latest"}
+
+	tests := []struct {
+		name            string
+		constraint      string
+		expectedVersion string
+		errorMatcher    func(error) bool
+	}{
+		{
+			name:            "case 0: tilde constraint",
+			constraint:      "~1.4",
+			expectedVersion: "1.4.3",
+		},
+		{
+			name:            "case 1: range constraint ignores prereleases",
+			constraint:      ">=2.0 <3",
+			expectedVersion: "2.0.1",
+		},
+		{
+			name:            "case 2: prerelease constraint",
+			constraint:      "~2.1.0-0",
+			expectedVersion: "2.1.0-beta.1",
+		},
+		{
+			name:         "case 3: no matching version",
+			constraint:   "^4.0",
+			errorMatcher: IsNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Resolve(tc.constraint, versions)
+			switch {
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case err != nil && !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if result != tc.expectedVersion {
+				t.Fatalf("version == %#q, want %#q", result, tc.expectedVersion)
+			}
+		})
+	}
+}
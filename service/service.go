@@ -19,8 +19,11 @@ import (
 	"github.com/giantswarm/app-operator/v5/service/controller/app"
 	"github.com/giantswarm/app-operator/v5/service/controller/catalog"
 	"github.com/giantswarm/app-operator/v5/service/internal/clientcache"
+	"github.com/giantswarm/app-operator/v5/service/internal/conditions"
 	"github.com/giantswarm/app-operator/v5/service/internal/crdcache"
+	"github.com/giantswarm/app-operator/v5/service/internal/indexcache"
 	"github.com/giantswarm/app-operator/v5/service/internal/recorder"
+	"github.com/giantswarm/app-operator/v5/service/valuesprovenance"
 	"github.com/giantswarm/app-operator/v5/service/watcher/appvalue"
 	"github.com/giantswarm/app-operator/v5/service/watcher/chartstatus"
 )
@@ -36,7 +39,8 @@ type Config struct {
 
 // Service is a type providing implementation of microkit service interface.
 type Service struct {
-	Version *version.Service
+	ValuesProvenance *valuesprovenance.Service
+	Version          *version.Service
 
 	// Internals
 	appController      *app.App
@@ -44,6 +48,8 @@ type Service struct {
 	appValueWatcher    *appvalue.AppValueWatcher
 	chartStatusWatcher *chartstatus.ChartStatusWatcher
 	bootOnce           sync.Once
+	k8sClient          k8sclient.Interface
+	logger             micrologger.Logger
 
 	// Settings
 	unique bool
@@ -67,14 +73,41 @@ func New(config Config) (*Service, error) {
 
 	var err error
 
-	var catalogController *catalog.Catalog
+	var event recorder.Interface
 	{
-		c := catalog.Config{
-			Logger:    config.Logger,
+		c := recorder.Config{
 			K8sClient: config.K8sClient,
 
-			MaxEntriesPerApp: config.Viper.GetInt(config.Flag.Service.AppCatalog.MaxEntriesPerApp),
-			UniqueApp:        config.Viper.GetBool(config.Flag.Service.App.Unique),
+			Component: fmt.Sprintf("%s-%s", project.Name(), project.Version()),
+		}
+
+		event = recorder.New(c)
+	}
+
+	var indexCache *indexcache.Resource
+	{
+		c := indexcache.Config{
+			Logger: config.Logger,
+		}
+
+		indexCache, err = indexcache.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var catalogController *catalog.Catalog
+	{
+		c := catalog.Config{
+			Event:      event,
+			IndexCache: indexCache,
+			Logger:     config.Logger,
+			K8sClient:  config.K8sClient,
+
+			MaxEntriesPerApp:    config.Viper.GetInt(config.Flag.Service.AppCatalog.MaxEntriesPerApp),
+			MetadataConcurrency: config.Viper.GetInt(config.Flag.Service.AppCatalog.Metadata.Concurrency),
+			MetadataRateLimit:   config.Viper.GetFloat64(config.Flag.Service.AppCatalog.Metadata.RateLimit),
+			UniqueApp:           config.Viper.GetBool(config.Flag.Service.App.Unique),
 		}
 
 		catalogController, err = catalog.NewCatalog(c)
@@ -114,14 +147,19 @@ func New(config Config) (*Service, error) {
 		}
 	}
 
+	valuesProvenance := valuesprovenance.New()
+
 	var appController *app.App
 	{
 		c := app.Config{
-			ClientCache: clientCache,
-			CRDCache:    crdCache,
-			Fs:          fs,
-			Logger:      config.Logger,
-			K8sClient:   config.K8sClient,
+			ClientCache:      clientCache,
+			CRDCache:         crdCache,
+			Event:            event,
+			Fs:               fs,
+			IndexCache:       indexCache,
+			Logger:           config.Logger,
+			K8sClient:        config.K8sClient,
+			ValuesProvenance: valuesProvenance,
 
 			ChartNamespace:    config.Viper.GetString(config.Flag.Service.Chart.Namespace),
 			HTTPClientTimeout: config.Viper.GetDuration(config.Flag.Service.Helm.HTTP.ClientTimeout),
@@ -138,17 +176,6 @@ func New(config Config) (*Service, error) {
 		}
 	}
 
-	var event recorder.Interface
-	{
-		c := recorder.Config{
-			K8sClient: config.K8sClient,
-
-			Component: fmt.Sprintf("%s-%s", project.Name(), project.Version()),
-		}
-
-		event = recorder.New(c)
-	}
-
 	var appValueWatcher *appvalue.AppValueWatcher
 	{
 		c := appvalue.AppValueWatcherConfig{
@@ -200,13 +227,16 @@ func New(config Config) (*Service, error) {
 	}
 
 	newService := &Service{
-		Version: versionService,
+		ValuesProvenance: valuesProvenance,
+		Version:          versionService,
 
 		appController:      appController,
 		catalogController:  catalogController,
 		appValueWatcher:    appValueWatcher,
 		chartStatusWatcher: chartStatusWatcher,
 		bootOnce:           sync.Once{},
+		k8sClient:          config.K8sClient,
+		logger:             config.Logger,
 
 		unique: config.Viper.GetBool(config.Flag.Service.App.Unique),
 	}
@@ -219,6 +249,8 @@ func (s *Service) Boot(ctx context.Context) {
 	s.bootOnce.Do(func() {
 		// Boot appCatalogController only if it's unique app.
 		if s.unique {
+			s.checkAppCRD(ctx)
+
 			go s.catalogController.Boot(ctx)
 		}
 
@@ -230,3 +262,15 @@ func (s *Service) Boot(ctx context.Context) {
 		go s.chartStatusWatcher.Boot(ctx)
 	})
 }
+
+// checkAppCRD warns when the App CRD does not have the conditions in its
+// status schema. The API server prunes them and updates of app CR statuses
+// fail until an apiextensions release with the conditions is installed.
+func (s *Service) checkAppCRD(ctx context.Context) {
+	ok, err := conditions.HasSchema(ctx, s.k8sClient.ExtClient())
+	if err != nil {
+		s.logger.Errorf(ctx, err, "failed to check status schema of CRD %#q", conditions.CRDName)
+	} else if !ok {
+		s.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("status schema of CRD %#q has no conditions, conditions of app CRs are pruned", conditions.CRDName))
+	}
+}
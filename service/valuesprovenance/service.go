@@ -0,0 +1,78 @@
+// Package valuesprovenance keeps the provenance of the merged values of each
+// app so it can be served by the values provenance endpoint.
+//
+// The provenance is kept in memory by the app-operator instance that
+// reconciles the app, i.e. the unique instance or the instance of the app's
+// version label. Other instances do not know it, and after a restart it is
+// unknown until the app is reconciled again. The config map provenance of
+// apps with the values provenance annotation is also persisted in the
+// annotation of their chart config map.
+package valuesprovenance
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Provenance maps the dotted path of each merged value to the layer that set
+// it. Values are never included.
+type Provenance struct {
+	ConfigMap map[string]string `json:"configMap,omitempty"`
+	Secret    map[string]string `json:"secret,omitempty"`
+}
+
+// Service stores the values provenance of apps by namespace and name.
+type Service struct {
+	mutex      sync.RWMutex
+	provenance map[string]Provenance
+}
+
+// New creates a new values provenance service.
+func New() *Service {
+	s := &Service{
+		provenance: map[string]Provenance{},
+	}
+
+	return s
+}
+
+// Delete removes the values provenance of the app.
+func (s *Service) Delete(namespace, name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.provenance, storeKey(namespace, name))
+}
+
+// Get returns the values provenance of the app and whether it is known.
+func (s *Service) Get(namespace, name string) (Provenance, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	p, ok := s.provenance[storeKey(namespace, name)]
+	return p, ok
+}
+
+// SetConfigMap stores the provenance of the config map values of the app.
+func (s *Service) SetConfigMap(namespace, name string, provenance map[string]string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p := s.provenance[storeKey(namespace, name)]
+	p.ConfigMap = provenance
+	s.provenance[storeKey(namespace, name)] = p
+}
+
+// SetSecret stores the provenance of the secret values of the app.
+func (s *Service) SetSecret(namespace, name string, provenance map[string]string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p := s.provenance[storeKey(namespace, name)]
+	p.Secret = provenance
+	s.provenance[storeKey(namespace, name)] = p
+}
+
+func storeKey(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
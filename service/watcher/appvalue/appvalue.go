@@ -2,9 +2,11 @@ package appvalue
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
+	k8smetadatalabel "github.com/giantswarm/k8smetadata/pkg/label"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	"k8s.io/apimachinery/pkg/labels"
@@ -26,9 +28,11 @@ type AppValueWatcher struct {
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
 
-	resourcesToApps sync.Map
-	selector        labels.Selector
-	unique          bool
+	// namespacesToApps has the apps using the default values of a namespace.
+	namespacesToApps sync.Map
+	resourcesToApps  sync.Map
+	selector         labels.Selector
+	unique           bool
 }
 
 func NewAppValueWatcher(config AppValueWatcherConfig) (*AppValueWatcher, error) {
@@ -47,20 +51,30 @@ func NewAppValueWatcher(config AppValueWatcherConfig) (*AppValueWatcher, error)
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
 
-		resourcesToApps: sync.Map{},
-		selector:        label.AppVersionSelector(config.UniqueApp),
-		unique:          config.UniqueApp,
+		namespacesToApps: sync.Map{},
+		resourcesToApps:  sync.Map{},
+		selector:         label.AppVersionSelector(config.UniqueApp),
+		unique:           config.UniqueApp,
 	}
 
 	return c, nil
 }
 
 func (c *AppValueWatcher) Boot(ctx context.Context) {
+	defaultValuesSelector := fmt.Sprintf("%s=true", label.DefaultValues)
+
 	// Watch for configmap changes.
-	go c.watchConfigMap(ctx)
+	go c.watchConfigMap(ctx, k8smetadatalabel.AppOperatorWatching, c.referencingApps)
+
+	// Watch for default values configmaps. They are not labelled for
+	// watching so ones created or labelled later are found too.
+	go c.watchConfigMap(ctx, defaultValuesSelector, c.defaultValuesApps)
 
 	// Watch for secret changes.
-	go c.watchSecret(ctx)
+	go c.watchSecret(ctx, k8smetadatalabel.AppOperatorWatching, c.referencingApps)
+
+	// Watch for default values secrets.
+	go c.watchSecret(ctx, defaultValuesSelector, c.defaultValuesApps)
 
 	// Build a cache of configmaps and link each app to its configmaps.
 	go c.buildCache(ctx)
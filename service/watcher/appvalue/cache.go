@@ -15,6 +15,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/giantswarm/app-operator/v5/service/internal/values"
 )
 
 func (c *AppValueWatcher) buildCache(ctx context.Context) {
@@ -44,6 +46,7 @@ func (c *AppValueWatcher) buildCache(ctx context.Context) {
 
 		c.logger.LogCtx(ctx, "debug", "watch channel has been closed, reopening...")
 		c.resourcesToApps = sync.Map{}
+		c.namespacesToApps = sync.Map{}
 	}
 
 }
@@ -109,8 +112,56 @@ func (c *AppValueWatcher) addCache(ctx context.Context, cr v1alpha1.App, eventTy
 		})
 	}
 
+	extraConfigs, err := values.ExtraConfigs(cr)
+	if err != nil {
+		c.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("failed to get extra configs of app CR %#q", cr.Name), "stack", fmt.Sprintf("%#v", err))
+	}
+
+	for _, e := range extraConfigs {
+		resourceType := configMapType
+		if strings.EqualFold(e.Kind, string(secretType)) {
+			resourceType = secretType
+		}
+
+		resources = append(resources, resourceIndex{
+			ResourceType: resourceType,
+			Name:         e.Name,
+			Namespace:    e.Namespace,
+		})
+	}
+
+	valueRefs, err := values.ValueRefs(cr)
+	if err != nil {
+		c.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("failed to get value refs of app CR %#q", cr.Name), "stack", fmt.Sprintf("%#v", err))
+	}
+
+	for _, r := range valueRefs {
+		resourceType := secretType
+		if strings.EqualFold(r.Kind, string(configMapType)) {
+			resourceType = configMapType
+		}
+
+		resources = append(resources, resourceIndex{
+			ResourceType: resourceType,
+			Name:         r.Name,
+			Namespace:    r.Namespace,
+		})
+	}
+
+	// Default values of the organization and namespace are implicit
+	// dependencies of the app. They are indexed by namespace so config maps
+	// and secrets created or labelled later are found too.
+	namespaces := []string{cr.GetNamespace()}
+	if ns := values.OrganizationNamespace(cr); ns != "" && ns != cr.GetNamespace() {
+		namespaces = append(namespaces, ns)
+	}
+
 	switch eventType {
 	case watch.Added, watch.Modified:
+		for _, namespace := range namespaces {
+			c.addNamespaceApp(namespace, app)
+		}
+
 		for _, resource := range resources {
 			// First, put the watchUpdate label
 			err := c.addLabel(ctx, resource)
@@ -137,6 +188,10 @@ func (c *AppValueWatcher) addCache(ctx context.Context, cr v1alpha1.App, eventTy
 		}
 
 	case watch.Deleted:
+		for _, namespace := range namespaces {
+			c.deleteNamespaceApp(namespace, app)
+		}
+
 		for _, resource := range resources {
 			v, ok := c.resourcesToApps.Load(resource)
 			if ok {
@@ -167,6 +222,73 @@ func (c *AppValueWatcher) addCache(ctx context.Context, cr v1alpha1.App, eventTy
 	return nil
 }
 
+// addNamespaceApp adds the app to the apps using the default values of the
+// namespace. The stored map is replaced instead of modified so it can be
+// read by the watches concurrently.
+func (c *AppValueWatcher) addNamespaceApp(namespace string, app appIndex) {
+	apps := map[appIndex]bool{
+		app: true,
+	}
+
+	if stored, ok := c.namespaceApps(namespace); ok {
+		for a := range stored {
+			apps[a] = true
+		}
+	}
+
+	c.namespacesToApps.Store(namespace, apps)
+}
+
+// deleteNamespaceApp removes the app from the apps using the default values
+// of the namespace.
+func (c *AppValueWatcher) deleteNamespaceApp(namespace string, app appIndex) {
+	stored, ok := c.namespaceApps(namespace)
+	if !ok {
+		return
+	}
+
+	apps := map[appIndex]bool{}
+	for a := range stored {
+		if a != app {
+			apps[a] = true
+		}
+	}
+
+	if len(apps) == 0 {
+		c.namespacesToApps.Delete(namespace)
+	} else {
+		c.namespacesToApps.Store(namespace, apps)
+	}
+}
+
+// namespaceApps returns the apps using the default values of the namespace.
+func (c *AppValueWatcher) namespaceApps(namespace string) (map[appIndex]bool, bool) {
+	v, ok := c.namespacesToApps.Load(namespace)
+	if !ok {
+		return nil, false
+	}
+
+	apps, ok := v.(map[appIndex]bool)
+	return apps, ok
+}
+
+// defaultValuesApps returns the apps using the default values of the
+// namespace of the config map or secret.
+func (c *AppValueWatcher) defaultValuesApps(resource resourceIndex) (map[appIndex]bool, bool) {
+	return c.namespaceApps(resource.Namespace)
+}
+
+// referencingApps returns the apps referencing the config map or secret.
+func (c *AppValueWatcher) referencingApps(resource resourceIndex) (map[appIndex]bool, bool) {
+	v, ok := c.resourcesToApps.Load(resource)
+	if !ok {
+		return nil, false
+	}
+
+	apps, ok := v.(map[appIndex]bool)
+	return apps, ok
+}
+
 func (c *AppValueWatcher) addLabel(ctx context.Context, resource resourceIndex) error {
 	var currentLabels map[string]string
 	{
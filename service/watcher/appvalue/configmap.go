@@ -8,7 +8,6 @@ import (
 
 	"github.com/giantswarm/apiextensions/v3/pkg/apis/application/v1alpha1"
 	"github.com/giantswarm/k8smetadata/pkg/annotation"
-	"github.com/giantswarm/k8smetadata/pkg/label"
 	"github.com/giantswarm/microerror"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,16 +15,16 @@ import (
 	"k8s.io/apimachinery/pkg/watch"
 )
 
-func (c *AppValueWatcher) watchConfigMap(ctx context.Context) {
+func (c *AppValueWatcher) watchConfigMap(ctx context.Context, selector string, apps appsFunc) {
 	for {
 		lo := metav1.ListOptions{
-			LabelSelector: label.AppOperatorWatching,
+			LabelSelector: selector,
 		}
 
 		// Find the highest resourceVersion for each configmap.
 		cms, err := c.k8sClient.K8sClient().CoreV1().ConfigMaps("").List(ctx, lo)
 		if err != nil {
-			c.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("failed to get configmaps with label %#q", label.AppOperatorWatching), "stack", fmt.Sprintf("%#v", err))
+			c.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("failed to get configmaps with label %#q", selector), "stack", fmt.Sprintf("%#v", err))
 			continue
 		}
 
@@ -46,7 +45,7 @@ func (c *AppValueWatcher) watchConfigMap(ctx context.Context) {
 
 		res, err := c.k8sClient.K8sClient().CoreV1().ConfigMaps("").Watch(ctx, lo)
 		if err != nil {
-			c.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("failed to get configmaps with label %#q", label.AppOperatorWatching), "stack", fmt.Sprintf("%#v", err))
+			c.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("failed to get configmaps with label %#q", selector), "stack", fmt.Sprintf("%#v", err))
 			continue
 		}
 
@@ -85,19 +84,10 @@ func (c *AppValueWatcher) watchConfigMap(ctx context.Context) {
 				Namespace:    cm.GetNamespace(),
 			}
 
-			var storedIndex map[appIndex]bool
-			{
-				v, ok := c.resourcesToApps.Load(configMap)
-				if !ok {
-					c.logger.Debugf(ctx, "cache missed configMap %#q in namespace %#q", configMap.Name, configMap.Namespace)
-					continue
-				}
-
-				storedIndex, ok = v.(map[appIndex]bool)
-				if !ok {
-					c.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("expected '%T', got '%T'", map[appIndex]bool{}, v), "stack", fmt.Sprintf("%#v", err))
-					continue
-				}
+			storedIndex, ok := apps(configMap)
+			if !ok {
+				c.logger.Debugf(ctx, "cache missed configMap %#q in namespace %#q", configMap.Name, configMap.Namespace)
+				continue
 			}
 
 			c.logger.Debugf(ctx, "listed apps depends on %#q configmap in namespace %#q", cm.Name, cm.Namespace)
@@ -4,23 +4,22 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/giantswarm/k8smetadata/pkg/label"
 	"github.com/giantswarm/microerror"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/watch"
 )
 
-func (c *AppValueWatcher) watchSecret(ctx context.Context) {
+func (c *AppValueWatcher) watchSecret(ctx context.Context, selector string, apps appsFunc) {
 	for {
 		lo := metav1.ListOptions{
-			LabelSelector: label.AppOperatorWatching,
+			LabelSelector: selector,
 		}
 
 		// Find the highest resourceVersion for each secret.
 		secrets, err := c.k8sClient.K8sClient().CoreV1().Secrets("").List(ctx, lo)
 		if err != nil {
-			c.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("failed to get secrets with label %#q", label.AppOperatorWatching), "stack", fmt.Sprintf("%#v", err))
+			c.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("failed to get secrets with label %#q", selector), "stack", fmt.Sprintf("%#v", err))
 			continue
 		}
 
@@ -41,7 +40,7 @@ func (c *AppValueWatcher) watchSecret(ctx context.Context) {
 
 		res, err := c.k8sClient.K8sClient().CoreV1().Secrets("").Watch(ctx, lo)
 		if err != nil {
-			c.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("failed to get secrets with label %#q", label.AppOperatorWatching), "stack", fmt.Sprintf("%#v", err))
+			c.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("failed to get secrets with label %#q", selector), "stack", fmt.Sprintf("%#v", err))
 			continue
 		}
 
@@ -80,19 +79,10 @@ func (c *AppValueWatcher) watchSecret(ctx context.Context) {
 				Namespace:    secret.GetNamespace(),
 			}
 
-			var storedIndex map[appIndex]bool
-			{
-				v, ok := c.resourcesToApps.Load(secretIndex)
-				if !ok {
-					c.logger.Debugf(ctx, "cache missed secret %#q in namespace %#q", secret.Name, secret.Namespace)
-					continue
-				}
-
-				storedIndex, ok = v.(map[appIndex]bool)
-				if !ok {
-					c.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("expected '%T', got '%T'", map[appIndex]bool{}, v), "stack", fmt.Sprintf("%#v", err))
-					continue
-				}
+			storedIndex, ok := apps(secretIndex)
+			if !ok {
+				c.logger.Debugf(ctx, "cache missed secret %#q in namespace %#q", secret.Name, secret.Namespace)
+				continue
 			}
 
 			c.logger.Debugf(ctx, "listing apps depends on %#q secret in namespace %#q", secret.Name, secret.Namespace)
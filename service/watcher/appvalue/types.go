@@ -7,6 +7,9 @@ const (
 	secretType    resourceType = "secret"
 )
 
+// appsFunc returns the apps to update when the config map or secret changes.
+type appsFunc func(resource resourceIndex) (map[appIndex]bool, bool)
+
 type appIndex struct {
 	Name      string `json:"name"`
 	Namespace string `json:"namespace"`
@@ -14,6 +14,10 @@ import (
 	"github.com/google/go-cmp/cmp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/giantswarm/app-operator/v5/service/internal/conditions"
+	"github.com/giantswarm/app-operator/v5/service/internal/dryrun"
+	"github.com/giantswarm/app-operator/v5/service/internal/rollback"
 )
 
 const chartOperatorAppName = "chart-operator"
@@ -148,6 +152,16 @@ func (c *ChartStatusWatcher) watchChartStatus(ctx context.Context) {
 			}
 
 			desiredStatus := toAppStatus(chart)
+			deployed := conditions.Deployed(*app, desiredStatus.Release)
+			if rollback.IsEnabled(*app) {
+				state, err := rollback.GetState(*app)
+				if err == nil && state.Message() != "" {
+					desiredStatus.Release.Reason = conditions.JoinReason(state.Message(), desiredStatus.Release.Reason)
+				}
+			}
+			if reason := dryrun.Reason(*app); reason != "" {
+				desiredStatus.Release.Reason = conditions.JoinReason(reason, desiredStatus.Release.Reason)
+			}
 			currentStatus := key.AppStatus(*app)
 
 			if !equals(currentStatus, desiredStatus) {
@@ -155,9 +169,7 @@ func (c *ChartStatusWatcher) watchChartStatus(ctx context.Context) {
 					c.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("status for app %#q in %#q namespace has to be updated", app.Name, app.Namespace), "diff", fmt.Sprintf("(-current +desired):\n%s", diff))
 				}
 
-				app.Status = desiredStatus
-
-				_, err = c.k8sClient.G8sClient().ApplicationV1alpha1().Apps(app.Namespace).UpdateStatus(ctx, app, metav1.UpdateOptions{})
+				_, err = conditions.Update(ctx, c.k8sClient.DynClient(), *app, &desiredStatus, []metav1.Condition{deployed})
 				if err != nil {
 					c.logger.Errorf(ctx, err, "failed to update status for app %#q in namespace %#q", app.Name, app.Namespace)
 					continue
@@ -165,6 +177,13 @@ func (c *ChartStatusWatcher) watchChartStatus(ctx context.Context) {
 
 				c.logger.Debugf(ctx, "status set for app %#q in namespace %#q", app.Name, app.Namespace)
 			}
+
+			// The history is updated after the status so the status update
+			// does not conflict with it.
+			_, err = conditions.AddRelease(ctx, c.k8sClient.DynClient(), *app, chart)
+			if err != nil {
+				c.logger.Errorf(ctx, err, "failed to update release history for app %#q in namespace %#q", app.Name, app.Namespace)
+			}
 		}
 
 		c.logger.Debugf(ctx, "watch channel had been closed, reopening...")